@@ -0,0 +1,116 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package business
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/tradalia/core/auth"
+	"github.com/tradalia/core/req"
+	"github.com/tradalia/portfolio-trader/pkg/business/allocation"
+	"github.com/tradalia/portfolio-trader/pkg/business/performance"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+func StartAllocationOptimization(tx *gorm.DB, c *auth.Context, portfolioId uint, oreq *allocation.OptimizationRequest) error {
+	err := oreq.Validate()
+	if err != nil {
+		//--- The message can contain the requested objective, so it cannot be used as a format
+		return req.AppError{ Code: http.StatusBadRequest, Message: err.Error() }
+	}
+
+	p, err := getPortfolioAndCheckAccess(tx, c, portfolioId)
+	if err != nil {
+		return err
+	}
+
+	systems, err := getPortfolioTradingSystems(tx, p)
+	if err != nil {
+		return err
+	}
+
+	if len(systems) == 0 {
+		return req.NewBadRequestError("Portfolio has no trading systems: %v", p.Name)
+	}
+
+	var fromTime *time.Time
+	if oreq.DaysBack > 0 {
+		from := time.Now().UTC().AddDate(0, 0, -oreq.DaysBack)
+		fromTime = &from
+	}
+
+	var returns []db.DailyReturn
+
+	for _, ts := range systems {
+		list, err := db.FindDailyReturnsByTsIdFromTime(tx, ts.Id, fromTime, nil)
+		if err != nil {
+			return err
+		}
+		returns = append(returns, *list...)
+	}
+
+	days, series := performance.AlignDailyNetReturns(systems, &returns)
+
+	if len(days) < allocation.MinDays {
+		return req.NewBadRequestError("Not enough daily returns to optimize the portfolio: %d days found, at least %d required", len(days), allocation.MinDays)
+	}
+
+	c.Log.Info("StartAllocationOptimization: Starting optimization", "portfolioId", p.Id, "portfolioName", p.Name, "objective", oreq.Objective)
+	allocation.StartOptimization(p.Id, systems, series, oreq)
+
+	return nil
+}
+
+//=============================================================================
+
+func StopAllocationOptimization(tx *gorm.DB, c *auth.Context, portfolioId uint) error {
+	_, err := getPortfolioAndCheckAccess(tx, c, portfolioId)
+	if err != nil {
+		return err
+	}
+
+	c.Log.Info("StopAllocationOptimization: Stopping optimization", "portfolioId", portfolioId)
+
+	return allocation.StopOptimization(portfolioId)
+}
+
+//=============================================================================
+
+func GetAllocationOptimizationInfo(tx *gorm.DB, c *auth.Context, portfolioId uint) (*allocation.OptimizationResponse, error) {
+	_, err := getPortfolioAndCheckAccess(tx, c, portfolioId)
+	if err != nil {
+		return nil, err
+	}
+
+	info := allocation.GetOptimizationInfo(portfolioId)
+	return allocation.NewOptimizationResponse(info), nil
+}
+
+//=============================================================================
@@ -0,0 +1,141 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package allocation
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/tradalia/portfolio-trader/pkg/db"
+)
+
+//=============================================================================
+
+var jobs = struct {
+	sync.RWMutex
+	m map[uint]*OptimizationProcess
+}{m: make(map[uint]*OptimizationProcess)}
+
+//=============================================================================
+//===
+//=== Init
+//===
+//=============================================================================
+
+func init() {
+	go periodicCleanup()
+}
+
+//=============================================================================
+//===
+//=== API methods
+//===
+//=============================================================================
+
+func StartOptimization(portfolioId uint, systems []*db.TradingSystem, series [][]float64, or *OptimizationRequest) {
+	jobs.Lock()
+	defer jobs.Unlock()
+
+	op, ok := jobs.m[portfolioId]
+	if ok {
+		slog.Error("Stopping a previous allocation optimization", "portfolioId", portfolioId)
+		op.Stop()
+		delete(jobs.m, portfolioId)
+	}
+
+	op = &OptimizationProcess{
+		portfolioId: portfolioId,
+		systems    : systems,
+		series     : series,
+		optReq     : or,
+	}
+
+	op.Start()
+	jobs.m[portfolioId] = op
+}
+
+//=============================================================================
+
+func StopOptimization(portfolioId uint) error {
+	jobs.Lock()
+	defer jobs.Unlock()
+
+	op, ok := jobs.m[portfolioId]
+	if ok {
+		op.Stop()
+	}
+
+	return nil
+}
+
+//=============================================================================
+
+func GetOptimizationInfo(portfolioId uint) *OptimizationInfo {
+	jobs.Lock()
+	defer jobs.Unlock()
+
+	op, ok := jobs.m[portfolioId]
+	if !ok {
+		return &OptimizationInfo{
+			Status: OptimStatusIdle,
+		}
+	}
+
+	return op.GetInfo()
+}
+
+//=============================================================================
+//===
+//=== Cleanup process
+//===
+//=============================================================================
+
+func periodicCleanup() {
+	for {
+		time.Sleep(time.Minute * 5)
+		purge()
+	}
+}
+
+//=============================================================================
+
+func purge() {
+	jobs.Lock()
+	defer jobs.Unlock()
+
+	for portfolioId, op := range jobs.m {
+		if op.info.Status == OptimStatusComplete {
+			delta := time.Now().Sub(op.info.EndTime)
+			if delta.Minutes() >= 30 {
+				slog.Info("purge: Purging allocation optimization entry for portfolio", "portfolioId", portfolioId)
+				delete(jobs.m, portfolioId)
+			}
+		}
+	}
+}
+
+//=============================================================================
@@ -0,0 +1,165 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package allocation
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tradalia/portfolio-trader/pkg/core"
+)
+
+//=============================================================================
+//===
+//=== Run
+//===
+//=============================================================================
+
+type Allocation struct {
+	TradingSystemId   uint    `json:"tradingSystemId"`
+	TradingSystemName string  `json:"tradingSystemName"`
+	Contracts         int     `json:"contracts"`
+	Margin            float64 `json:"margin"`
+	Weight            float64 `json:"weight"`
+	RiskContribution  float64 `json:"riskContribution"`
+}
+
+//=============================================================================
+
+type Run struct {
+	Allocations  []*Allocation `json:"allocations"`
+
+	FitnessValue float64 `json:"fitnessValue"`
+	NetProfit    float64 `json:"netProfit"`
+	MaxDrawdown  float64 `json:"maxDrawdown"`
+	SharpeRatio  float64 `json:"sharpeRatio"`
+	StdDev       float64 `json:"stdDev"`
+	Margin       float64 `json:"margin"`
+	random       int
+}
+
+//=============================================================================
+//===
+//=== OptimizationInfo
+//===
+//=============================================================================
+
+const OptimStatusIdle     = "idle"
+const OptimStatusRunning  = "running"
+const OptimStatusComplete = "complete"
+
+type OptimizationInfo struct {
+	sync.RWMutex
+	CurrStep  uint
+	MaxSteps  uint
+	StartTime time.Time
+	EndTime   time.Time
+	Status    string
+	results   *core.SortedResults
+	keys      map[string]bool
+
+	Objective   string
+	MaxMargin   float64
+	MaxDrawdown float64
+	Days        int
+	BaseValue   float64
+	BestValue   float64
+}
+
+//=============================================================================
+
+func NewOptimizationInfo(maxResultSize int, or *OptimizationRequest, steps uint, days int, baseValue float64) *OptimizationInfo {
+	oi := &OptimizationInfo{}
+	oi.CurrStep    = 0
+	oi.StartTime   = time.Now()
+	oi.Status      = OptimStatusRunning
+	oi.results     = core.NewSortedResults(maxResultSize, runComparator)
+	oi.keys        = map[string]bool{}
+	oi.Objective   = or.Objective
+	oi.MaxMargin   = or.MaxMargin
+	oi.MaxDrawdown = or.MaxDrawdown
+	oi.Days        = days
+	oi.BaseValue   = baseValue
+	oi.BestValue   = baseValue
+	oi.MaxSteps    = steps
+
+	return oi
+}
+
+//=============================================================================
+//===
+//=== Public methods
+//===
+//=============================================================================
+
+func (oi *OptimizationInfo) GetRuns() []any {
+	oi.Lock()
+	defer oi.Unlock()
+
+	if oi.results != nil {
+		return oi.results.ToList()
+	}
+
+	return nil
+}
+
+//=============================================================================
+//===
+//=== Private methods
+//===
+//=============================================================================
+
+func (oi *OptimizationInfo) addResult(key string, r *Run) {
+	oi.Lock()
+	defer oi.Unlock()
+
+	oi.CurrStep++
+
+	//--- Different restarts often converge to the same allocation
+
+	if r == nil || oi.keys[key] {
+		return
+	}
+
+	oi.keys[key] = true
+	oi.results.Add(r)
+
+	if oi.BestValue < r.FitnessValue {
+		oi.BestValue = r.FitnessValue
+	}
+}
+
+//=============================================================================
+
+func (oi *OptimizationInfo) setComplete() {
+	oi.Lock()
+	defer oi.Unlock()
+
+	oi.EndTime = time.Now()
+	oi.Status  = OptimStatusComplete
+}
+
+//=============================================================================
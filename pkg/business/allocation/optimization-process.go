@@ -0,0 +1,391 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package allocation
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/tradalia/portfolio-trader/pkg/core"
+	"github.com/tradalia/portfolio-trader/pkg/core/stats"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+)
+
+//=============================================================================
+
+const MaxResultSize = 100
+
+//=============================================================================
+//===
+//=== OptimizationProcess
+//===
+//=== Integer contracts are searched with a multi-start hill climbing: each restart
+//=== begins from a random allocation and moves one contract at a time (add, remove
+//=== or shift between two systems) while the objective improves
+//=============================================================================
+
+type OptimizationProcess struct {
+	portfolioId  uint
+	systems      []*db.TradingSystem
+	series       [][]float64
+	optReq       *OptimizationRequest
+	info         *OptimizationInfo
+	minContracts []int
+	maxContracts []int
+	covariance   [][]float64
+	random       *rand.Rand
+	stopping     bool
+}
+
+//=============================================================================
+
+func (op *OptimizationProcess) Start() {
+	op.random = rand.New(rand.NewSource(time.Now().UnixNano()))
+	op.initLimits()
+	op.initCovariance()
+
+	steps := op.optReq.Restarts
+	if steps == 0 {
+		steps = DefaultRestarts
+	}
+
+	days := 0
+	if len(op.series) > 0 {
+		days = len(op.series[0])
+	}
+
+	op.info = NewOptimizationInfo(MaxResultSize, op.optReq, uint(steps), days, op.calcBaseValue())
+
+	go op.generate(steps)
+}
+
+//=============================================================================
+
+func (op *OptimizationProcess) Stop() {
+	slog.Info("Stop: Stopping allocation optimization", "portfolioId", op.portfolioId)
+	op.stopping = true
+}
+
+//=============================================================================
+
+func (op *OptimizationProcess) GetInfo() *OptimizationInfo {
+	return op.info
+}
+
+//=============================================================================
+//===
+//=== Private methods
+//===
+//=============================================================================
+
+func (op *OptimizationProcess) initLimits() {
+	limits := map[uint]ContractLimit{}
+	for _, l := range op.optReq.Limits {
+		limits[l.TradingSystemId] = l
+	}
+
+	op.minContracts = make([]int, len(op.systems))
+	op.maxContracts = make([]int, len(op.systems))
+
+	for i, ts := range op.systems {
+		l, ok := limits[ts.Id]
+		if ok {
+			op.minContracts[i] = l.MinContracts
+			op.maxContracts[i] = l.MaxContracts
+		} else {
+			op.maxContracts[i] = DefaultMaxContracts
+		}
+	}
+}
+
+//=============================================================================
+//--- The matrix is sized on the systems: if the series are missing it is left to zero
+
+func (op *OptimizationProcess) initCovariance() {
+	n     := len(op.systems)
+	means := make([]float64, len(op.series))
+
+	for i := range op.series {
+		means[i] = stats.Mean(op.series[i])
+	}
+
+	op.covariance = make([][]float64, n)
+	for i := range op.covariance {
+		op.covariance[i] = make([]float64, n)
+	}
+
+	for i := 0; i < n && i < len(op.series); i++ {
+		for j := i; j < n && j < len(op.series); j++ {
+			cov := stats.Covariance(op.series[i], means[i], op.series[j], means[j])
+			op.covariance[i][j], op.covariance[j][i] = cov, cov
+		}
+	}
+}
+
+//=============================================================================
+//--- The baseline is what we trade today without any sizing: 1 contract each
+
+func (op *OptimizationProcess) calcBaseValue() float64 {
+	contracts := make([]int, len(op.systems))
+	for i := range contracts {
+		contracts[i] = 1
+	}
+
+	m := op.calcMetrics(contracts, op.calcProfits(contracts))
+
+	return core.Trunc2d(op.calcFitness(contracts, m))
+}
+
+//=============================================================================
+//--- GoRoutine
+
+func (op *OptimizationProcess) generate(steps int) {
+	slog.Info("generate: Started", "portfolioId", op.portfolioId, "objective", op.optReq.Objective, "restarts", steps)
+
+	for step := 0; step < steps && !op.stopping; step++ {
+		contracts := op.createStart(step)
+		contracts, fitness, ok := op.climb(contracts)
+
+		if ok {
+			op.info.addResult(fmt.Sprint(contracts), op.createRun(contracts, fitness))
+		} else {
+			op.info.addResult("", nil)
+		}
+	}
+
+	op.info.setComplete()
+	slog.Info("generate: Complete.", "portfolioId", op.portfolioId)
+}
+
+//=============================================================================
+//--- The first restart starts from 1 contract each, the others from random values
+
+func (op *OptimizationProcess) createStart(step int) []int {
+	contracts := make([]int, len(op.systems))
+
+	for i := range contracts {
+		if step == 0 {
+			contracts[i] = max(op.minContracts[i], min(1, op.maxContracts[i]))
+		} else {
+			contracts[i] = op.minContracts[i] + op.random.Intn(op.maxContracts[i] - op.minContracts[i] +1)
+		}
+	}
+
+	//--- An empty portfolio is not feasible: we pick a random system to start from
+
+	var tradable []int
+	total := 0
+
+	for i, c := range contracts {
+		total += c
+		if op.maxContracts[i] > 0 {
+			tradable = append(tradable, i)
+		}
+	}
+
+	if total == 0 && len(tradable) > 0 {
+		contracts[tradable[op.random.Intn(len(tradable))]] = 1
+	}
+
+	return contracts
+}
+
+//=============================================================================
+
+func (op *OptimizationProcess) climb(contracts []int) ([]int, float64, bool) {
+	contracts, profits, ok := op.repair(contracts)
+	if !ok {
+		return nil, 0, false
+	}
+
+	fitness := op.calcFitness(contracts, op.calcMetrics(contracts, profits))
+	n       := len(contracts)
+	buffer  := make([]float64, len(profits))
+
+	for !op.stopping {
+		bestI, bestJ, bestDi, bestDj := -1, -1, 0, 0
+		bestFitness := fitness
+
+		try := func(i, di, j, dj int) {
+			if !op.canMove(contracts, i, di) || (j >= 0 && !op.canMove(contracts, j, dj)) {
+				return
+			}
+
+			op.move(contracts, buffer, profits, i, di, j, dj)
+			m := op.calcMetrics(contracts, buffer)
+
+			if op.isFeasible(m) {
+				f := op.calcFitness(contracts, m)
+				if f > bestFitness + 1e-9 {
+					bestI, bestJ, bestDi, bestDj, bestFitness = i, j, di, dj, f
+				}
+			}
+
+			contracts[i] -= di
+			if j >= 0 {
+				contracts[j] -= dj
+			}
+		}
+
+		for i := 0; i < n; i++ {
+			try(i, +1, -1, 0)
+			try(i, -1, -1, 0)
+
+			for j := 0; j < n; j++ {
+				if i != j {
+					try(i, +1, j, -1)
+				}
+			}
+		}
+
+		if bestI == -1 {
+			break
+		}
+
+		op.move(contracts, profits, profits, bestI, bestDi, bestJ, bestDj)
+		fitness = bestFitness
+	}
+
+	return contracts, fitness, true
+}
+
+//=============================================================================
+//--- Removes contracts from the largest position until all constraints are met
+
+func (op *OptimizationProcess) repair(contracts []int) ([]int, []float64, bool) {
+	profits := op.calcProfits(contracts)
+
+	for !op.isFeasible(op.calcMetrics(contracts, profits)) {
+		idx  := -1
+		size := 0.0
+
+		for i, c := range contracts {
+			value := float64(c) * math.Max(op.systems[i].MarginValue, 1)
+			if c > op.minContracts[i] && value > size {
+				idx  = i
+				size = value
+			}
+		}
+
+		if idx == -1 {
+			return nil, nil, false
+		}
+
+		op.move(contracts, profits, profits, idx, -1, -1, 0)
+	}
+
+	return contracts, profits, true
+}
+
+//=============================================================================
+
+func (op *OptimizationProcess) canMove(contracts []int, i, delta int) bool {
+	c := contracts[i] + delta
+	return c >= op.minContracts[i] && c <= op.maxContracts[i]
+}
+
+//=============================================================================
+//--- Applies the deltas to the contracts and writes the updated daily profits into dest
+
+func (op *OptimizationProcess) move(contracts []int, dest, profits []float64, i, di, j, dj int) {
+	contracts[i] += di
+	if j >= 0 {
+		contracts[j] += dj
+	}
+
+	for t := range profits {
+		value := profits[t] + float64(di) * op.series[i][t]
+		if j >= 0 {
+			value += float64(dj) * op.series[j][t]
+		}
+
+		dest[t] = value
+	}
+}
+
+//=============================================================================
+
+func (op *OptimizationProcess) calcProfits(contracts []int) []float64 {
+	if len(op.series) != len(contracts) {
+		return []float64{}
+	}
+
+	profits := make([]float64, len(op.series[0]))
+
+	for i, c := range contracts {
+		for t, value := range op.series[i] {
+			profits[t] += float64(c) * value
+		}
+	}
+
+	return profits
+}
+
+//=============================================================================
+
+func (op *OptimizationProcess) createRun(contracts []int, fitness float64) *Run {
+	m := op.calcMetrics(contracts, op.calcProfits(contracts))
+	rc, variance := op.calcRiskContributions(contracts)
+
+	r := &Run{
+		Allocations : []*Allocation{},
+		FitnessValue: fitness,
+		NetProfit   : core.Trunc2d(m.netProfit),
+		MaxDrawdown : core.Trunc2d(-m.maxDrawdown),
+		SharpeRatio : core.Trunc2d(m.sharpeRatio),
+		StdDev      : core.Trunc2d(m.stdDev),
+		Margin      : core.Trunc2d(m.margin),
+		random      : op.random.Int(),
+	}
+
+	for i, ts := range op.systems {
+		a := &Allocation{
+			TradingSystemId  : ts.Id,
+			TradingSystemName: ts.Name,
+			Contracts        : contracts[i],
+			Margin           : core.Trunc2d(float64(contracts[i]) * ts.MarginValue),
+		}
+
+		if m.margin > 0 {
+			a.Weight = core.Trunc2d(a.Margin / m.margin * 100)
+		} else {
+			a.Weight = core.Trunc2d(float64(contracts[i]) / float64(m.contracts) * 100)
+		}
+
+		if variance > 0 {
+			a.RiskContribution = core.Trunc2d(rc[i] / variance * 100)
+		}
+
+		r.Allocations = append(r.Allocations, a)
+	}
+
+	return r
+}
+
+//=============================================================================
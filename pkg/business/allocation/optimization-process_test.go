@@ -0,0 +1,114 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package allocation
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/tradalia/portfolio-trader/pkg/db"
+)
+
+//=============================================================================
+
+var systems = []*db.TradingSystem{
+	{ Id: 1, Name: "ts1", MarginValue: 1000 },
+	{ Id: 2, Name: "ts2", MarginValue: 2000 },
+}
+
+//=============================================================================
+
+func TestEmptyReturns(t *testing.T) {
+	objectives := []string{
+		ObjectiveSharpeRatio,
+		ObjectiveReturnDrawdown,
+		ObjectiveMinVariance,
+		ObjectiveEqualRiskContribution,
+	}
+
+	for _, objective := range objectives {
+		op := newTestProcess(objective, nil)
+		op.generate(3)
+
+		if op.info.Status != OptimStatusComplete {
+			t.Errorf("Optimization not complete for objective %v: %v", objective, op.info.Status)
+		}
+
+		if len(op.covariance) != len(systems) {
+			t.Errorf("Bad covariance size for objective %v. Expected %v but got %v", objective, len(systems), len(op.covariance))
+		}
+	}
+}
+
+//=============================================================================
+
+func TestOptimization(t *testing.T) {
+	series := [][]float64{
+		{ 10, -5, 20, -10, 15, 5, -20, 30 },
+		{ -5, 10, -10, 20, -5, 10, 15, -10 },
+	}
+
+	op := newTestProcess(ObjectiveSharpeRatio, series)
+	op.generate(5)
+
+	runs := op.info.GetRuns()
+	if len(runs) == 0 {
+		t.Fatalf("No runs found")
+	}
+
+	r := runs[0].(*Run)
+	if len(r.Allocations) != len(systems) {
+		t.Errorf("Bad allocations. Expected %v but got %v", len(systems), len(r.Allocations))
+	}
+
+	if r.FitnessValue < op.info.BaseValue {
+		t.Errorf("Best run is worse than the baseline: %v < %v", r.FitnessValue, op.info.BaseValue)
+	}
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func newTestProcess(objective string, series [][]float64) *OptimizationProcess {
+	op := &OptimizationProcess{
+		portfolioId: 1,
+		systems    : systems,
+		series     : series,
+		optReq     : &OptimizationRequest{ Objective: objective },
+		random     : rand.New(rand.NewSource(1)),
+	}
+
+	op.initLimits()
+	op.initCovariance()
+	op.info = NewOptimizationInfo(MaxResultSize, op.optReq, 3, 0, op.calcBaseValue())
+
+	return op
+}
+
+//=============================================================================
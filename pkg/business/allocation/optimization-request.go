@@ -0,0 +1,95 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package allocation
+
+import (
+	"errors"
+	"strconv"
+)
+
+//=============================================================================
+//===
+//=== OptimizationRequest
+//===
+//=============================================================================
+
+const ObjectiveSharpeRatio           = "sharpe"
+const ObjectiveReturnDrawdown        = "return/maxDD"
+const ObjectiveMinVariance           = "minVariance"
+const ObjectiveEqualRiskContribution = "erc"
+
+const DefaultMaxContracts = 10
+const DefaultRestarts     = 20
+const MaxRestarts         = 1000
+const MinDays             = 20
+
+//=============================================================================
+
+type ContractLimit struct {
+	TradingSystemId uint `json:"tradingSystemId"`
+	MinContracts    int  `json:"minContracts"`
+	MaxContracts    int  `json:"maxContracts"`
+}
+
+//=============================================================================
+
+type OptimizationRequest struct {
+	DaysBack    int             `json:"daysBack"`
+	Objective   string          `json:"objective"`
+	MaxMargin   float64         `json:"maxMargin"`
+	MaxDrawdown float64         `json:"maxDrawdown"`
+	Restarts    int             `json:"restarts"`
+	Limits      []ContractLimit `json:"limits"`
+}
+
+//=============================================================================
+
+func (r *OptimizationRequest) Validate() error {
+	if  r.Objective != ObjectiveSharpeRatio    &&
+		r.Objective != ObjectiveReturnDrawdown &&
+		r.Objective != ObjectiveMinVariance    &&
+		r.Objective != ObjectiveEqualRiskContribution {
+		return errors.New("Invalid objective: "+ r.Objective)
+	}
+
+	if r.DaysBack < 0 || r.MaxMargin < 0 || r.MaxDrawdown < 0 {
+		return errors.New("Days back, max margin and max drawdown cannot be negative")
+	}
+
+	if r.Restarts < 0 || r.Restarts > MaxRestarts {
+		return errors.New("Restarts must be between 0 and "+ strconv.Itoa(MaxRestarts))
+	}
+
+	for _, l := range r.Limits {
+		if l.MinContracts < 0 || l.MaxContracts < l.MinContracts {
+			return errors.New("Invalid contract limits for trading system "+ strconv.Itoa(int(l.TradingSystemId)))
+		}
+	}
+
+	return nil
+}
+
+//=============================================================================
@@ -0,0 +1,232 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package allocation
+
+import (
+	"math"
+
+	"github.com/tradalia/portfolio-trader/pkg/business/performance"
+	"github.com/tradalia/portfolio-trader/pkg/core/stats"
+)
+
+//=============================================================================
+//===
+//=== Run comparator
+//===
+//=== Notes:
+//===  - in reverse order: max to min
+//===  - objectives like the Sharpe ratio do not depend on the size, so the net
+//===    profit breaks ties
+//=============================================================================
+
+func runComparator(a any, b any) int {
+	r1 := a.(*Run)
+	r2 := b.(*Run)
+	v1 := r1.FitnessValue
+	v2 := r2.FitnessValue
+
+	if v1 < v2 { return +1 }
+	if v1 > v2 { return -1 }
+
+	if r1.NetProfit < r2.NetProfit { return +1 }
+	if r1.NetProfit > r2.NetProfit { return -1 }
+
+	if r1.random == r2.random {
+		return 0
+	}
+
+	if r1.random < r2.random {
+		return 1
+	}
+
+	return -1
+}
+
+//=============================================================================
+//===
+//=== Metrics
+//===
+//=============================================================================
+
+type metrics struct {
+	contracts   int
+	netProfit   float64
+	maxDrawdown float64
+	stdDev      float64
+	sharpeRatio float64
+	margin      float64
+}
+
+//=============================================================================
+//--- Profits are the daily net returns of the whole portfolio with the given contracts
+
+func (op *OptimizationProcess) calcMetrics(contracts []int, profits []float64) *metrics {
+	m := &metrics{}
+
+	for i, c := range contracts {
+		m.contracts += c
+		m.margin    += float64(c) * op.systems[i].MarginValue
+	}
+
+	equity := 0.0
+	peak   := 0.0
+
+	for _, p := range profits {
+		equity += p
+		peak     = math.Max(peak, equity)
+		m.maxDrawdown = math.Max(m.maxDrawdown, peak - equity)
+	}
+
+	m.netProfit = equity
+
+	if len(profits) > 0 {
+		mean := stats.Mean(profits)
+		m.stdDev = stats.StdDev(profits, mean)
+
+		if m.stdDev > 0 {
+			m.sharpeRatio = mean / m.stdDev * math.Sqrt(performance.AnnualDays)
+		}
+	}
+
+	return m
+}
+
+//=============================================================================
+
+func (op *OptimizationProcess) isFeasible(m *metrics) bool {
+	if m.contracts == 0 {
+		return false
+	}
+
+	if op.optReq.MaxMargin > 0 && m.margin > op.optReq.MaxMargin {
+		return false
+	}
+
+	if op.optReq.MaxDrawdown > 0 && m.maxDrawdown > op.optReq.MaxDrawdown {
+		return false
+	}
+
+	return true
+}
+
+//=============================================================================
+//===
+//=== Fitness functions
+//===
+//=============================================================================
+
+func (op *OptimizationProcess) calcFitness(contracts []int, m *metrics) float64 {
+	switch op.optReq.Objective {
+		case ObjectiveSharpeRatio:
+			return m.sharpeRatio
+
+		case ObjectiveReturnDrawdown:
+			dd := m.maxDrawdown
+			if dd == 0 {
+				dd = 1
+			}
+			return m.netProfit / dd
+
+		case ObjectiveMinVariance:
+			return ffMinVariance(m)
+
+		case ObjectiveEqualRiskContribution:
+			return op.ffEqualRiskContribution(contracts)
+
+		default:
+			panic("Unknown objective: "+ op.optReq.Objective)
+	}
+}
+
+//=============================================================================
+//--- The risk is measured per unit of margin (or per contract when margins are
+//--- not set), otherwise the best portfolio would always be the smallest one
+
+func ffMinVariance(m *metrics) float64 {
+	scale := m.margin
+	if scale == 0 {
+		scale = float64(m.contracts)
+	}
+
+	if scale == 0 {
+		return math.Inf(-1)
+	}
+
+	return -m.stdDev / scale
+}
+
+//=============================================================================
+//--- Distance of the risk contributions from the equal share. Systems that cannot
+//--- be traded (max contracts = 0) are not taken into account
+
+func (op *OptimizationProcess) ffEqualRiskContribution(contracts []int) float64 {
+	rc, variance := op.calcRiskContributions(contracts)
+	if variance <= 0 {
+		return math.Inf(-1)
+	}
+
+	active := 0
+	for i := range contracts {
+		if op.maxContracts[i] > 0 {
+			active++
+		}
+	}
+
+	target := 1 / float64(active)
+	sum    := 0.0
+
+	for i := range contracts {
+		if op.maxContracts[i] > 0 {
+			delta := rc[i]/variance - target
+			sum += delta * delta
+		}
+	}
+
+	return -sum
+}
+
+//=============================================================================
+//--- Risk contribution of each system: c_i * (Cov * c)_i. The sum is the variance
+//--- of the portfolio
+
+func (op *OptimizationProcess) calcRiskContributions(contracts []int) ([]float64, float64) {
+	rc       := make([]float64, len(contracts))
+	variance := 0.0
+
+	for i := range contracts {
+		sum := 0.0
+		for j := range contracts {
+			sum += op.covariance[i][j] * float64(contracts[j])
+		}
+
+		rc[i]     = float64(contracts[i]) * sum
+		variance += rc[i]
+	}
+
+	return rc, variance
+}
+
+//=============================================================================
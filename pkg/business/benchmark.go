@@ -0,0 +1,178 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"time"
+
+	"github.com/tradalia/core/auth"
+	"github.com/tradalia/core/datatype"
+	"github.com/tradalia/core/req"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+type BenchmarkSpec struct {
+	Code string `json:"code" binding:"required,max=16"`
+	Name string `json:"name" binding:"required,max=64"`
+}
+
+//=============================================================================
+
+type BenchmarkReturnItem struct {
+	Day   datatype.IntDate `json:"day"   binding:"required"`
+	Value float64          `json:"value"`
+}
+
+//-----------------------------------------------------------------------------
+
+type BenchmarkReturnsRequest struct {
+	Returns []BenchmarkReturnItem `json:"returns" binding:"required,min=1,dive"`
+}
+
+//=============================================================================
+
+func GetBenchmarks(tx *gorm.DB, c *auth.Context, filter map[string]any, offset int, limit int) (*[]db.Benchmark, error) {
+	if ! c.Session.IsAdmin() {
+		filter["username"] = c.Session.Username
+	}
+
+	return db.GetBenchmarks(tx, filter, offset, limit)
+}
+
+//=============================================================================
+
+func AddBenchmark(tx *gorm.DB, c *auth.Context, spec *BenchmarkSpec) (*db.Benchmark, error) {
+	c.Log.Info("AddBenchmark: Creating new benchmark", "code", spec.Code)
+
+	b := &db.Benchmark{
+		Username: c.Session.Username,
+		Code    : spec.Code,
+		Name    : spec.Name,
+	}
+
+	err := db.AddBenchmark(tx, b)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Log.Info("AddBenchmark: Benchmark created", "id", b.Id, "code", b.Code)
+	return b, nil
+}
+
+//=============================================================================
+
+func DeleteBenchmark(tx *gorm.DB, c *auth.Context, id uint) error {
+	c.Log.Info("DeleteBenchmark: Deleting benchmark", "id", id)
+
+	_, err := getBenchmarkAndCheckAccess(tx, c, id)
+	if err != nil {
+		return err
+	}
+
+	err = db.DeleteAllBenchmarkReturnsByBenchmarkId(tx, id)
+	if err != nil {
+		return err
+	}
+
+	return db.DeleteBenchmark(tx, id)
+}
+
+//=============================================================================
+
+func GetBenchmarkReturns(tx *gorm.DB, c *auth.Context, id uint) (*[]db.BenchmarkReturn, error) {
+	_, err := getBenchmarkAndCheckAccess(tx, c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	to   := datatype.Today(time.UTC)
+	from := to.AddDays(-50 * 365)
+
+	return db.FindBenchmarkReturnsFromDay(tx, id, from, to)
+}
+
+//=============================================================================
+
+func SetBenchmarkReturns(tx *gorm.DB, c *auth.Context, id uint, brr *BenchmarkReturnsRequest) error {
+	_, err := getBenchmarkAndCheckAccess(tx, c, id)
+	if err != nil {
+		return err
+	}
+
+	daySet := map[datatype.IntDate]bool{}
+	var list []db.BenchmarkReturn
+
+	for _, item := range brr.Returns {
+		if !item.Day.IsValid() {
+			return req.NewBadRequestError("Invalid day in benchmark returns: %v", item.Day)
+		}
+
+		if daySet[item.Day] {
+			return req.NewBadRequestError("Duplicated day in benchmark returns: %v", item.Day)
+		}
+
+		daySet[item.Day] = true
+		list = append(list, db.BenchmarkReturn{
+			BenchmarkId: id,
+			Day        : item.Day,
+			Value      : item.Value,
+		})
+	}
+
+	c.Log.Info("SetBenchmarkReturns: Setting benchmark returns", "id", id, "count", len(list))
+
+	return db.SetBenchmarkReturns(tx, id, list)
+}
+
+//=============================================================================
+//===
+//=== Private methods
+//===
+//=============================================================================
+
+func getBenchmarkAndCheckAccess(tx *gorm.DB, c *auth.Context, id uint) (*db.Benchmark, error) {
+	b, err := db.GetBenchmarkById(tx, id)
+	if err != nil {
+		c.Log.Error("getBenchmark: Cannot get the benchmark", "id", id, "error", err)
+		return nil, err
+	}
+
+	if b == nil {
+		return nil, req.NewNotFoundError("Benchmark was not found: %v", id)
+	}
+
+	if ! c.Session.IsAdmin() {
+		if b.Username != c.Session.Username {
+			return nil, req.NewForbiddenError("Benchmark not owned by user: %v", id)
+		}
+	}
+
+	return b, nil
+}
+
+//=============================================================================
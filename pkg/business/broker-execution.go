@@ -0,0 +1,307 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/tradalia/core/auth"
+	"github.com/tradalia/portfolio-trader/pkg/consts"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+type ExecutionItem struct {
+	ExecutionDate *time.Time `json:"executionDate" binding:"required"`
+	Side          string     `json:"side"          binding:"required,oneof=BUY SELL"`
+	Price         float64    `json:"price"         binding:"required"`
+	Quantity      int        `json:"quantity"      binding:"required,min=1"`
+}
+
+//-----------------------------------------------------------------------------
+
+type ExecutionsRequest struct {
+	Executions []ExecutionItem `json:"executions" binding:"required,min=1,dive"`
+}
+
+//-----------------------------------------------------------------------------
+
+type ExecutionsResponse struct {
+	Added   int `json:"added"`
+	Matched int `json:"matched"`
+}
+
+//=============================================================================
+
+func GetExecutions(tx *gorm.DB, c *auth.Context, tsId uint) (*[]db.BrokerExecution, error) {
+	_, err := getTradingSystemAndCheckAccess(tx, c, tsId)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.FindExecutionsByTradingSystemId(tx, tsId)
+}
+
+//=============================================================================
+
+func AddExecutions(tx *gorm.DB, c *auth.Context, tsId uint, er *ExecutionsRequest) (*ExecutionsResponse, error) {
+	c.Log.Info("AddExecutions: Adding broker executions to trading system", "id", tsId, "count", len(er.Executions))
+
+	ts, err := getTradingSystemAndCheckAccess(tx, c, tsId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range er.Executions {
+		be := &db.BrokerExecution{
+			TradingSystemId: ts.Id,
+			ExecutionDate  : item.ExecutionDate,
+			Side           : item.Side,
+			Price          : item.Price,
+			Quantity       : item.Quantity,
+		}
+
+		err = db.AddExecution(tx, be)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	matched, err := MatchExecutions(tx, ts.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Log.Info("AddExecutions: Operation ended", "id", tsId, "matched", matched)
+
+	return &ExecutionsResponse{
+		Added  : len(er.Executions),
+		Matched: matched,
+	}, nil
+}
+
+//=============================================================================
+//--- Fills the broker fields of trades by matching unmatched executions against
+//--- the entry and the exit of each trade. The closest fills with the right side within
+//--- the allowed window are aggregated up to the trade quantity, so partial fills and
+//--- fills shared by two trades (stop and reverse) are matched too. Unmatched executions
+//--- older than the last matched one are marked as orphaned, to skip them from now on.
+//--- Returns the number of matched executions
+
+func MatchExecutions(tx *gorm.DB, tsId uint) (int, error) {
+	executions, err := db.FindUnmatchedExecutionsByTsId(tx, tsId)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(*executions) == 0 {
+		return 0, nil
+	}
+
+	trades, err := db.FindTradesWithoutBrokerInfo(tx, tsId)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := map[uint]*db.BrokerExecution{}
+
+	for i := range *trades {
+		tr := &(*trades)[i]
+		trChanged := false
+
+		if tr.EntryDateAtBroker == nil {
+			fills := findExecutions(executions, entrySide(tr), tr.Contracts, tr.EntryDate)
+			if fills != nil {
+				tr.EntryDateAtBroker, tr.EntryPriceAtBroker = applyFills(fills, tr, changed)
+				trChanged = true
+			}
+		}
+
+		if tr.ExitDateAtBroker == nil {
+			fills := findExecutions(executions, exitSide(tr), tr.Contracts, tr.ExitDate)
+			if fills != nil {
+				tr.ExitDateAtBroker, tr.ExitPriceAtBroker = applyFills(fills, tr, changed)
+				trChanged = true
+			}
+		}
+
+		if trChanged {
+			err = db.UpdateTrade(tx, tr)
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	matched := len(changed)
+
+	for _, be := range changed {
+		err = db.UpdateExecution(tx, be)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	last, err := db.FindLastMatchedExecutionByTsId(tx, tsId)
+	if err != nil {
+		return 0, err
+	}
+
+	if last != nil {
+		for _, be := range findOrphanedExecutions(executions, last.ExecutionDate) {
+			be.Orphaned = true
+			err = db.UpdateExecution(tx, be)
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if matched > 0 {
+		slog.Info("MatchExecutions: Broker executions matched", "tsId", tsId, "matched", matched)
+	}
+
+	return matched, nil
+}
+
+//=============================================================================
+//===
+//=== Private methods
+//===
+//=============================================================================
+
+type executionFill struct {
+	execution *db.BrokerExecution
+	quantity  int
+}
+
+//=============================================================================
+//--- Returns the closest fills that make the given quantity, or nil if the fills within
+//--- the window are not enough
+
+func findExecutions(executions *[]db.BrokerExecution, side string, quantity int, date *time.Time) []executionFill {
+	window := time.Minute * consts.ExecutionMatchMinutes
+
+	var candidates []*db.BrokerExecution
+
+	for i := range *executions {
+		be := &(*executions)[i]
+
+		if be.Orphaned || be.Remaining() <= 0 || be.Side != side {
+			continue
+		}
+
+		if be.ExecutionDate.Sub(*date).Abs() > window {
+			continue
+		}
+
+		candidates = append(candidates, be)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].ExecutionDate.Sub(*date).Abs() < candidates[j].ExecutionDate.Sub(*date).Abs()
+	})
+
+	var fills []executionFill
+
+	for _, be := range candidates {
+		qty := min(be.Remaining(), quantity)
+		fills     = append(fills, executionFill{ execution: be, quantity: qty })
+		quantity -= qty
+
+		if quantity == 0 {
+			return fills
+		}
+	}
+
+	return nil
+}
+
+//=============================================================================
+//--- Assigns the fills to the trade and returns the date of the last fill and the average price
+
+func applyFills(fills []executionFill, tr *db.Trade, changed map[uint]*db.BrokerExecution) (*time.Time, float64) {
+	var date *time.Time
+	value    := 0.0
+	quantity := 0
+
+	for _, f := range fills {
+		be := f.execution
+		be.Matched += f.quantity
+		be.TradeId  = &tr.Id
+		changed[be.Id] = be
+
+		if date == nil || be.ExecutionDate.After(*date) {
+			date = be.ExecutionDate
+		}
+
+		value    += be.Price * float64(f.quantity)
+		quantity += f.quantity
+	}
+
+	return date, value / float64(quantity)
+}
+
+//=============================================================================
+
+func findOrphanedExecutions(executions *[]db.BrokerExecution, lastMatched *time.Time) []*db.BrokerExecution {
+	var list []*db.BrokerExecution
+
+	for i := range *executions {
+		be := &(*executions)[i]
+
+		if !be.Orphaned && be.Remaining() > 0 && be.ExecutionDate.Before(*lastMatched) {
+			list = append(list, be)
+		}
+	}
+
+	return list
+}
+
+//=============================================================================
+
+func entrySide(tr *db.Trade) string {
+	if tr.TradeType == db.TradeTypeLong {
+		return db.ExecutionSideBuy
+	}
+
+	return db.ExecutionSideSell
+}
+
+//=============================================================================
+
+func exitSide(tr *db.Trade) string {
+	if tr.TradeType == db.TradeTypeLong {
+		return db.ExecutionSideSell
+	}
+
+	return db.ExecutionSideBuy
+}
+
+//=============================================================================
@@ -0,0 +1,150 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package business
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tradalia/portfolio-trader/pkg/db"
+)
+
+//=============================================================================
+
+func TestFindExecutions(t *testing.T) {
+	base := date(2025, 1, 10)
+
+	tests := []struct {
+		name       string
+		executions []db.BrokerExecution
+		quantity   int
+		expected   []int
+	}{
+		{ "exact fill",    []db.BrokerExecution{ newExecution(base, 1, 0, 2, 0) },                                        2, []int{ 2 } },
+		{ "partial fills", []db.BrokerExecution{ newExecution(base, 5, 0, 1, 0), newExecution(base, 1, 0, 1, 0) },         2, []int{ 1, 1 } },
+		{ "reverse fill",  []db.BrokerExecution{ newExecution(base, 0, 0, 2, 0) },                                        1, []int{ 1 } },
+		{ "shared fill",   []db.BrokerExecution{ newExecution(base, 0, 0, 2, 1) },                                        1, []int{ 1 } },
+		{ "not enough",    []db.BrokerExecution{ newExecution(base, 0, 0, 1, 0) },                                        2, nil },
+		{ "out of window", []db.BrokerExecution{ newExecution(base, 0, 0, 2, 0), newExecution(base, 30, 0, 1, 0) },       3, nil },
+		{ "used fill",     []db.BrokerExecution{ newExecution(base, 0, 0, 2, 2), newExecution(base, 10, 100, 2, 0) },     2, []int{ 2 } },
+	}
+
+	for _, test := range tests {
+		fills := findExecutions(&test.executions, db.ExecutionSideBuy, test.quantity, base)
+
+		var quantities []int
+		for _, f := range fills {
+			quantities = append(quantities, f.quantity)
+		}
+
+		if len(quantities) != len(test.expected) {
+			t.Errorf("%v: Bad fills. Expected %v but got %v", test.name, test.expected, quantities)
+			continue
+		}
+
+		for i := range quantities {
+			if quantities[i] != test.expected[i] {
+				t.Errorf("%v: Bad fills. Expected %v but got %v", test.name, test.expected, quantities)
+				break
+			}
+		}
+	}
+}
+
+//=============================================================================
+
+func TestApplyFills(t *testing.T) {
+	base := date(2025, 1, 10)
+	tr   := &db.Trade{ Id: 7 }
+
+	executions := []db.BrokerExecution{
+		newExecution(base, 0, 100, 2, 0),
+		newExecution(base, 2, 110, 2, 0),
+	}
+
+	fills := []executionFill{
+		{ execution: &executions[0], quantity: 1 },
+		{ execution: &executions[1], quantity: 2 },
+	}
+
+	changed := map[uint]*db.BrokerExecution{}
+	execDate, price := applyFills(fills, tr, changed)
+
+	if !execDate.Equal(*executions[1].ExecutionDate) {
+		t.Errorf("Bad date. Expected %v but got %v", executions[1].ExecutionDate, execDate)
+	}
+
+	expected := (100.0 + 2 * 110) / 3
+	if price != expected {
+		t.Errorf("Bad price. Expected %v but got %v", expected, price)
+	}
+
+	if executions[0].Remaining() != 1 || executions[1].Remaining() != 0 || len(changed) != 2 {
+		t.Errorf("Bad matched quantities: %+v", executions)
+	}
+
+	if *executions[0].TradeId != tr.Id {
+		t.Errorf("Bad trade id: %v", *executions[0].TradeId)
+	}
+}
+
+//=============================================================================
+
+func TestFindOrphanedExecutions(t *testing.T) {
+	base := date(2025, 1, 10)
+
+	executions := []db.BrokerExecution{
+		newExecution(base, -60, 0, 1, 0),
+		newExecution(base, -30, 0, 1, 1),
+		newExecution(base,   0, 0, 2, 1),
+		newExecution(base,  30, 0, 1, 0),
+	}
+
+	list := findOrphanedExecutions(&executions, base)
+
+	if len(list) != 1 || list[0] != &executions[0] {
+		t.Errorf("Bad orphaned executions: %v", list)
+	}
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func newExecution(base *time.Time, minutes int, price float64, quantity int, matched int) db.BrokerExecution {
+	return db.BrokerExecution{
+		Id           : uint(minutes + 1000),
+		ExecutionDate: addMinutes(base, minutes),
+		Side         : db.ExecutionSideBuy,
+		Price        : price,
+		Quantity     : quantity,
+		Matched      : matched,
+	}
+}
+
+//=============================================================================
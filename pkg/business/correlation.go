@@ -0,0 +1,81 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package business
+
+import (
+	"time"
+
+	"github.com/tradalia/core/auth"
+	"github.com/tradalia/portfolio-trader/pkg/business/correlation"
+	"github.com/tradalia/portfolio-trader/pkg/business/performance"
+	"github.com/tradalia/portfolio-trader/pkg/consts"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+type CorrelationRequest struct {
+	SystemSelection
+	DaysBack      int `json:"daysBack"      binding:"min=0,max=10000"`
+	RollingWindow int `json:"rollingWindow" binding:"omitempty,min=10,max=1000"`
+}
+
+//=============================================================================
+
+func GetCorrelation(tx *gorm.DB, c *auth.Context, req *CorrelationRequest) (*correlation.CorrelationResponse, error) {
+	systems, err := getSelectedTradingSystems(tx, c, &req.SystemSelection)
+	if err != nil {
+		return nil, err
+	}
+
+	var fromTime *time.Time
+	if req.DaysBack > 0 {
+		from := time.Now().UTC().AddDate(0, 0, -req.DaysBack)
+		fromTime = &from
+	}
+
+	var returns []db.DailyReturn
+
+	for _, ts := range systems {
+		list, err := db.FindDailyReturnsByTsIdFromTime(tx, ts.Id, fromTime, nil)
+		if err != nil {
+			return nil, err
+		}
+		returns = append(returns, *list...)
+	}
+
+	window := req.RollingWindow
+	if window == 0 {
+		window = consts.CorrelationRollingWindow
+	}
+
+	days, series := performance.AlignDailyNetReturns(systems, &returns)
+
+	return correlation.Analyze(systems, days, series, window), nil
+}
+
+//=============================================================================
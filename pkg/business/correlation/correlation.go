@@ -0,0 +1,270 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package correlation
+
+import (
+	"math"
+
+	"github.com/tradalia/core/datatype"
+	"github.com/tradalia/portfolio-trader/pkg/consts"
+	"github.com/tradalia/portfolio-trader/pkg/core"
+	"github.com/tradalia/portfolio-trader/pkg/core/stats"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+)
+
+//=============================================================================
+
+type SystemInfo struct {
+	Id   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+//=============================================================================
+
+type RollingCorrelation struct {
+	TradingSystemIdA uint               `json:"tradingSystemIdA"`
+	TradingSystemIdB uint               `json:"tradingSystemIdB"`
+	Days             []datatype.IntDate `json:"days"`
+	Values           []float64          `json:"values"`
+}
+
+//=============================================================================
+
+type RedundantPair struct {
+	TradingSystemIdA uint    `json:"tradingSystemIdA"`
+	TradingSystemIdB uint    `json:"tradingSystemIdB"`
+	Correlation      float64 `json:"correlation"`
+}
+
+//=============================================================================
+
+//--- Overlap is the number of days used to correlate each pair
+
+type CorrelationResponse struct {
+	TradingSystems []SystemInfo          `json:"tradingSystems"`
+	Days           int                   `json:"days"`
+	Overlap        [][]int               `json:"overlap"`
+	Pearson        [][]float64           `json:"pearson"`
+	Spearman       [][]float64           `json:"spearman"`
+	Drawdown       [][]float64           `json:"drawdown"`
+	Rolling        []*RollingCorrelation `json:"rolling"`
+	Clusters       []stats.ClusterMerge  `json:"clusters"`
+	Order          []uint                `json:"order"`
+	Redundant      []*RedundantPair      `json:"redundant"`
+}
+
+//=============================================================================
+//--- Correlates the daily net returns of the given systems. Series must be aligned on
+//--- the same days (one row per system). Each pair is correlated only over the days
+//--- where both systems were live, otherwise the zeros outside the lifetime of the
+//--- shorter one would pull the correlation to 0. Clustering uses the distance sqrt(2(1-rho))
+
+func Analyze(systems []*db.TradingSystem, days []datatype.IntDate, series [][]float64, window int) *CorrelationResponse {
+	n   := len(systems)
+	res := &CorrelationResponse{
+		TradingSystems: []SystemInfo{},
+		Days          : len(days),
+		Overlap       : make([][]int, n),
+		Pearson       : newMatrix(n),
+		Spearman      : newMatrix(n),
+		Drawdown      : newMatrix(n),
+		Rolling       : []*RollingCorrelation{},
+		Clusters      : []stats.ClusterMerge{},
+		Order         : []uint{},
+		Redundant     : []*RedundantPair{},
+	}
+
+	for i, ts := range systems {
+		res.TradingSystems = append(res.TradingSystems, SystemInfo{ Id: ts.Id, Name: ts.Name })
+		res.Overlap[i]     = make([]int, n)
+	}
+
+	if n == 0 || len(days) < 2 {
+		return res
+	}
+
+	first := make([]int, n)
+	last  := make([]int, n)
+
+	for i := range series {
+		first[i], last[i] = calcLiveRange(series[i])
+		res.Overlap[i][i] = max(0, last[i] - first[i] +1)
+	}
+
+	dist := newMatrix(n)
+
+	for i := 0; i < n; i++ {
+		res.Pearson [i][i] = 1
+		res.Spearman[i][i] = 1
+		res.Drawdown[i][i] = 1
+
+		for j := i+1; j < n; j++ {
+			from := max(first[i], first[j])
+			to   := min(last[i],  last[j]) +1
+
+			var pairDays []datatype.IntDate
+			var a, b     []float64
+
+			if to - from >= 2 {
+				pairDays = days[from:to]
+				a        = series[i][from:to]
+				b        = series[j][from:to]
+				res.Overlap[i][j], res.Overlap[j][i] = len(pairDays), len(pairDays)
+			}
+
+			pearson  := safe(stats.Correlation        (a, b))
+			spearman := safe(stats.SpearmanCorrelation(a, b))
+			drawdown := safe(calcDrawdownCorrelation  (a, b, calcInDrawdown(a), calcInDrawdown(b)))
+
+			res.Pearson [i][j], res.Pearson [j][i] = core.Trunc2d(pearson),  core.Trunc2d(pearson)
+			res.Spearman[i][j], res.Spearman[j][i] = core.Trunc2d(spearman), core.Trunc2d(spearman)
+			res.Drawdown[i][j], res.Drawdown[j][i] = core.Trunc2d(drawdown), core.Trunc2d(drawdown)
+
+			d := math.Sqrt(math.Max(0, 2 * (1 - pearson)))
+			dist[i][j], dist[j][i] = d, d
+
+			res.Rolling = append(res.Rolling, calcRollingCorrelation(systems[i], systems[j], pairDays, a, b, window))
+
+			if pearson >= consts.CorrelationRedundancy {
+				res.Redundant = append(res.Redundant, &RedundantPair{
+					TradingSystemIdA: systems[i].Id,
+					TradingSystemIdB: systems[j].Id,
+					Correlation     : core.Trunc2d(pearson),
+				})
+			}
+		}
+	}
+
+	res.Clusters = stats.HierarchicalClustering(dist)
+	for _, idx := range stats.LeafOrder(res.Clusters, n) {
+		res.Order = append(res.Order, systems[idx].Id)
+	}
+
+	return res
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func newMatrix(n int) [][]float64 {
+	m := make([][]float64, n)
+	for i := range m {
+		m[i] = make([]float64, n)
+	}
+
+	return m
+}
+
+//=============================================================================
+//--- Indexes of the first and last day with a return. Days without a return before the
+//--- first trade or after the last one are outside the lifetime of the system. If the
+//--- system has no returns, the range is empty (first > last)
+
+func calcLiveRange(returns []float64) (int, int) {
+	first, last := len(returns), -1
+
+	for i, r := range returns {
+		if r != 0 {
+			first = min(first, i)
+			last  = i
+		}
+	}
+
+	return first, last
+}
+
+//=============================================================================
+//--- A constant series has no defined correlation: we return 0
+
+func safe(value float64) float64 {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0
+	}
+
+	return value
+}
+
+//=============================================================================
+//--- Marks the days where the equity built from the returns is below its peak
+
+func calcInDrawdown(returns []float64) []bool {
+	flags  := make([]bool, len(returns))
+	equity := 0.0
+	peak   := 0.0
+
+	for i, r := range returns {
+		equity += r
+		peak    = math.Max(peak, equity)
+		flags[i] = equity < peak
+	}
+
+	return flags
+}
+
+//=============================================================================
+//--- Correlation restricted to the days where at least one of the two systems is
+//--- in drawdown, to spot systems that lose money together
+
+func calcDrawdownCorrelation(a, b []float64, ddA, ddB []bool) float64 {
+	var x, y []float64
+
+	for i := range a {
+		if ddA[i] || ddB[i] {
+			x = append(x, a[i])
+			y = append(y, b[i])
+		}
+	}
+
+	if len(x) < 2 {
+		return 0
+	}
+
+	return stats.Correlation(x, y)
+}
+
+//=============================================================================
+
+func calcRollingCorrelation(tsA, tsB *db.TradingSystem, days []datatype.IntDate, a, b []float64, window int) *RollingCorrelation {
+	rc := &RollingCorrelation{
+		TradingSystemIdA: tsA.Id,
+		TradingSystemIdB: tsB.Id,
+		Days            : []datatype.IntDate{},
+		Values          : []float64{},
+	}
+
+	for i := window-1; i < len(days); i++ {
+		value := safe(stats.Correlation(a[i-window+1:i+1], b[i-window+1:i+1]))
+		rc.Days   = append(rc.Days,   days[i])
+		rc.Values = append(rc.Values, core.Trunc2d(value))
+	}
+
+	return rc
+}
+
+//=============================================================================
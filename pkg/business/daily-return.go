@@ -0,0 +1,387 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"sort"
+	"time"
+
+	"github.com/tradalia/core/auth"
+	"github.com/tradalia/core/datatype"
+	"github.com/tradalia/portfolio-trader/pkg/core"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+//--- Bars of the trading system's instrument, of any timeframe. The last bar of each
+//--- session day is used as the day's close to mark open positions to market
+
+type DailyBar struct {
+	Time  *time.Time `json:"time"  binding:"required"`
+	Close float64    `json:"close" binding:"required"`
+}
+
+//-----------------------------------------------------------------------------
+
+type DailyReturnsRequest struct {
+	Bars []DailyBar `json:"bars" binding:"dive"`
+}
+
+//-----------------------------------------------------------------------------
+
+type DailyReturnsResponse struct {
+	TradingSystems int `json:"tradingSystems"`
+	DailyReturns   int `json:"dailyReturns"`
+}
+
+//=============================================================================
+
+func RecomputeDailyReturns(tx *gorm.DB, c *auth.Context, tsId uint, drr *DailyReturnsRequest) (*DailyReturnsResponse, error) {
+	c.Log.Info("RecomputeDailyReturns: Rebuilding daily returns of trading system", "id", tsId, "bars", len(drr.Bars))
+
+	ts, err := getTradingSystemAndCheckAccess(tx, c, tsId)
+	if err != nil {
+		return nil, err
+	}
+
+	trades, err := db.FindTradesByTradingSystemId(tx, tsId)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := RebuildDailyReturns(tx, ts, trades, drr.Bars)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DailyReturnsResponse{
+		TradingSystems: 1,
+		DailyReturns  : count,
+	}, nil
+}
+
+//=============================================================================
+//--- Bars are specific to an instrument, so they cannot be used here
+
+func RecomputeAllDailyReturns(tx *gorm.DB, c *auth.Context) (*DailyReturnsResponse, error) {
+	filter := map[string]any{}
+	if ! c.Session.IsAdmin() {
+		filter["username"] = c.Session.Username
+	}
+
+	list, err := db.GetTradingSystems(tx, filter, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Log.Info("RecomputeAllDailyReturns: Rebuilding daily returns of trading systems", "count", len(*list))
+
+	res := &DailyReturnsResponse{}
+
+	for _, ts := range *list {
+		trades, err := db.FindTradesByTradingSystemId(tx, ts.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		count, err := RebuildDailyReturns(tx, &ts, trades, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		res.TradingSystems++
+		res.DailyReturns += count
+	}
+
+	return res, nil
+}
+
+//=============================================================================
+//--- Builds the daily returns from the trades. Each trade's profit goes to its exit day,
+//--- in the exchange timezone and considering the session (a session that crosses
+//--- midnight belongs to the next day). If bars are provided, trades that last more
+//--- than one day are marked to market using the daily closes
+
+func BuildDailyReturns(ts *db.TradingSystem, trades *[]db.Trade, bars []DailyBar) []*db.DailyReturn {
+	dayOf := getDayFunction(ts)
+
+	closeDays, closes := buildDailyCloses(bars, dayOf)
+	dayMap := map[datatype.IntDate]*db.DailyReturn{}
+
+	origin := db.DailyReturnOriginTrades
+	if len(closeDays) > 0 {
+		origin = db.DailyReturnOriginMarked
+	}
+
+	addProfit := func(day datatype.IntDate, profit float64, trades int) {
+		dr, ok := dayMap[day]
+		if !ok {
+			dr = &db.DailyReturn{
+				TradingSystemId: ts.Id,
+				Day            : day,
+				Origin         : origin,
+			}
+			dayMap[day] = dr
+		}
+
+		dr.GrossProfit += profit
+		dr.Trades      += trades
+	}
+
+	for _, tr := range *trades {
+		if tr.Excluded {
+			continue
+		}
+
+		entryDay := dayOf(tr.EntryDate)
+		exitDay  := dayOf(tr.ExitDate)
+		marked   := 0.0
+
+		if len(closeDays) > 0 && entryDay < exitDay {
+			direction := 1.0
+			if tr.TradeType == db.TradeTypeShort {
+				direction = -1
+			}
+
+			multiplier := ts.PointValue * float64(tr.Contracts) * direction
+			mark       := tr.EntryPrice
+
+			for i := sort.Search(len(closeDays), func(i int) bool { return closeDays[i] >= entryDay }); i < len(closeDays) && closeDays[i] < exitDay; i++ {
+				closePrice := closes[closeDays[i]]
+				profit     := (closePrice - mark) * multiplier
+
+				addProfit(closeDays[i], profit, 0)
+				marked += profit
+				mark    = closePrice
+			}
+		}
+
+		//--- The exit day gets the remaining profit, so that the total matches the trade
+
+		addProfit(exitDay, tr.GrossProfit - marked, 1)
+	}
+
+	var list []*db.DailyReturn
+	for _, dr := range dayMap {
+		dr.GrossProfit = core.Trunc2d(dr.GrossProfit)
+		list = append(list, dr)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Day < list[j].Day
+	})
+
+	return list
+}
+
+//=============================================================================
+//--- Replaces the daily returns derived from trades (marked to market or not) with the
+//--- ones built now. Daily returns sent by the runtime are never replaced. Returns the
+//--- number of daily returns added
+
+func RebuildDailyReturns(tx *gorm.DB, ts *db.TradingSystem, trades *[]db.Trade, bars []DailyBar) (int, error) {
+	return mergeDailyReturns(tx, ts, BuildDailyReturns(ts, trades, bars), nil, true)
+}
+
+//=============================================================================
+//--- Rebuilds from trades the given days (all days if nil) and adds the missing ones.
+//--- Only daily returns derived from trades without bars are replaced, so runtime and
+//--- marked to market returns are kept
+
+func UpdateDailyReturns(tx *gorm.DB, ts *db.TradingSystem, trades *[]db.Trade, days map[datatype.IntDate]bool) (int, error) {
+	return mergeDailyReturns(tx, ts, BuildDailyReturns(ts, trades, nil), days, false)
+}
+
+//=============================================================================
+//--- Adds the daily returns built from trades only for the days that have none
+
+func AddMissingDailyReturns(tx *gorm.DB, ts *db.TradingSystem, trades *[]db.Trade) (int, error) {
+	return mergeDailyReturns(tx, ts, BuildDailyReturns(ts, trades, nil), map[datatype.IntDate]bool{}, false)
+}
+
+//=============================================================================
+//--- Returns the days the trades belong to (i.e. their exit days)
+
+func GetTradeDays(ts *db.TradingSystem, trades []*db.Trade) map[datatype.IntDate]bool {
+	dayOf := getDayFunction(ts)
+	days  := map[datatype.IntDate]bool{}
+
+	for _, tr := range trades {
+		if tr.ExitDate != nil {
+			days[dayOf(tr.ExitDate)] = true
+		}
+	}
+
+	return days
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+//--- Daily returns sent by the runtime are never rebuilt from trades, so the change of a
+//--- live trade (exclusion, update or deletion) is applied to them as a difference: the
+//--- trade before the change (nil if added) is removed from its day and the trade after
+//--- the change (nil if deleted) is added. Backtest trades are not part of the runtime
+//--- returns, so they are ignored
+
+func adjustRuntimeDailyReturns(tx *gorm.DB, ts *db.TradingSystem, before *db.Trade, after *db.Trade) error {
+	dayOf   := getDayFunction(ts)
+	profits := map[datatype.IntDate]float64{}
+	trades  := map[datatype.IntDate]int{}
+
+	addTrade := func(tr *db.Trade, sign int) {
+		if tr != nil && !tr.Excluded && tr.ExitDate != nil && tr.IsLive(ts) {
+			day := dayOf(tr.ExitDate)
+			profits[day] += float64(sign) * tr.GrossProfit
+			trades[day]  += sign
+		}
+	}
+
+	addTrade(before, -1)
+	addTrade(after,   1)
+
+	if len(profits) == 0 {
+		return nil
+	}
+
+	list, err := db.FindDailyReturnsByTradingSystemId(tx, ts.Id)
+	if err != nil {
+		return err
+	}
+
+	for _, dr := range *list {
+		if !dr.IsFromRuntime() {
+			continue
+		}
+
+		profit, ok := profits[dr.Day]
+		if !ok || (profit == 0 && trades[dr.Day] == 0) {
+			continue
+		}
+
+		dr.GrossProfit = core.Trunc2d(dr.GrossProfit + profit)
+		dr.Trades      = max(dr.Trades + trades[dr.Day], 0)
+
+		err = db.UpdateDailyReturn(tx, &dr)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//=============================================================================
+//--- A trade's day is computed in the exchange timezone and considering the session:
+//--- a session that crosses midnight belongs to the next day
+
+func getDayFunction(ts *db.TradingSystem) func(t *time.Time) datatype.IntDate {
+	loc, err := time.LoadLocation(ts.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	session := core.ParseSession(ts.SessionConfig)
+
+	return func(t *time.Time) datatype.IntDate {
+		lt := t.In(loc)
+		if session != nil {
+			lt = session.Date(lt)
+		}
+
+		return datatype.IntDate(lt.Year()*10000 + int(lt.Month())*100 + lt.Day())
+	}
+}
+
+//=============================================================================
+//--- Daily returns of the given days (all days if nil) are replaced if derived from
+//--- trades. Days that have a daily return which is kept are not added
+
+func mergeDailyReturns(tx *gorm.DB, ts *db.TradingSystem, list []*db.DailyReturn, days map[datatype.IntDate]bool, replaceMarked bool) (int, error) {
+	existing, err := db.FindDailyReturnsByTradingSystemId(tx, ts.Id)
+	if err != nil {
+		return 0, err
+	}
+
+	keepSet := map[datatype.IntDate]bool{}
+
+	for _, dr := range *existing {
+		replace := dr.Origin == db.DailyReturnOriginTrades || (replaceMarked && dr.Origin == db.DailyReturnOriginMarked)
+
+		if replace && (days == nil || days[dr.Day]) {
+			err = db.DeleteDailyReturn(tx, dr.Id)
+			if err != nil {
+				return 0, err
+			}
+		} else {
+			keepSet[dr.Day] = true
+		}
+	}
+
+	count := 0
+
+	for _, dr := range list {
+		if !keepSet[dr.Day] {
+			err = db.AddDailyReturn(tx, dr)
+			if err != nil {
+				return 0, err
+			}
+
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+//=============================================================================
+
+func buildDailyCloses(bars []DailyBar, dayOf func(t *time.Time) datatype.IntDate) ([]datatype.IntDate, map[datatype.IntDate]float64) {
+	sorted := make([]DailyBar, len(bars))
+	copy(sorted, bars)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(*sorted[j].Time)
+	})
+
+	closes := map[datatype.IntDate]float64{}
+	var days []datatype.IntDate
+
+	for _, bar := range sorted {
+		day := dayOf(bar.Time)
+		if _, ok := closes[day]; !ok {
+			days = append(days, day)
+		}
+
+		closes[day] = bar.Close
+	}
+
+	return days, closes
+}
+
+//=============================================================================
@@ -0,0 +1,206 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package divergence
+
+import (
+	"math"
+	"time"
+
+	"github.com/tradalia/portfolio-trader/pkg/consts"
+	"github.com/tradalia/portfolio-trader/pkg/core"
+	"github.com/tradalia/portfolio-trader/pkg/core/stats"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+)
+
+//=============================================================================
+
+type SampleInfo struct {
+	Trades         int     `json:"trades"`
+	NetProfit      float64 `json:"netProfit"`
+	NetAvgTrade    float64 `json:"netAvgTrade"`
+	NetWinPerc     float64 `json:"netWinPerc"`
+	TradesPerMonth float64 `json:"tradesPerMonth"`
+}
+
+//=============================================================================
+
+type TestResult struct {
+	Statistic float64 `json:"statistic"`
+	PValue    float64 `json:"pValue"`
+}
+
+//=============================================================================
+
+type DivergenceResponse struct {
+	TradingSystemId   uint        `json:"tradingSystemId"`
+	Backtest          SampleInfo  `json:"backtest"`
+	Live              SampleInfo  `json:"live"`
+	KolmogorovSmirnov *TestResult `json:"kolmogorovSmirnov"`
+	MannWhitney       *TestResult `json:"mannWhitney"`
+	AvgTradeRatio     float64     `json:"avgTradeRatio"`
+	FrequencyRatio    float64     `json:"frequencyRatio"`
+	Checked           bool        `json:"checked"`
+	Divergent         bool        `json:"divergent"`
+	Reasons           []string    `json:"reasons"`
+}
+
+//=============================================================================
+//--- Compares the net profit distribution of live trades against the backtest ones.
+//--- Trades must be sorted by entry date
+
+func Analyze(ts *db.TradingSystem, trades *[]db.Trade) *DivergenceResponse {
+	var btProfits, liveProfits []float64
+	var btFrom, btTo, liveFrom *time.Time
+
+	cost := ts.CostPerOperation
+
+	for _, tr := range *trades {
+		profit := tr.GrossProfit - 2 * cost
+
+		if tr.IsLive(ts) {
+			liveProfits = append(liveProfits, profit)
+			if liveFrom == nil {
+				liveFrom = tr.EntryDate
+			}
+		} else {
+			btProfits = append(btProfits, profit)
+			if btFrom == nil {
+				btFrom = tr.EntryDate
+			}
+			btTo = tr.ExitDate
+		}
+	}
+
+	//--- Live frequency is measured until now, so that a system that stopped trading
+	//--- shows a lower frequency
+
+	if ts.RunningSince != nil && (liveFrom == nil || ts.RunningSince.Before(*liveFrom)) {
+		liveFrom = ts.RunningSince
+	}
+
+	now := time.Now()
+
+	res := &DivergenceResponse{
+		TradingSystemId: ts.Id,
+		Backtest       : newSampleInfo(btProfits,   btFrom,   btTo),
+		Live           : newSampleInfo(liveProfits, liveFrom, &now),
+		Reasons        : []string{},
+	}
+
+	if len(liveProfits) < consts.DivergenceMinLiveTrades || len(btProfits) < consts.DivergenceMinLiveTrades {
+		return res
+	}
+
+	res.Checked        = true
+	res.AvgTradeRatio  = calcRatio(res.Live.NetAvgTrade,    res.Backtest.NetAvgTrade)
+	res.FrequencyRatio = calcRatio(res.Live.TradesPerMonth, res.Backtest.TradesPerMonth)
+
+	ksD,  ksP  := stats.KolmogorovSmirnov(btProfits, liveProfits)
+	mwU,  mwP  := stats.MannWhitney      (btProfits, liveProfits)
+
+	res.KolmogorovSmirnov = &TestResult{ Statistic: core.Trunc2d(ksD), PValue: truncPValue(ksP) }
+	res.MannWhitney       = &TestResult{ Statistic: core.Trunc2d(mwU), PValue: truncPValue(mwP) }
+
+	if ksP < consts.DivergenceMaxPValue {
+		res.Reasons = append(res.Reasons, "Live trade distribution differs from backtest (Kolmogorov-Smirnov)")
+	}
+
+	if mwP < consts.DivergenceMaxPValue {
+		res.Reasons = append(res.Reasons, "Live trade profits are shifted from backtest (Mann-Whitney)")
+	}
+
+	if res.Backtest.NetAvgTrade > 0 && res.AvgTradeRatio < consts.DivergenceMinAvgTradeRatio {
+		res.Reasons = append(res.Reasons, "Live average trade dropped below backtest threshold")
+	}
+
+	if res.Backtest.TradesPerMonth > 0 && math.Abs(res.FrequencyRatio - 1) > consts.DivergenceMaxFrequencyDrift {
+		res.Reasons = append(res.Reasons, "Live trade frequency drifted from backtest")
+	}
+
+	res.Divergent = len(res.Reasons) > 0
+
+	return res
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func newSampleInfo(profits []float64, from, to *time.Time) SampleInfo {
+	si := SampleInfo{
+		Trades: len(profits),
+	}
+
+	if len(profits) == 0 {
+		return si
+	}
+
+	winCount := 0
+	for _, p := range profits {
+		si.NetProfit += p
+		if p > 0 {
+			winCount++
+		}
+	}
+
+	si.NetAvgTrade = core.Trunc2d(si.NetProfit / float64(len(profits)))
+	si.NetWinPerc  = core.Trunc2d(float64(winCount) / float64(len(profits)) * 100)
+	si.NetProfit   = core.Trunc2d(si.NetProfit)
+
+	if from != nil && to != nil {
+		days := to.Sub(*from).Hours() / 24
+		if days < 1 {
+			days = 1
+		}
+
+		si.TradesPerMonth = core.Trunc2d(float64(len(profits)) / days * consts.DaysPerMonth)
+	}
+
+	return si
+}
+
+//=============================================================================
+
+func calcRatio(value, reference float64) float64 {
+	if reference == 0 {
+		return 0
+	}
+
+	return core.Trunc2d(value / reference)
+}
+
+//=============================================================================
+
+func truncPValue(value float64) float64 {
+	if math.IsNaN(value) {
+		return 1
+	}
+
+	return math.Floor(value * 10000) / 10000
+}
+
+//=============================================================================
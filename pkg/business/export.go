@@ -0,0 +1,239 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"fmt"
+
+	"github.com/tradalia/portfolio-trader/pkg/business/filter"
+	"github.com/tradalia/portfolio-trader/pkg/business/performance"
+	"github.com/tradalia/portfolio-trader/pkg/core/export"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+)
+
+//=============================================================================
+//===
+//=== Conversion of responses into exportable tables (CSV / XLSX)
+//===
+//=============================================================================
+
+func TradesTables(trades *[]db.Trade) []*export.Table {
+	return []*export.Table{ buildTradesTable(trades) }
+}
+
+//=============================================================================
+
+func PerformanceAnalysisTables(res *performance.AnalysisResponse) []*export.Table {
+	tables := []*export.Table{
+		buildTradesTable(res.Trades),
+		buildEquityTable("equities", res.AllEquities),
+		buildEquityTable("equities-long",  res.LongEquities),
+		buildEquityTable("equities-short", res.ShortEquities),
+		buildPeriodTable("annual",    res.Aggregates.Annual),
+		buildPeriodTable("quarterly", res.Aggregates.Quarterly),
+		buildPeriodTable("monthly",   res.Aggregates.Monthly),
+		buildPeriodTable("weekly",    res.Aggregates.Weekly),
+	}
+
+	ym := export.NewTable("year-month", "year", "jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec", "total")
+	for _, r := range res.Aggregates.YearMonth {
+		row := []any{ r.Year }
+		for _, v := range r.Months {
+			row = append(row, v)
+		}
+
+		ym.AddRow(append(row, r.Total)...)
+	}
+
+	return append(tables, ym)
+}
+
+//=============================================================================
+
+func FilterAnalysisTables(res *filter.AnalysisResponse) []*export.Table {
+	eq := &res.Equities
+	t  := export.NewTable("equities", "time", "netProfit", "unfilteredEquity", "filteredEquity", "unfilteredDrawdown", "filteredDrawdown", "filterActivation")
+
+	for i, tm := range eq.Time {
+		t.AddRow(tm, eq.NetProfit[i], eq.UnfilteredEquity[i], eq.FilteredEquity[i], eq.UnfilteredDrawdown[i], eq.FilteredDrawdown[i], eq.FilterActivation[i])
+	}
+
+	s := export.NewTable("summary", "metric", "unfiltered", "filtered")
+	s.AddRow("profit",      res.Summary.UnfProfit,       res.Summary.FilProfit)
+	s.AddRow("maxDrawdown", res.Summary.UnfMaxDrawdown,  res.Summary.FilMaxDrawdown)
+	s.AddRow("winningPerc", res.Summary.UnfWinningPerc,  res.Summary.FilWinningPerc)
+	s.AddRow("averageTrade",res.Summary.UnfAverageTrade, res.Summary.FilAverageTrade)
+
+	return []*export.Table{ t, s }
+}
+
+//=============================================================================
+
+func FilterOptimizationTables(res *filter.OptimizationResponse) []*export.Table {
+	t := export.NewTable("runs",
+		"fitnessValue", "netProfit", "avgTrade", "maxDrawdown",
+		"equAvgEnabled", "equAvgLen", "posProEnabled", "posProLen",
+		"winPerEnabled", "winPerLen", "winPerValue",
+		"oldNewEnabled", "oldNewOldLen", "oldNewOldPerc", "oldNewNewLen",
+		"trendlineEnabled", "trendlineLen", "trendlineValue",
+		"drawdownEnabled", "drawdownMin", "drawdownMax")
+
+	for _, item := range res.Runs {
+		r, ok := item.(*filter.Run)
+		if !ok || r.Filter == nil {
+			continue
+		}
+
+		f := r.Filter
+		t.AddRow(r.FitnessValue, r.NetProfit, r.AvgTrade, r.MaxDrawdown,
+			f.EquAvgEnabled, f.EquAvgLen, f.PosProEnabled, f.PosProLen,
+			f.WinPerEnabled, f.WinPerLen, f.WinPerValue,
+			f.OldNewEnabled, f.OldNewOldLen, f.OldNewOldPerc, f.OldNewNewLen,
+			f.TrendlineEnabled, f.TrendlineLen, f.TrendlineValue,
+			f.DrawdownEnabled, f.DrawdownMin, f.DrawdownMax)
+	}
+
+	return []*export.Table{ t }
+}
+
+//=============================================================================
+
+func PortfolioAnalysisTables(res *performance.PortfolioAnalysisResponse) []*export.Table {
+	t := export.NewTable("contributions", "tradingSystemId", "name", "trades", "netProfit", "maxDrawdown", "profitShare", "drawdownShare")
+
+	for _, c := range res.Contributions {
+		t.AddRow(c.TradingSystemId, c.Name, c.Trades, c.NetProfit, c.MaxDrawdown, c.ProfitShare, c.DrawdownShare)
+	}
+
+	return append(PerformanceAnalysisTables(&res.AnalysisResponse), t)
+}
+
+//=============================================================================
+
+func PortfolioMonitoringTables(res *PortfolioMonitoringResponse) []*export.Table {
+	tables := []*export.Table{ buildMonitoringTable("portfolio", &res.BaseMonitoring) }
+
+	for _, tsm := range res.TradingSystems {
+		tables = append(tables, buildMonitoringTable(fmt.Sprintf("ts-%d", tsm.Id), &tsm.BaseMonitoring))
+	}
+
+	return tables
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func buildTradesTable(trades *[]db.Trade) *export.Table {
+	t := export.NewTable("trades",
+		"id", "tradingSystemId", "tradeType",
+		"entryDate", "entryPrice", "entryLabel",
+		"exitDate", "exitPrice", "exitLabel",
+		"grossProfit", "contracts",
+		"entryDateAtBroker", "entryPriceAtBroker", "exitDateAtBroker", "exitPriceAtBroker",
+		"maxAdverseExcursion", "maxFavorableExcursion", "origin", "excluded")
+
+	if trades == nil {
+		return t
+	}
+
+	for _, tr := range *trades {
+		t.AddRow(tr.Id, tr.TradingSystemId, tr.TradeType,
+			tr.EntryDate, tr.EntryPrice, tr.EntryLabel,
+			tr.ExitDate,  tr.ExitPrice,  tr.ExitLabel,
+			tr.GrossProfit, tr.Contracts,
+			tr.EntryDateAtBroker, tr.EntryPriceAtBroker, tr.ExitDateAtBroker, tr.ExitPriceAtBroker,
+			tr.MaxAdverseExcursion, tr.MaxFavorableExcursion, tr.Origin, tr.Excluded)
+	}
+
+	return t
+}
+
+//=============================================================================
+
+func buildEquityTable(name string, eq *performance.Equities) *export.Table {
+	t := export.NewTable(name, "time", "grossEquity", "netEquity", "grossDrawdown", "netDrawdown")
+
+	if eq == nil || eq.Time == nil {
+		return t
+	}
+
+	for i, tm := range *eq.Time {
+		t.AddRow(tm, (*eq.GrossEquity)[i], (*eq.NetEquity)[i], (*eq.GrossDrawdown)[i], (*eq.NetDrawdown)[i])
+	}
+
+	return t
+}
+
+//=============================================================================
+
+func buildPeriodTable(name string, list *[]*performance.PeriodAggregate) *export.Table {
+	t := export.NewTable(name,
+		"period", "trades",
+		"grossProfit", "grossAvgTrade", "grossWinPerc", "grossMaxDrawdown",
+		"netProfit",   "netAvgTrade",   "netWinPerc",   "netMaxDrawdown")
+
+	if list == nil {
+		return t
+	}
+
+	for _, pa := range *list {
+		t.AddRow(pa.Period, pa.Trades,
+			pa.GrossProfit, pa.GrossAvgTrade, pa.GrossWinPerc, pa.GrossMaxDrawdown,
+			pa.NetProfit,   pa.NetAvgTrade,   pa.NetWinPerc,   pa.NetMaxDrawdown)
+	}
+
+	return t
+}
+
+//=============================================================================
+
+func buildMonitoringTable(name string, bm *BaseMonitoring) *export.Table {
+	t := export.NewTable(name, "time", "grossProfit", "netProfit", "grossDrawdown", "netDrawdown")
+
+	if bm.Time == nil {
+		return t
+	}
+
+	for i, tm := range *bm.Time {
+		t.AddRow(tm, valueAt(bm.GrossProfit, i), valueAt(bm.NetProfit, i), valueAt(bm.GrossDrawdown, i), valueAt(bm.NetDrawdown, i))
+	}
+
+	return t
+}
+
+//=============================================================================
+
+func valueAt(list *[]float64, i int) any {
+	if list == nil || i >= len(*list) {
+		return nil
+	}
+
+	return (*list)[i]
+}
+
+//=============================================================================
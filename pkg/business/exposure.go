@@ -0,0 +1,354 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package business
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/tradalia/core/auth"
+	"github.com/tradalia/portfolio-trader/pkg/business/performance"
+	"github.com/tradalia/portfolio-trader/pkg/core"
+	"github.com/tradalia/portfolio-trader/pkg/core/stats"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+type StatusCount struct {
+	Off     int `json:"off"`
+	Paused  int `json:"paused"`
+	Running int `json:"running"`
+	Idle    int `json:"idle"`
+	Broken  int `json:"broken"`
+}
+
+//=============================================================================
+
+type ExposureItem struct {
+	Key          string  `json:"key"`
+	Systems      int     `json:"systems"`
+	Margin       float64 `json:"margin"`
+	OpenExposure float64 `json:"openExposure"`
+}
+
+//=============================================================================
+//--- Worst case losses of the active systems, built from their historical max
+//--- drawdowns (negative values):
+//--- - SumOfDrawdowns     : all systems hit their max drawdown at the same time
+//--- - CorrelatedDrawdown : drawdowns combined using the correlation of daily returns
+//--- - CombinedDrawdown   : max drawdown of the combined equity
+//--- - MaxSystemDrawdown  : the worst drawdown of a single system
+
+type WorstCaseLoss struct {
+	SumOfDrawdowns     float64 `json:"sumOfDrawdowns"`
+	CorrelatedDrawdown float64 `json:"correlatedDrawdown"`
+	CombinedDrawdown   float64 `json:"combinedDrawdown"`
+	MaxSystemDrawdown  float64 `json:"maxSystemDrawdown"`
+}
+
+//=============================================================================
+
+type ExposureSummary struct {
+	TradingSystems int             `json:"tradingSystems"`
+	ActiveSystems  int             `json:"activeSystems"`
+	Margin         float64         `json:"margin"`
+	OpenExposure   float64         `json:"openExposure"`
+	Statuses       StatusCount     `json:"statuses"`
+	ByMarketType   []*ExposureItem `json:"byMarketType"`
+	ByDataSymbol   []*ExposureItem `json:"byDataSymbol"`
+	ByCurrency     []*ExposureItem `json:"byCurrency"`
+	WorstCase      WorstCaseLoss   `json:"worstCase"`
+}
+
+//=============================================================================
+
+type PortfolioExposure struct {
+	PortfolioId uint   `json:"portfolioId"`
+	Name        string `json:"name"`
+	ExposureSummary
+}
+
+//=============================================================================
+//--- Total includes all systems of the user, also the ones not in a portfolio
+
+type ExposureResponse struct {
+	Timestamp  time.Time            `json:"timestamp"`
+	Total      ExposureSummary      `json:"total"`
+	Portfolios []*PortfolioExposure `json:"portfolios"`
+}
+
+//=============================================================================
+
+func GetExposure(tx *gorm.DB, c *auth.Context) (*ExposureResponse, error) {
+	username := c.Session.Username
+
+	tsList, err := db.GetTradingSystemsByUser(tx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	var systems []*db.TradingSystem
+	for i := range *tsList {
+		systems = append(systems, &(*tsList)[i])
+	}
+
+	seMap, err := calcSystemExposures(tx, systems)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ExposureResponse{
+		Timestamp : time.Now(),
+		Total     : *buildExposureSummary(systems, seMap),
+		Portfolios: []*PortfolioExposure{},
+	}
+
+	poList, err := db.GetPortfoliosByUser(tx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range *poList {
+		p := &(*poList)[i]
+
+		list, err := getPortfolioTradingSystems(tx, p)
+		if err != nil {
+			return nil, err
+		}
+
+		res.Portfolios = append(res.Portfolios, &PortfolioExposure{
+			PortfolioId    : p.Id,
+			Name           : p.Name,
+			ExposureSummary: *buildExposureSummary(list, seMap),
+		})
+	}
+
+	sort.Slice(res.Portfolios, func(i, j int) bool {
+		return res.Portfolios[i].Name < res.Portfolios[j].Name
+	})
+
+	return res, nil
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+type systemExposure struct {
+	active       bool
+	margin       float64
+	openExposure float64
+	returns      []db.DailyReturn
+}
+
+//=============================================================================
+
+func calcSystemExposures(tx *gorm.DB, systems []*db.TradingSystem) (map[uint]*systemExposure, error) {
+	seMap := map[uint]*systemExposure{}
+
+	for _, ts := range systems {
+		se := &systemExposure{
+			active: ts.Running && ts.Active,
+		}
+
+		if se.active {
+			margin, err := calcMargin(tx, ts)
+			if err != nil {
+				return nil, err
+			}
+
+			se.margin = margin
+
+			list, err := db.FindDailyReturnsByTsIdFromTime(tx, ts.Id, nil, nil)
+			if err != nil {
+				return nil, err
+			}
+
+			se.returns = *list
+		}
+
+		exposure, err := calcOpenExposure(tx, ts)
+		if err != nil {
+			return nil, err
+		}
+
+		se.openExposure = exposure
+		seMap[ts.Id]    = se
+	}
+
+	return seMap, nil
+}
+
+//=============================================================================
+
+func buildExposureSummary(systems []*db.TradingSystem, seMap map[uint]*systemExposure) *ExposureSummary {
+	es := &ExposureSummary{
+		TradingSystems: len(systems),
+	}
+
+	marketMap   := map[string]*ExposureItem{}
+	symbolMap   := map[string]*ExposureItem{}
+	currencyMap := map[string]*ExposureItem{}
+
+	var active []*db.TradingSystem
+
+	for _, ts := range systems {
+		se := seMap[ts.Id]
+
+		if se.active {
+			es.ActiveSystems++
+			active = append(active, ts)
+		}
+
+		es.Margin       += se.margin
+		es.OpenExposure += se.openExposure
+
+		addExposureItem(marketMap,   ts.MarketType,   se)
+		addExposureItem(symbolMap,   ts.DataSymbol,   se)
+		addExposureItem(currencyMap, ts.CurrencyCode, se)
+
+		switch ts.Status {
+			case db.TsStatusOff:
+				es.Statuses.Off++
+			case db.TsStatusPaused:
+				es.Statuses.Paused++
+			case db.TsStatusRunning:
+				es.Statuses.Running++
+			case db.TsStatusIdle:
+				es.Statuses.Idle++
+			case db.TsStatusBroken:
+				es.Statuses.Broken++
+		}
+	}
+
+	es.Margin       = core.Trunc2d(es.Margin)
+	es.OpenExposure = core.Trunc2d(es.OpenExposure)
+	es.ByMarketType = toExposureItems(marketMap)
+	es.ByDataSymbol = toExposureItems(symbolMap)
+	es.ByCurrency   = toExposureItems(currencyMap)
+	es.WorstCase    = calcWorstCaseLoss(active, seMap)
+
+	return es
+}
+
+//=============================================================================
+
+func addExposureItem(itemMap map[string]*ExposureItem, key string, se *systemExposure) {
+	item, ok := itemMap[key]
+	if !ok {
+		item = &ExposureItem{
+			Key: key,
+		}
+		itemMap[key] = item
+	}
+
+	item.Systems++
+	item.Margin       += se.margin
+	item.OpenExposure += se.openExposure
+}
+
+//=============================================================================
+
+func toExposureItems(itemMap map[string]*ExposureItem) []*ExposureItem {
+	list := []*ExposureItem{}
+
+	for _, item := range itemMap {
+		item.Margin       = core.Trunc2d(item.Margin)
+		item.OpenExposure = core.Trunc2d(item.OpenExposure)
+		list = append(list, item)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Key < list[j].Key
+	})
+
+	return list
+}
+
+//=============================================================================
+
+func calcWorstCaseLoss(systems []*db.TradingSystem, seMap map[uint]*systemExposure) WorstCaseLoss {
+	wcl := WorstCaseLoss{}
+
+	var returns []db.DailyReturn
+	for _, ts := range systems {
+		returns = append(returns, seMap[ts.Id].returns...)
+	}
+
+	_, series := performance.AlignDailyNetReturns(systems, &returns)
+	if series == nil {
+		return wcl
+	}
+
+	//--- Drawdown of each system and of the combined equity
+
+	drawdowns := make([]float64, len(series))
+	combined  := make([]float64, len(series[0]))
+
+	for i, profits := range series {
+		_, drawdowns[i] = core.BuildDrawDown(core.BuildEquity(&profits))
+
+		wcl.SumOfDrawdowns += drawdowns[i]
+		wcl.MaxSystemDrawdown = math.Min(wcl.MaxSystemDrawdown, drawdowns[i])
+
+		for d, v := range profits {
+			combined[d] += v
+		}
+	}
+
+	_, wcl.CombinedDrawdown = core.BuildDrawDown(core.BuildEquity(&combined))
+
+	//--- Drawdowns combined like the volatilities of a portfolio
+
+	variance := 0.0
+
+	for i := range series {
+		for j := range series {
+			corr := 1.0
+			if i != j {
+				corr = stats.Correlation(series[i], series[j])
+			}
+
+			variance += corr * drawdowns[i] * drawdowns[j]
+		}
+	}
+
+	wcl.CorrelatedDrawdown = -math.Sqrt(math.Max(variance, 0))
+
+	wcl.SumOfDrawdowns     = core.Trunc2d(wcl.SumOfDrawdowns)
+	wcl.CorrelatedDrawdown = core.Trunc2d(wcl.CorrelatedDrawdown)
+	wcl.CombinedDrawdown   = core.Trunc2d(wcl.CombinedDrawdown)
+	wcl.MaxSystemDrawdown  = core.Trunc2d(wcl.MaxSystemDrawdown)
+
+	return wcl
+}
+
+//=============================================================================
@@ -0,0 +1,51 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"github.com/tradalia/core/auth"
+	"github.com/tradalia/portfolio-trader/pkg/business/performance"
+	"github.com/tradalia/portfolio-trader/pkg/business/report"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+func GetTradingSystemFactsheet(tx *gorm.DB, c *auth.Context, tsId uint, req *performance.AnalysisRequest) ([]byte, error) {
+	res, err := RunPerformanceAnalysis(tx, c, tsId, req)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := report.NewTradingSystemFactsheet(res)
+	if err != nil {
+		c.Log.Error("GetTradingSystemFactsheet: Cannot build factsheet", "id", tsId, "error", err)
+		return nil, err
+	}
+
+	return report.Render(f)
+}
+
+//=============================================================================
@@ -0,0 +1,216 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package business
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/tradalia/core/auth"
+	"github.com/tradalia/portfolio-trader/pkg/business/performance"
+	"github.com/tradalia/portfolio-trader/pkg/core"
+	"github.com/tradalia/portfolio-trader/pkg/core/stats"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+const (
+	GroupByTag          = "tag"
+	GroupByMarketType   = "marketType"
+	GroupByStrategyType = "strategyType"
+)
+
+//=============================================================================
+
+type AggregationRequest struct {
+	SystemSelection
+	GroupBy  string `json:"groupBy"  binding:"required,oneof=tag marketType strategyType"`
+	DaysBack int    `json:"daysBack" binding:"min=0,max=10000"`
+}
+
+//=============================================================================
+//--- When grouping by tag, a system with several tags is counted in each of them,
+//--- so profit shares may not sum up to 100
+
+type AggregationItem struct {
+	Key         string  `json:"key"`
+	TsIds       []uint  `json:"tsIds"`
+	NetProfit   float64 `json:"netProfit"`
+	MaxDrawdown float64 `json:"maxDrawdown"`
+	Trades      int     `json:"trades"`
+	SharpeRatio float64 `json:"sharpeRatio"`
+	ProfitShare float64 `json:"profitShare"`
+}
+
+//=============================================================================
+
+type AggregationResponse struct {
+	GroupBy   string             `json:"groupBy"`
+	NetProfit float64            `json:"netProfit"`
+	Items     []*AggregationItem `json:"items"`
+}
+
+//=============================================================================
+
+func GetPerformanceAggregation(tx *gorm.DB, c *auth.Context, req *AggregationRequest) (*AggregationResponse, error) {
+	systems, err := getSelectedTradingSystems(tx, c, &req.SystemSelection)
+	if err != nil {
+		return nil, err
+	}
+
+	var fromTime *time.Time
+	if req.DaysBack > 0 {
+		from := time.Now().UTC().AddDate(0, 0, -req.DaysBack)
+		fromTime = &from
+	}
+
+	var returns []db.DailyReturn
+	trades := map[uint]int{}
+
+	for _, ts := range systems {
+		list, err := db.FindDailyReturnsByTsIdFromTime(tx, ts.Id, fromTime, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, dr := range *list {
+			trades[ts.Id] += dr.Trades
+		}
+		returns = append(returns, *list...)
+	}
+
+	_, series := performance.AlignDailyNetReturns(systems, &returns)
+
+	res := &AggregationResponse{
+		GroupBy: req.GroupBy,
+		Items  : []*AggregationItem{},
+	}
+
+	if series == nil {
+		return res, nil
+	}
+
+	//--- Group systems by key
+
+	groups := map[string][]int{}
+
+	for i, ts := range systems {
+		for _, key := range getAggregationKeys(ts, req.GroupBy) {
+			groups[key] = append(groups[key], i)
+		}
+	}
+
+	for i := range systems {
+		for _, v := range series[i] {
+			res.NetProfit += v
+		}
+	}
+
+	res.NetProfit = core.Trunc2d(res.NetProfit)
+
+	//--- Build one item per key
+
+	for key, indexes := range groups {
+		res.Items = append(res.Items, buildAggregationItem(key, indexes, systems, series, trades, res.NetProfit))
+	}
+
+	sort.Slice(res.Items, func(i, j int) bool {
+		return res.Items[i].Key < res.Items[j].Key
+	})
+
+	return res, nil
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func getAggregationKeys(ts *db.TradingSystem, groupBy string) []string {
+	switch groupBy {
+		case GroupByTag:
+			tags := ParseTags(ts.Tags)
+			if len(tags) == 0 {
+				return []string{""}
+			}
+			return tags
+
+		case GroupByMarketType:
+			return []string{ ts.MarketType }
+
+		default:
+			return []string{ ts.StrategyType }
+	}
+}
+
+//=============================================================================
+
+func buildAggregationItem(key string, indexes []int, systems []*db.TradingSystem, series [][]float64, trades map[uint]int, totProfit float64) *AggregationItem {
+	item := &AggregationItem{
+		Key  : key,
+		TsIds: []uint{},
+	}
+
+	profits := make([]float64, len(series[0]))
+
+	for _, i := range indexes {
+		ts := systems[i]
+		item.TsIds   = append(item.TsIds, ts.Id)
+		item.Trades += trades[ts.Id]
+
+		for d, v := range series[i] {
+			profits[d] += v
+		}
+	}
+
+	_, maxDD := core.BuildDrawDown(core.BuildEquity(&profits))
+
+	for _, v := range profits {
+		item.NetProfit += v
+	}
+
+	mean   := stats.Mean(profits)
+	stdDev := stats.StdDev(profits, mean)
+
+	if stdDev != 0 {
+		item.SharpeRatio = core.Trunc2d(mean / stdDev * math.Sqrt(performance.AnnualDays))
+	}
+
+	if totProfit != 0 {
+		item.ProfitShare = core.Trunc2d(item.NetProfit * 100 / totProfit)
+	}
+
+	item.NetProfit   = core.Trunc2d(item.NetProfit)
+	item.MaxDrawdown = core.Trunc2d(maxDD)
+
+	return item
+}
+
+//=============================================================================
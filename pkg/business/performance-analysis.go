@@ -73,12 +73,55 @@ func RunPerformanceAnalysis(tx *gorm.DB, c *auth.Context, tsId uint, req *perfor
 	}
 
 	res := performance.GetPerformanceAnalysis(ts, trades, returns)
+	res.Significance = performance.CalcSignificance(res, req.Trials)
+
+	//--- Compare with benchmark (if requested)
+
+	if req.BenchmarkId != 0 {
+		res.Benchmark, err = compareWithBenchmark(tx, c, ts, returns, req)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	return res, nil
 }
 
 //=============================================================================
 
+func compareWithBenchmark(tx *gorm.DB, c *auth.Context, ts *db.TradingSystem, returns *[]db.DailyReturn, r *performance.AnalysisRequest) (*performance.BenchmarkComparison, error) {
+	b, err := getBenchmarkAndCheckAccess(tx, c, r.BenchmarkId)
+	if err != nil {
+		return nil, err
+	}
+
+	capital := r.Capital
+	if capital == 0 {
+		capital = ts.MarginValue
+	}
+
+	if capital <= 0 {
+		return nil, req.NewBadRequestError("A capital is required to compare with a benchmark (trading system has no margin)")
+	}
+
+	from := datatype.IntDate(0)
+	to   := datatype.Today(time.UTC)
+
+	if len(*returns) > 0 {
+		from = (*returns)[0].Day
+		to   = (*returns)[len(*returns) -1].Day
+	}
+
+	benchReturns, err := db.FindBenchmarkReturnsFromDay(tx, b.Id, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return performance.CompareWithBenchmark(returns, ts.CostPerOperation, capital, b, benchReturns), nil
+}
+
+//=============================================================================
+
 func calcPerformancePeriod(daysBack int, fromDate, toDate datatype.IntDate, loc *time.Location) (*time.Time, *time.Time, error) {
 	//--- All
 
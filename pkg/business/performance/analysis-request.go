@@ -31,10 +31,13 @@ import (
 //=============================================================================
 
 type AnalysisRequest struct {
-	DaysBack int               `json:"daysBack" binding:"max=10000"`
-	Timezone string            `json:"timezone" binding:"required"`
-	FromDate datatype.IntDate  `json:"fromDate"`
-	ToDate   datatype.IntDate  `json:"toDate"`
+	DaysBack    int               `json:"daysBack" binding:"max=10000"`
+	Timezone    string            `json:"timezone" binding:"required"`
+	FromDate    datatype.IntDate  `json:"fromDate"`
+	ToDate      datatype.IntDate  `json:"toDate"`
+	BenchmarkId uint              `json:"benchmarkId"`
+	Capital     float64           `json:"capital" binding:"min=0"`
+	Trials      int               `json:"trials"  binding:"min=0"`
 }
 
 //=============================================================================
@@ -25,6 +25,7 @@ THE SOFTWARE.
 package performance
 
 import (
+	"math"
 	"time"
 
 	"github.com/tradalia/core/datatype"
@@ -71,50 +72,47 @@ type General struct {
 //=============================================================================
 
 type Aggregates struct {
-	Annual *[]*AnnualAggregate `json:"annual"`
+	Annual    *[]*PeriodAggregate `json:"annual"`
+	Quarterly *[]*PeriodAggregate `json:"quarterly"`
+	Monthly   *[]*PeriodAggregate `json:"monthly"`
+	Weekly    *[]*PeriodAggregate `json:"weekly"`
+	YearMonth []*YearMonthReturns `json:"yearMonth"`
 }
 
 //=============================================================================
-
-type AnnualAggregate struct {
-	Year          int     `json:"year"`
-	GrossProfit   float64 `json:"grossProfit"`
-	GrossAvgTrade float64 `json:"grossAvgTrade"`
-	GrossWinPerc  float64 `json:"grossWinPerc"`
-	NetProfit     float64 `json:"netProfit"`
-	NetAvgTrade   float64 `json:"netAvgTrade"`
-	NetWinPerc    float64 `json:"netWinPerc"`
-	Trades        int     `json:"trades"`
+//--- Period is "2025" for years, "2025-Q1" for quarters, "2025-03" for months and
+//--- "2025-W09" for ISO weeks. Drawdowns are calculated inside the period
+
+type PeriodAggregate struct {
+	Period           string  `json:"period"`
+	Year             int     `json:"year"`
+	GrossProfit      float64 `json:"grossProfit"`
+	GrossAvgTrade    float64 `json:"grossAvgTrade"`
+	GrossWinPerc     float64 `json:"grossWinPerc"`
+	GrossMaxDrawdown float64 `json:"grossMaxDrawdown"`
+	NetProfit        float64 `json:"netProfit"`
+	NetAvgTrade      float64 `json:"netAvgTrade"`
+	NetWinPerc       float64 `json:"netWinPerc"`
+	NetMaxDrawdown   float64 `json:"netMaxDrawdown"`
+	Trades           int     `json:"trades"`
+
+	grossPeak float64
+	netPeak   float64
 }
 
 //-----------------------------------------------------------------------------
 
-func NewAggregate(tr *db.Trade, cost float64) *AnnualAggregate{
-	a := &AnnualAggregate{
-		Year         : tr.ExitDate.Year(),
-		GrossProfit  : tr.GrossProfit,
-		GrossAvgTrade: 0,
-		GrossWinPerc : 0,
-		NetProfit    : tr.GrossProfit - 2 * cost,
-		NetAvgTrade  : 0,
-		NetWinPerc   : 0,
-		Trades       : 1,
-	}
-
-	if a.GrossProfit > 0 {
-		a.GrossWinPerc = 1
-	}
-
-	if a.NetProfit > 0 {
-		a.NetWinPerc = 1
+func NewPeriodAggregate(period string, year int) *PeriodAggregate {
+	return &PeriodAggregate{
+		Period: period,
+		Year  : year,
 	}
-
-	return a
 }
 
 //-----------------------------------------------------------------------------
+//--- Trades must be added in exit date order to get the right drawdown
 
-func (a *AnnualAggregate) addTrade(tr *db.Trade, cost float64) {
+func (a *PeriodAggregate) addTrade(tr *db.Trade, cost float64) {
 	netProfit := tr.GrossProfit - 2 * cost
 
 	a.GrossProfit += tr.GrossProfit
@@ -128,15 +126,31 @@ func (a *AnnualAggregate) addTrade(tr *db.Trade, cost float64) {
 	if netProfit > 0 {
 		a.NetWinPerc++
 	}
+
+	a.grossPeak        = math.Max(a.grossPeak, a.GrossProfit)
+	a.netPeak          = math.Max(a.netPeak,   a.NetProfit)
+	a.GrossMaxDrawdown = math.Min(a.GrossMaxDrawdown, a.GrossProfit - a.grossPeak)
+	a.NetMaxDrawdown   = math.Min(a.NetMaxDrawdown,   a.NetProfit   - a.netPeak)
 }
 
 //-----------------------------------------------------------------------------
 
-func (a *AnnualAggregate) consolidate() {
-	a.GrossAvgTrade = core.Trunc2d(a.GrossProfit  / float64(a.Trades))
-	a.GrossWinPerc  = core.Trunc2d(a.GrossWinPerc / float64(a.Trades) * 100)
-	a.NetAvgTrade   = core.Trunc2d(a.NetProfit    / float64(a.Trades))
-	a.NetWinPerc    = core.Trunc2d(a.NetWinPerc   / float64(a.Trades) * 100)
+func (a *PeriodAggregate) consolidate() {
+	a.GrossAvgTrade    = core.Trunc2d(a.GrossProfit  / float64(a.Trades))
+	a.GrossWinPerc     = core.Trunc2d(a.GrossWinPerc / float64(a.Trades) * 100)
+	a.NetAvgTrade      = core.Trunc2d(a.NetProfit    / float64(a.Trades))
+	a.NetWinPerc       = core.Trunc2d(a.NetWinPerc   / float64(a.Trades) * 100)
+	a.GrossMaxDrawdown = core.Trunc2d(a.GrossMaxDrawdown)
+	a.NetMaxDrawdown   = core.Trunc2d(a.NetMaxDrawdown)
+}
+
+//=============================================================================
+//--- Net profit of each month of a year (factsheet style)
+
+type YearMonthReturns struct {
+	Year   int         `json:"year"`
+	Months [12]float64 `json:"months"`
+	Total  float64     `json:"total"`
 }
 
 //=============================================================================
@@ -192,27 +206,84 @@ type YoYRolling struct {
 
 //=============================================================================
 
+type HoldingBucket struct {
+	Label    string      `json:"label"`
+	MinHours float64     `json:"minHours"`
+	MaxHours float64     `json:"maxHours"`
+	Info     RollingInfo `json:"info"`
+}
+
+//=============================================================================
+//--- Session slots are built using the exchange timezone and are nil if the trading
+//--- system has no valid session config
+
 type Rolling struct {
-	Daily    [ 7]RollingInfo `json:"daily"`
-	Monthly  [12]RollingInfo `json:"monthly"`
-	DayYoY   []*YoYRolling   `json:"dayYoY"`
-	MonthYoY []*YoYRolling   `json:"monthYoY"`
+	Daily         [ 7]RollingInfo     `json:"daily"`
+	Monthly       [12]RollingInfo     `json:"monthly"`
+	DayYoY        []*YoYRolling       `json:"dayYoY"`
+	MonthYoY      []*YoYRolling       `json:"monthYoY"`
+	EntryHourly   [24]RollingInfo     `json:"entryHourly"`
+	ExitHourly    [24]RollingInfo     `json:"exitHourly"`
+	WeekdayHourly [ 7][24]RollingInfo `json:"weekdayHourly"`
+	SessionDaily  []RollingInfo       `json:"sessionDaily"`
+	SessionHourly []RollingInfo       `json:"sessionHourly"`
+	HoldingTime   []*HoldingBucket    `json:"holdingTime"`
 }
 
 //=============================================================================
 
 type AnalysisResponse struct {
-	General         General           `json:"general"`
-	TradingSystem   *db.TradingSystem `json:"tradingSystem"`
-	Gross           Performance       `json:"gross"`
-	Net             Performance       `json:"net"`
-	AllEquities     *Equities         `json:"allEquities"`
-	LongEquities    *Equities         `json:"longEquities"`
-	ShortEquities   *Equities         `json:"shortEquities"`
-	Trades          *[]db.Trade       `json:"trades"`
-	Aggregates      Aggregates        `json:"aggregates"`
-	Distributions   Distributions     `json:"distributions"`
-	Rolling         Rolling           `json:"rolling"`
+	General         General              `json:"general"`
+	TradingSystem   *db.TradingSystem    `json:"tradingSystem"`
+	Gross           Performance          `json:"gross"`
+	Net             Performance          `json:"net"`
+	AllEquities     *Equities            `json:"allEquities"`
+	LongEquities    *Equities            `json:"longEquities"`
+	ShortEquities   *Equities            `json:"shortEquities"`
+	Trades          *[]db.Trade          `json:"trades"`
+	Aggregates      Aggregates           `json:"aggregates"`
+	Distributions   Distributions        `json:"distributions"`
+	Rolling         Rolling              `json:"rolling"`
+	Benchmark       *BenchmarkComparison `json:"benchmark"`
+	Excursions      *Excursions          `json:"excursions"`
+	HoldingTimes    HoldingTimes         `json:"holdingTimes"`
+	Slippage        *Slippage            `json:"slippage"`
+	Labels          Labels               `json:"labels"`
+	Significance    *Significance        `json:"significance"`
+	Segments        *Segments            `json:"segments"`
+
+	systems map[uint]*db.TradingSystem
+}
+
+//-----------------------------------------------------------------------------
+
+func (res *AnalysisResponse) systemOf(tr *db.Trade) *db.TradingSystem {
+	ts, ok := res.systems[tr.TradingSystemId]
+	if ok {
+		return ts
+	}
+
+	return res.TradingSystem
+}
+
+//-----------------------------------------------------------------------------
+
+func (res *AnalysisResponse) costOf(tr *db.Trade) float64 {
+	return res.systemOf(tr).CostPerOperation
+}
+
+//-----------------------------------------------------------------------------
+
+func (res *AnalysisResponse) netProfits(trades *[]db.Trade, tradeType string) *[]float64 {
+	netSlice := []float64{}
+
+	for _, tr := range *trades {
+		if tradeType == db.TradeTypeAll || tr.TradeType == tradeType {
+			netSlice = append(netSlice, tr.GrossProfit - 2 * res.costOf(&tr))
+		}
+	}
+
+	return &netSlice
 }
 
 //=============================================================================
@@ -0,0 +1,164 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package performance
+
+import (
+	"math"
+
+	"github.com/tradalia/core/datatype"
+	"github.com/tradalia/portfolio-trader/pkg/core"
+	"github.com/tradalia/portfolio-trader/pkg/core/stats"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+)
+
+//=============================================================================
+//--- Number of trading days in a year, used to annualize daily values
+
+const AnnualDays = 256
+
+//=============================================================================
+
+type BenchmarkComparison struct {
+	BenchmarkId      uint    `json:"benchmarkId"`
+	BenchmarkCode    string  `json:"benchmarkCode"`
+	BenchmarkName    string  `json:"benchmarkName"`
+	Capital          float64 `json:"capital"`
+	Days             int     `json:"days"`
+	Correlation      float64 `json:"correlation"`
+	Beta             float64 `json:"beta"`
+	Alpha            float64 `json:"alpha"`
+	TrackingError    float64 `json:"trackingError"`
+	InformationRatio float64 `json:"informationRatio"`
+	UpCapture        float64 `json:"upCapture"`
+	DownCapture      float64 `json:"downCapture"`
+}
+
+//=============================================================================
+//--- Daily net profits (cost is the cost per operation) are converted into percentage
+//--- returns using the given capital, so that they can be compared with the benchmark
+//--- returns. Alpha and tracking error are annualized and expressed as a percentage
+
+func CompareWithBenchmark(returns *[]db.DailyReturn, cost float64, capital float64, b *db.Benchmark, benchReturns *[]db.BenchmarkReturn) *BenchmarkComparison {
+	bc := &BenchmarkComparison{
+		BenchmarkId  : b.Id,
+		BenchmarkCode: b.Code,
+		BenchmarkName: b.Name,
+		Capital      : capital,
+	}
+
+	sysRet, benRet := alignReturns(returns, cost, capital, benchReturns)
+	bc.Days = len(sysRet)
+
+	if bc.Days < 2 {
+		return bc
+	}
+
+	sysMean := stats.Mean(sysRet)
+	benMean := stats.Mean(benRet)
+	benVar  := stats.Covariance(benRet, benMean, benRet, benMean)
+
+	bc.Correlation = core.Trunc2d(stats.Correlation(sysRet, benRet))
+
+	beta := 0.0
+	if benVar != 0 {
+		beta = stats.Covariance(sysRet, sysMean, benRet, benMean) / benVar
+	}
+
+	bc.Beta  = core.Trunc2d(beta)
+	bc.Alpha = core.Trunc2d((sysMean - beta * benMean) * AnnualDays)
+
+	//--- Tracking error and information ratio on active returns
+
+	active := make([]float64, len(sysRet))
+	for i := range sysRet {
+		active[i] = sysRet[i] - benRet[i]
+	}
+
+	actMean := stats.Mean(active)
+	actStd  := stats.StdDev(active, actMean)
+
+	bc.TrackingError = core.Trunc2d(actStd * math.Sqrt(AnnualDays))
+
+	if actStd != 0 {
+		bc.InformationRatio = core.Trunc2d(actMean / actStd * math.Sqrt(AnnualDays))
+	}
+
+	bc.UpCapture   = core.Trunc2d(calcCapture(sysRet, benRet, true))
+	bc.DownCapture = core.Trunc2d(calcCapture(sysRet, benRet, false))
+
+	return bc
+}
+
+//=============================================================================
+//--- Only benchmark days inside the trading system's period are used. Days without
+//--- a daily return mean that the trading system was flat
+
+func alignReturns(returns *[]db.DailyReturn, cost float64, capital float64, benchReturns *[]db.BenchmarkReturn) ([]float64, []float64) {
+	var sysRet []float64
+	var benRet []float64
+
+	if len(*returns) == 0 {
+		return sysRet, benRet
+	}
+
+	profits := map[datatype.IntDate]float64{}
+	for _, dr := range *returns {
+		profits[dr.Day] += dr.GrossProfit - 2 * cost * float64(dr.Trades)
+	}
+
+	firstDay := (*returns)[0].Day
+	lastDay  := (*returns)[len(*returns) -1].Day
+
+	for _, br := range *benchReturns {
+		if br.Day >= firstDay && br.Day <= lastDay {
+			sysRet = append(sysRet, profits[br.Day] / capital * 100)
+			benRet = append(benRet, br.Value)
+		}
+	}
+
+	return sysRet, benRet
+}
+
+//=============================================================================
+
+func calcCapture(sysRet, benRet []float64, upside bool) float64 {
+	sysSum := 0.0
+	benSum := 0.0
+
+	for i, br := range benRet {
+		if (upside && br > 0) || (!upside && br < 0) {
+			sysSum += sysRet[i]
+			benSum += br
+		}
+	}
+
+	if benSum == 0 {
+		return 0
+	}
+
+	return sysSum / benSum * 100
+}
+
+//=============================================================================
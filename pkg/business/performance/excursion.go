@@ -0,0 +1,241 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package performance
+
+import (
+	"github.com/tradalia/portfolio-trader/pkg/core"
+	"github.com/tradalia/portfolio-trader/pkg/core/stats"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+)
+
+//=============================================================================
+
+type ExcursionPoint struct {
+	TradeType             string  `json:"tradeType"`
+	MaxAdverseExcursion   float64 `json:"maxAdverseExcursion"`
+	MaxFavorableExcursion float64 `json:"maxFavorableExcursion"`
+	GrossProfit           float64 `json:"grossProfit"`
+}
+
+//=============================================================================
+//--- Efficiencies are percentages of the range covered by the trade (MFE + MAE):
+//---  - entry: how close to the best price the trade entered  (MFE / range)
+//---  - exit : how close to the best price the trade exited   ((profit + MAE) / range)
+//---  - total: how much of the range has been captured        (profit / range)
+
+type Efficiency struct {
+	Entry Value `json:"entry"`
+	Exit  Value `json:"exit"`
+	Total Value `json:"total"`
+}
+
+//=============================================================================
+
+type Excursions struct {
+	Trades                int               `json:"trades"`
+	Points                []*ExcursionPoint `json:"points"`
+	AvgAdverseExcursion   Value             `json:"avgAdverseExcursion"`
+	AvgFavorableExcursion Value             `json:"avgFavorableExcursion"`
+	Efficiency            Efficiency        `json:"efficiency"`
+}
+
+//=============================================================================
+//--- Holding times are expressed in hours
+
+type HoldingTime struct {
+	Trades    int              `json:"trades"`
+	Mean      float64          `json:"mean"`
+	Median    float64          `json:"median"`
+	Min       float64          `json:"min"`
+	Max       float64          `json:"max"`
+	Histogram *stats.Histogram `json:"histogram"`
+}
+
+//=============================================================================
+
+type HoldingTimes struct {
+	All     *HoldingTime `json:"all"`
+	Long    *HoldingTime `json:"long"`
+	Short   *HoldingTime `json:"short"`
+	Winners *HoldingTime `json:"winners"`
+	Losers  *HoldingTime `json:"losers"`
+}
+
+//=============================================================================
+//===
+//=== Excursions
+//===
+//=============================================================================
+
+type excursionSum struct {
+	count  Value
+	mae    Value
+	mfe    Value
+	entry  Value
+	exit   Value
+	total  Value
+	ranges Value
+}
+
+//-----------------------------------------------------------------------------
+
+func calcExcursions(res *AnalysisResponse) {
+	exc := &Excursions{}
+	sum := excursionSum{}
+
+	for _, tr := range *res.Trades {
+		if !tr.HasExcursions() {
+			continue
+		}
+
+		mae := *tr.MaxAdverseExcursion
+		mfe := *tr.MaxFavorableExcursion
+
+		exc.Points = append(exc.Points, &ExcursionPoint{
+			TradeType            : tr.TradeType,
+			MaxAdverseExcursion  : mae,
+			MaxFavorableExcursion: mfe,
+			GrossProfit          : tr.GrossProfit,
+		})
+
+		addToValue(&sum.count, tr.TradeType, 1)
+		addToValue(&sum.mae,   tr.TradeType, mae)
+		addToValue(&sum.mfe,   tr.TradeType, mfe)
+
+		tradeRange := mae + mfe
+		if tradeRange > 0 {
+			addToValue(&sum.ranges, tr.TradeType, 1)
+			addToValue(&sum.entry,  tr.TradeType, mfe / tradeRange)
+			addToValue(&sum.exit,   tr.TradeType, (tr.GrossProfit + mae) / tradeRange)
+			addToValue(&sum.total,  tr.TradeType, tr.GrossProfit / tradeRange)
+		}
+	}
+
+	exc.Trades = int(sum.count.Total)
+
+	if exc.Trades == 0 {
+		return
+	}
+
+	exc.AvgAdverseExcursion   = divideValue(sum.mae,   sum.count,  1)
+	exc.AvgFavorableExcursion = divideValue(sum.mfe,   sum.count,  1)
+	exc.Efficiency.Entry      = divideValue(sum.entry, sum.ranges, 100)
+	exc.Efficiency.Exit       = divideValue(sum.exit,  sum.ranges, 100)
+	exc.Efficiency.Total      = divideValue(sum.total, sum.ranges, 100)
+
+	res.Excursions = exc
+}
+
+//=============================================================================
+
+func addToValue(v *Value, tradeType string, amount float64) {
+	v.Total += amount
+
+	if tradeType == db.TradeTypeLong {
+		v.Long += amount
+	} else {
+		v.Short += amount
+	}
+}
+
+//=============================================================================
+
+func divideValue(v Value, count Value, scale float64) Value {
+	return Value{
+		Total: calcRatio(v.Total, count.Total, scale),
+		Long : calcRatio(v.Long,  count.Long,  scale),
+		Short: calcRatio(v.Short, count.Short, scale),
+	}
+}
+
+//=============================================================================
+
+func calcRatio(value, count, scale float64) float64 {
+	if count == 0 {
+		return 0
+	}
+
+	return core.Trunc2d(value / count * scale)
+}
+
+//=============================================================================
+//===
+//=== Holding times
+//===
+//=============================================================================
+
+func calcHoldingTimes(res *AnalysisResponse) {
+	var all, long, short, winners, losers []float64
+
+	for _, tr := range *res.Trades {
+		hours := tr.ExitDate.Sub(*tr.EntryDate).Hours()
+
+		all = append(all, hours)
+
+		if tr.TradeType == db.TradeTypeLong {
+			long = append(long, hours)
+		} else {
+			short = append(short, hours)
+		}
+
+		if tr.GrossProfit - 2 * res.costOf(&tr) > 0 {
+			winners = append(winners, hours)
+		} else {
+			losers = append(losers, hours)
+		}
+	}
+
+	res.HoldingTimes.All     = calcHoldingTime(all)
+	res.HoldingTimes.Long    = calcHoldingTime(long)
+	res.HoldingTimes.Short   = calcHoldingTime(short)
+	res.HoldingTimes.Winners = calcHoldingTime(winners)
+	res.HoldingTimes.Losers  = calcHoldingTime(losers)
+}
+
+//=============================================================================
+
+func calcHoldingTime(data []float64) *HoldingTime {
+	if len(data) == 0 {
+		return nil
+	}
+
+	ht := &HoldingTime{
+		Trades: len(data),
+		Mean  : core.Trunc2d(stats.Mean(data)),
+		Median: core.Trunc2d(stats.Median(data)),
+		Min   : core.Trunc2d(stats.Min(data)),
+		Max   : core.Trunc2d(stats.Max(data)),
+	}
+
+	//--- The histogram's gaussian cannot be built when all values are the same
+
+	if ht.Min != ht.Max {
+		ht.Histogram = stats.NewHistogram(data)
+	}
+
+	return ht
+}
+
+//=============================================================================
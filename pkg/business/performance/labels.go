@@ -0,0 +1,163 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package performance
+
+import (
+	"sort"
+
+	"github.com/tradalia/portfolio-trader/pkg/core"
+)
+
+//=============================================================================
+//--- All values are net of costs
+
+type LabelAggregate struct {
+	Label       string  `json:"label"`
+	Trades      int     `json:"trades"`
+	NetProfit   float64 `json:"netProfit"`
+	NetAvgTrade float64 `json:"netAvgTrade"`
+	NetWinPerc  float64 `json:"netWinPerc"`
+	MaxDrawdown float64 `json:"maxDrawdown"`
+}
+
+//=============================================================================
+
+type LabelPairAggregate struct {
+	EntryLabel  string  `json:"entryLabel"`
+	ExitLabel   string  `json:"exitLabel"`
+	Trades      int     `json:"trades"`
+	NetProfit   float64 `json:"netProfit"`
+	NetAvgTrade float64 `json:"netAvgTrade"`
+	NetWinPerc  float64 `json:"netWinPerc"`
+	MaxDrawdown float64 `json:"maxDrawdown"`
+}
+
+//=============================================================================
+
+type Labels struct {
+	Entry  []*LabelAggregate     `json:"entry"`
+	Exit   []*LabelAggregate     `json:"exit"`
+	Matrix []*LabelPairAggregate `json:"matrix"`
+}
+
+//=============================================================================
+
+type labelPair struct {
+	entry string
+	exit  string
+}
+
+//=============================================================================
+
+func calcLabels(res *AnalysisResponse) {
+	entryMap := map[string][]float64{}
+	exitMap  := map[string][]float64{}
+	pairMap  := map[labelPair][]float64{}
+
+	for _, tr := range *res.Trades {
+		netProfit := tr.GrossProfit - 2 * res.costOf(&tr)
+		pair      := labelPair{ entry: tr.EntryLabel, exit: tr.ExitLabel }
+
+		entryMap[tr.EntryLabel] = append(entryMap[tr.EntryLabel], netProfit)
+		exitMap [tr.ExitLabel]  = append(exitMap [tr.ExitLabel],  netProfit)
+		pairMap [pair]          = append(pairMap [pair],          netProfit)
+	}
+
+	res.Labels.Entry  = buildLabelAggregates(entryMap)
+	res.Labels.Exit   = buildLabelAggregates(exitMap)
+	res.Labels.Matrix = []*LabelPairAggregate{}
+
+	for pair, profits := range pairMap {
+		la := newLabelAggregate("", profits)
+
+		res.Labels.Matrix = append(res.Labels.Matrix, &LabelPairAggregate{
+			EntryLabel : pair.entry,
+			ExitLabel  : pair.exit,
+			Trades     : la.Trades,
+			NetProfit  : la.NetProfit,
+			NetAvgTrade: la.NetAvgTrade,
+			NetWinPerc : la.NetWinPerc,
+			MaxDrawdown: la.MaxDrawdown,
+		})
+	}
+
+	sort.Slice(res.Labels.Matrix, func(i, j int) bool {
+		a := res.Labels.Matrix[i]
+		b := res.Labels.Matrix[j]
+
+		if a.EntryLabel != b.EntryLabel {
+			return a.EntryLabel < b.EntryLabel
+		}
+
+		return a.ExitLabel < b.ExitLabel
+	})
+}
+
+//=============================================================================
+
+func buildLabelAggregates(labelMap map[string][]float64) []*LabelAggregate {
+	list := []*LabelAggregate{}
+
+	for label, profits := range labelMap {
+		list = append(list, newLabelAggregate(label, profits))
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Label < list[j].Label
+	})
+
+	return list
+}
+
+//=============================================================================
+//--- Profits are in trade order, so that the drawdown can be calculated
+
+func newLabelAggregate(label string, profits []float64) *LabelAggregate {
+	la := &LabelAggregate{
+		Label : label,
+		Trades: len(profits),
+	}
+
+	winning := 0
+
+	for _, profit := range profits {
+		la.NetProfit += profit
+
+		if profit > 0 {
+			winning++
+		}
+	}
+
+	_, maxDD := core.BuildDrawDown(core.BuildEquity(&profits))
+
+	la.NetProfit   = core.Trunc2d(la.NetProfit)
+	la.NetAvgTrade = calcAvgTrade(la.NetProfit, la.Trades)
+	la.NetWinPerc  = core.Trunc2d(float64(winning) / float64(la.Trades) * 100)
+	la.MaxDrawdown = core.Trunc2d(maxDD)
+
+	return la
+}
+
+//=============================================================================
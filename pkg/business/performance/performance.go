@@ -25,6 +25,9 @@ THE SOFTWARE.
 package performance
 
 import (
+	"fmt"
+	"slices"
+	"sort"
 	"time"
 
 	"github.com/tradalia/core/datatype"
@@ -36,13 +39,30 @@ import (
 //=============================================================================
 
 func GetPerformanceAnalysis(ts *db.TradingSystem, trades *[]db.Trade, returns *[]db.DailyReturn) *AnalysisResponse {
+	res := runAnalysis(ts, nil, trades, returns)
+	calcSegments(res, returns)
+
+	return res
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+//--- Systems maps the trading system id of each trade to its system (costs and point
+//--- values can differ) and it is nil when all trades belong to ts
+
+func runAnalysis(ts *db.TradingSystem, systems map[uint]*db.TradingSystem, trades *[]db.Trade, returns *[]db.DailyReturn) *AnalysisResponse {
 	res := AnalysisResponse{}
 	res.TradingSystem = ts
 	res.Trades        = trades
+	res.systems       = systems
 
-	allEq  , allMaxGrossDD  , allMaxNetDD   := calcEquities(ts, trades, db.TradeTypeAll)
-	longEq , longMaxGrossDD , longMaxNetDD  := calcEquities(ts, trades, db.TradeTypeLong)
-	shortEq, shortMaxGrossDD, shortMaxNetDD := calcEquities(ts, trades, db.TradeTypeShort)
+	allEq  , allMaxGrossDD  , allMaxNetDD   := calcEquities(&res, trades, db.TradeTypeAll)
+	longEq , longMaxGrossDD , longMaxNetDD  := calcEquities(&res, trades, db.TradeTypeLong)
+	shortEq, shortMaxGrossDD, shortMaxNetDD := calcEquities(&res, trades, db.TradeTypeShort)
 
 	res.AllEquities   = allEq
 	res.LongEquities  = longEq
@@ -73,19 +93,19 @@ func GetPerformanceAnalysis(ts *db.TradingSystem, trades *[]db.Trade, returns *[
 	updateGeneralInfo(&res)
 	calcDistributions(&res, returns)
 	calcRolling      (&res)
+	calcExcursions   (&res)
+	calcHoldingTimes (&res)
+	calcSlippage     (&res)
+	calcLabels       (&res)
 
 	return &res
 }
 
-//=============================================================================
-//===
-//=== Private functions
-//===
 //=============================================================================
 
-func calcEquities(ts *db.TradingSystem, trades *[]db.Trade, tradeType string) (*Equities, float64, float64) {
+func calcEquities(res *AnalysisResponse, trades *[]db.Trade, tradeType string) (*Equities, float64, float64) {
 	timeSlice, grossProfits := core.BuildGrossProfits(trades, tradeType)
-	netProfits              := core.BuildNetProfits(grossProfits, ts.CostPerOperation)
+	netProfits              := res.netProfits(trades, tradeType)
 
 	grossEquity := core.BuildEquity(grossProfits)
 	netEquity   := core.BuildEquity(netProfits)
@@ -128,42 +148,105 @@ func calcAvgTrade(value float64, count int) float64 {
 //=============================================================================
 
 func calcAggregates(res *AnalysisResponse) {
-	calcYearAggregates(res)
+	cost := res.costOf
+
+	//--- Trades are sorted by entry date but aggregates use the exit date
+
+	trades := slices.Clone(*res.Trades)
+	sort.SliceStable(trades, func(i, j int) bool {
+		return trades[i].ExitDate.Before(*trades[j].ExitDate)
+	})
+
+	res.Aggregates.Annual    = calcPeriodAggregates(trades, cost, yearPeriod)
+	res.Aggregates.Quarterly = calcPeriodAggregates(trades, cost, quarterPeriod)
+	res.Aggregates.Monthly   = calcPeriodAggregates(trades, cost, monthPeriod)
+	res.Aggregates.Weekly    = calcPeriodAggregates(trades, cost, weekPeriod)
+	res.Aggregates.YearMonth = calcYearMonthReturns(trades, cost)
 }
 
 //=============================================================================
 
-func calcYearAggregates(res *AnalysisResponse) {
-	cost := float64(res.TradingSystem.CostPerOperation)
-	list := []*AnnualAggregate{}
+func calcPeriodAggregates(trades []db.Trade, costOf func(tr *db.Trade) float64, periodOf func(t *time.Time) (string, int)) *[]*PeriodAggregate {
+	periodMap := map[string]*PeriodAggregate{}
 
-	var currYear *AnnualAggregate
+	for i := range trades {
+		tr := &trades[i]
+		period, year := periodOf(tr.ExitDate)
 
-	for _, tr := range *res.Trades {
-		if currYear == nil {
-			//--- Beginning of a new year
-
-			currYear = NewAggregate(&tr, cost)
-			list = append(list, currYear)
-		} else {
-			if currYear.Year == tr.ExitDate.Year() {
-				//--- Continue on the current year
-				currYear.addTrade(&tr, cost)
-			} else {
-				//--- Continue on the new year
-
-				currYear.consolidate()
-				currYear = NewAggregate(&tr, cost)
-				list = append(list, currYear)
-			}
+		pa, ok := periodMap[period]
+		if !ok {
+			pa = NewPeriodAggregate(period, year)
+			periodMap[period] = pa
+		}
+
+		pa.addTrade(tr, costOf(tr))
+	}
+
+	list := []*PeriodAggregate{}
+	for _, pa := range periodMap {
+		pa.consolidate()
+		list = append(list, pa)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Period < list[j].Period
+	})
+
+	return &list
+}
+
+//=============================================================================
+
+func yearPeriod(t *time.Time) (string, int) {
+	return fmt.Sprintf("%d", t.Year()), t.Year()
+}
+
+//=============================================================================
+
+func quarterPeriod(t *time.Time) (string, int) {
+	return fmt.Sprintf("%d-Q%d", t.Year(), (int(t.Month()) -1) / 3 +1), t.Year()
+}
+
+//=============================================================================
+
+func monthPeriod(t *time.Time) (string, int) {
+	return fmt.Sprintf("%d-%02d", t.Year(), int(t.Month())), t.Year()
+}
+
+//=============================================================================
+
+func weekPeriod(t *time.Time) (string, int) {
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week), year
+}
+
+//=============================================================================
+
+func calcYearMonthReturns(trades []db.Trade, costOf func(tr *db.Trade) float64) []*YearMonthReturns {
+	var list []*YearMonthReturns
+	var curr *YearMonthReturns
+
+	for _, tr := range trades {
+		year := tr.ExitDate.Year()
+
+		if curr == nil || curr.Year != year {
+			curr = &YearMonthReturns{ Year: year }
+			list = append(list, curr)
 		}
+
+		netProfit := tr.GrossProfit - 2 * costOf(&tr)
+		curr.Months[tr.ExitDate.Month() -1] += netProfit
+		curr.Total                          += netProfit
 	}
 
-	if currYear != nil {
-		currYear.consolidate()
+	for _, ymr := range list {
+		for i := range ymr.Months {
+			ymr.Months[i] = core.Trunc2d(ymr.Months[i])
+		}
+		ymr.Total = core.Trunc2d(ymr.Total)
 	}
 
-	res.Aggregates.Annual = &list
+	return list
 }
 
 //=============================================================================
@@ -202,13 +285,15 @@ func calcDistributions(res *AnalysisResponse, returns *[]db.DailyReturn) {
 	list := core.ToNonZeroDailyReturnSlice(returns)
 	dist.Daily = calcDistribution(list)
 
-	dist.AnnualSharpeRatio = core.Trunc2d(dist.Daily.SharpeRatio * 16)
-	dist.AnnualStandardDev = core.Trunc2d(dist.Daily.StandardDev * 16)
+	if dist.Daily != nil {
+		dist.AnnualSharpeRatio = core.Trunc2d(dist.Daily.SharpeRatio * 16)
+		dist.AnnualStandardDev = core.Trunc2d(dist.Daily.StandardDev * 16)
+	}
 
 	//--- All (gross + net)
 
 	_, allGross := core.BuildGrossProfits(res.Trades, db.TradeTypeAll)
-	allNet      := core.BuildNetProfits(allGross, res.TradingSystem.CostPerOperation)
+	allNet      := res.netProfits(res.Trades, db.TradeTypeAll)
 
 	dist.TradesAllGross = calcDistribution(*allGross)
 	dist.TradesAllNet   = calcDistribution(*allNet)
@@ -216,7 +301,7 @@ func calcDistributions(res *AnalysisResponse, returns *[]db.DailyReturn) {
 	//--- Long (gross + net)
 
 	_, longGross := core.BuildGrossProfits(res.Trades, db.TradeTypeLong)
-	longNet      := core.BuildNetProfits(longGross, res.TradingSystem.CostPerOperation)
+	longNet      := res.netProfits(res.Trades, db.TradeTypeLong)
 
 	dist.TradesLongGross = calcDistribution(*longGross)
 	dist.TradesLongNet   = calcDistribution(*longNet)
@@ -224,7 +309,7 @@ func calcDistributions(res *AnalysisResponse, returns *[]db.DailyReturn) {
 	//--- Short (gross + net)
 
 	_, shortGross := core.BuildGrossProfits(res.Trades, db.TradeTypeShort)
-	shortNet      := core.BuildNetProfits(shortGross, res.TradingSystem.CostPerOperation)
+	shortNet      := res.netProfits(res.Trades, db.TradeTypeShort)
 
 	dist.TradesShortGross = calcDistribution(*shortGross)
 	dist.TradesShortNet   = calcDistribution(*shortNet)
@@ -267,22 +352,81 @@ func calcDistribution(data []float64) *Distribution {
 //=============================================================================
 
 func calcRolling(res *AnalysisResponse) {
-	costPerOper := res.TradingSystem.CostPerOperation
+	session     := core.ParseSession(res.TradingSystem.SessionConfig)
+	exchLoc     := getExchangeLocation(res.TradingSystem)
+	holding     := newHoldingBuckets()
+
+	if session != nil {
+		res.Rolling.SessionDaily  = make([]RollingInfo,  7)
+		res.Rolling.SessionHourly = make([]RollingInfo, 24)
+	}
 
 	for _, tr := range *res.Trades {
+		costPerOper := res.costOf(&tr)
+
 		year := tr.EntryDate.Year()
 		dow  := int(tr.EntryDate.Weekday())
 		mon  := int(tr.EntryDate.Month()) -1
 
 		dowRI := &res.Rolling.Daily  [dow]
 		monRI := &res.Rolling.Monthly[mon]
+		enhRI := &res.Rolling.EntryHourly[tr.EntryDate.Hour()]
+		exhRI := &res.Rolling.ExitHourly [tr.ExitDate .Hour()]
+		wdhRI := &res.Rolling.WeekdayHourly[dow][tr.EntryDate.Hour()]
 
 		updateRollingInfo(&tr, dowRI, costPerOper)
 		updateRollingInfo(&tr, monRI, costPerOper)
+		updateRollingInfo(&tr, enhRI, costPerOper)
+		updateRollingInfo(&tr, exhRI, costPerOper)
+		updateRollingInfo(&tr, wdhRI, costPerOper)
+
+		if session != nil {
+			entry := tr.EntryDate.In(exchLoc)
+			updateRollingInfo(&tr, &res.Rolling.SessionDaily [session.Weekday(entry)], costPerOper)
+			updateRollingInfo(&tr, &res.Rolling.SessionHourly[session.Hour   (entry)], costPerOper)
+		}
+
+		hours := tr.ExitDate.Sub(*tr.EntryDate).Hours()
+		for _, hb := range holding {
+			if hours >= hb.MinHours && (hb.MaxHours == 0 || hours < hb.MaxHours) {
+				updateRollingInfo(&tr, &hb.Info, costPerOper)
+				break
+			}
+		}
 
 		res.Rolling.DayYoY   = updateYoY(res.Rolling.DayYoY,   year, &tr, dow, costPerOper,  7)
 		res.Rolling.MonthYoY = updateYoY(res.Rolling.MonthYoY, year, &tr, mon, costPerOper, 12)
 	}
+
+	res.Rolling.HoldingTime = holding
+}
+
+//=============================================================================
+//--- Session times are expressed in the exchange timezone but trades could have been
+//--- shifted to another one
+
+func getExchangeLocation(ts *db.TradingSystem) *time.Location {
+	loc, err := time.LoadLocation(ts.Timezone)
+	if err != nil || ts.Timezone == "" {
+		return time.UTC
+	}
+
+	return loc
+}
+
+//=============================================================================
+//--- The last bucket has no upper limit (MaxHours = 0)
+
+func newHoldingBuckets() []*HoldingBucket {
+	return []*HoldingBucket{
+		{ Label: "< 1h",  MinHours:   0, MaxHours:   1 },
+		{ Label: "1h-4h", MinHours:   1, MaxHours:   4 },
+		{ Label: "4h-8h", MinHours:   4, MaxHours:   8 },
+		{ Label: "8h-1d", MinHours:   8, MaxHours:  24 },
+		{ Label: "1d-3d", MinHours:  24, MaxHours:  72 },
+		{ Label: "3d-7d", MinHours:  72, MaxHours: 168 },
+		{ Label: "> 7d",  MinHours: 168, MaxHours:   0 },
+	}
 }
 
 //=============================================================================
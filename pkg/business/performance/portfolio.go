@@ -0,0 +1,331 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package performance
+
+import (
+	"math"
+	"sort"
+
+	"github.com/tradalia/core/datatype"
+	"github.com/tradalia/portfolio-trader/pkg/core"
+	"github.com/tradalia/portfolio-trader/pkg/core/stats"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+)
+
+//=============================================================================
+//--- ProfitShare is the percentage of the portfolio net profit. DrawdownShare is the
+//--- percentage of the portfolio max drawdown lost by the system in the same period
+
+type Contribution struct {
+	TradingSystemId uint    `json:"tradingSystemId"`
+	Name            string  `json:"name"`
+	Trades          int     `json:"trades"`
+	NetProfit       float64 `json:"netProfit"`
+	MaxDrawdown     float64 `json:"maxDrawdown"`
+	ProfitShare     float64 `json:"profitShare"`
+	DrawdownShare   float64 `json:"drawdownShare"`
+}
+
+//=============================================================================
+//--- DiversificationRatio is the sum of the systems' daily standard deviations over the
+//--- portfolio one. DrawdownRatio is the sum of the systems' max drawdowns over the
+//--- portfolio one. Both are 1 when there is no diversification
+
+type Diversification struct {
+	Systems              int     `json:"systems"`
+	DiversificationRatio float64 `json:"diversificationRatio"`
+	DrawdownRatio        float64 `json:"drawdownRatio"`
+	AverageCorrelation   float64 `json:"averageCorrelation"`
+}
+
+//=============================================================================
+
+type PortfolioAnalysisResponse struct {
+	AnalysisResponse
+	Portfolio       *db.Portfolio    `json:"portfolio"`
+	Contributions   []*Contribution  `json:"contributions"`
+	Diversification *Diversification `json:"diversification"`
+}
+
+//=============================================================================
+//--- Trades and daily returns of all systems are merged and analysed as a single system,
+//--- using the costs of each system
+
+func GetPortfolioAnalysis(p *db.Portfolio, systems []*db.TradingSystem, trades *[]db.Trade, returns *[]db.DailyReturn) *PortfolioAnalysisResponse {
+	sysMap := map[uint]*db.TradingSystem{}
+	for _, ts := range systems {
+		sysMap[ts.Id] = ts
+	}
+
+	sort.SliceStable(*trades, func(i, j int) bool {
+		return (*trades)[i].ExitDate.Before(*(*trades)[j].ExitDate)
+	})
+
+	pts := &db.TradingSystem{
+		Name    : p.Name,
+		Username: p.Username,
+		Timezone: "UTC",
+	}
+
+	res := &PortfolioAnalysisResponse{
+		AnalysisResponse: *runAnalysis(pts, sysMap, trades, MergeDailyReturns(returns)),
+		Portfolio       : p,
+	}
+
+	res.Contributions   = calcContributions(&res.AnalysisResponse, systems)
+	res.Diversification = calcDiversification(&res.AnalysisResponse, res.Contributions, returns, systems)
+	res.TradingSystem   = nil
+
+	return res
+}
+
+//=============================================================================
+//--- Sums the daily returns of several systems day by day
+
+func MergeDailyReturns(returns *[]db.DailyReturn) *[]db.DailyReturn {
+	dayMap := map[datatype.IntDate]*db.DailyReturn{}
+
+	for _, dr := range *returns {
+		merged, ok := dayMap[dr.Day]
+		if !ok {
+			merged = &db.DailyReturn{ Day: dr.Day }
+			dayMap[dr.Day] = merged
+		}
+
+		merged.GrossProfit += dr.GrossProfit
+		merged.Trades      += dr.Trades
+	}
+
+	list := []db.DailyReturn{}
+	for _, dr := range dayMap {
+		list = append(list, *dr)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Day < list[j].Day
+	})
+
+	return &list
+}
+
+//=============================================================================
+//--- Builds the daily net returns of each system over the union of all days. Days
+//--- without a return for a system are set to zero
+
+func AlignDailyNetReturns(systems []*db.TradingSystem, returns *[]db.DailyReturn) ([]datatype.IntDate, [][]float64) {
+	daySet := map[datatype.IntDate]bool{}
+	for _, dr := range *returns {
+		daySet[dr.Day] = true
+	}
+
+	var days []datatype.IntDate
+	for day := range daySet {
+		days = append(days, day)
+	}
+
+	sort.Slice(days, func(i, j int) bool {
+		return days[i] < days[j]
+	})
+
+	if len(days) == 0 {
+		return nil, nil
+	}
+
+	dayIndex := map[datatype.IntDate]int{}
+	for i, day := range days {
+		dayIndex[day] = i
+	}
+
+	sysIndex := map[uint]int{}
+	series   := make([][]float64, len(systems))
+
+	for i, ts := range systems {
+		sysIndex[ts.Id] = i
+		series[i]       = make([]float64, len(days))
+	}
+
+	for _, dr := range *returns {
+		i, ok := sysIndex[dr.TradingSystemId]
+		if ok {
+			cost := systems[i].CostPerOperation
+			series[i][dayIndex[dr.Day]] += dr.GrossProfit - 2 * cost * float64(dr.Trades)
+		}
+	}
+
+	return days, series
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func calcContributions(res *AnalysisResponse, systems []*db.TradingSystem) []*Contribution {
+	conMap  := map[uint]*Contribution{}
+	equMap  := map[uint][]float64{}
+	var list []*Contribution
+
+	for _, ts := range systems {
+		c := &Contribution{
+			TradingSystemId: ts.Id,
+			Name           : ts.Name,
+		}
+
+		conMap[ts.Id] = c
+		list = append(list, c)
+	}
+
+	//--- The worst drawdown of the portfolio goes from the peak to the trough
+
+	peak, trough := findMaxDrawdownRange(res.AllEquities.NetEquity)
+	portfolioDD  := res.Net.MaxDrawdown.Total
+	ddLoss       := map[uint]float64{}
+
+	for i, tr := range *res.Trades {
+		c, ok := conMap[tr.TradingSystemId]
+		if !ok {
+			continue
+		}
+
+		netProfit := tr.GrossProfit - 2 * res.costOf(&tr)
+		c.Trades++
+		c.NetProfit += netProfit
+		equMap[tr.TradingSystemId] = append(equMap[tr.TradingSystemId], c.NetProfit)
+
+		if i > peak && i <= trough {
+			ddLoss[tr.TradingSystemId] += netProfit
+		}
+	}
+
+	totProfit := res.Net.Profit.Total
+
+	for _, c := range list {
+		equity := equMap[c.TradingSystemId]
+		if len(equity) > 0 {
+			_, c.MaxDrawdown = core.BuildDrawDown(&equity)
+		}
+
+		if totProfit != 0 {
+			c.ProfitShare = core.Trunc2d(c.NetProfit / totProfit * 100)
+		}
+
+		if portfolioDD != 0 {
+			c.DrawdownShare = core.Trunc2d(ddLoss[c.TradingSystemId] / portfolioDD * 100)
+		}
+
+		c.NetProfit   = core.Trunc2d(c.NetProfit)
+		c.MaxDrawdown = core.Trunc2d(c.MaxDrawdown)
+	}
+
+	return list
+}
+
+//=============================================================================
+//--- Returns the indexes of the peak and of the trough of the max drawdown. The peak
+//--- is -1 when the drawdown starts from zero
+
+func findMaxDrawdownRange(equity *[]float64) (int, int) {
+	maxProfit := 0.0
+	maxIndex  := -1
+	maxDD     := 0.0
+	peak      := -1
+	trough    := -1
+
+	for i, value := range *equity {
+		if value >= maxProfit {
+			maxProfit = value
+			maxIndex  = i
+		} else if value - maxProfit < maxDD {
+			maxDD  = value - maxProfit
+			peak   = maxIndex
+			trough = i
+		}
+	}
+
+	return peak, trough
+}
+
+//=============================================================================
+
+func calcDiversification(res *AnalysisResponse, contributions []*Contribution, returns *[]db.DailyReturn, systems []*db.TradingSystem) *Diversification {
+	div := &Diversification{
+		Systems: len(systems),
+	}
+
+	_, series := AlignDailyNetReturns(systems, returns)
+	if len(series) == 0 {
+		return div
+	}
+
+	days      := len(series[0])
+	portfolio := make([]float64, days)
+	sumStdDev := 0.0
+
+	for _, s := range series {
+		for d, value := range s {
+			portfolio[d] += value
+		}
+
+		sumStdDev += stats.StdDev(s, stats.Mean(s))
+	}
+
+	portStdDev := stats.StdDev(portfolio, stats.Mean(portfolio))
+	if portStdDev != 0 && !math.IsNaN(portStdDev) {
+		div.DiversificationRatio = core.Trunc2d(sumStdDev / portStdDev)
+	}
+
+	//--- Drawdown ratio
+
+	sumDD := 0.0
+	for _, c := range contributions {
+		sumDD += c.MaxDrawdown
+	}
+
+	if res.Net.MaxDrawdown.Total != 0 {
+		div.DrawdownRatio = core.Trunc2d(sumDD / res.Net.MaxDrawdown.Total)
+	}
+
+	//--- Average pairwise correlation
+
+	pairs := 0
+	sum   := 0.0
+
+	for i := 0; i < len(series); i++ {
+		for j := i + 1; j < len(series); j++ {
+			sum += stats.Correlation(series[i], series[j])
+			pairs++
+		}
+	}
+
+	if pairs > 0 {
+		div.AverageCorrelation = core.Trunc2d(sum / float64(pairs))
+	}
+
+	return div
+}
+
+//=============================================================================
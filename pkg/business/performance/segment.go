@@ -0,0 +1,231 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package performance
+
+import (
+	"time"
+
+	"github.com/tradalia/core/datatype"
+	"github.com/tradalia/portfolio-trader/pkg/consts"
+	"github.com/tradalia/portfolio-trader/pkg/core"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+)
+
+//=============================================================================
+
+const (
+	segmentInSample    = 0
+	segmentOutOfSample = 1
+	segmentLive        = 2
+)
+
+//=============================================================================
+
+type SegmentMetrics struct {
+	Trades            int     `json:"trades"`
+	NetProfit         float64 `json:"netProfit"`
+	NetAvgTrade       float64 `json:"netAvgTrade"`
+	NetWinPerc        float64 `json:"netWinPerc"`
+	SharpeRatio       float64 `json:"sharpeRatio"`
+	NetProfitPerMonth float64 `json:"netProfitPerMonth"`
+}
+
+//=============================================================================
+//--- Ratios between a segment's metrics and the in-sample ones (1 = no degradation)
+
+type Degradation struct {
+	NetAvgTrade       float64 `json:"netAvgTrade"`
+	NetWinPerc        float64 `json:"netWinPerc"`
+	SharpeRatio       float64 `json:"sharpeRatio"`
+	NetProfitPerMonth float64 `json:"netProfitPerMonth"`
+}
+
+//=============================================================================
+
+type Segment struct {
+	Metrics     SegmentMetrics    `json:"metrics"`
+	Degradation *Degradation      `json:"degradation"`
+	Analysis    *AnalysisResponse `json:"analysis"`
+}
+
+//=============================================================================
+
+type Segments struct {
+	InSampleFrom datatype.IntDate `json:"inSampleFrom"`
+	InSampleTo   datatype.IntDate `json:"inSampleTo"`
+	LiveFrom     *time.Time       `json:"liveFrom"`
+	InSample     *Segment         `json:"inSample"`
+	OutOfSample  *Segment         `json:"outOfSample"`
+	Live         *Segment         `json:"live"`
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+//--- Trades are split using their exit date: live trades are the ones closed after the
+//--- system was last set running, in-sample trades the ones closed inside the in-sample
+//--- period and all the others are out-of-sample
+
+func calcSegments(res *AnalysisResponse, returns *[]db.DailyReturn) {
+	ts := res.TradingSystem
+
+	if !hasInSample(ts) && ts.RunningSince == nil {
+		return
+	}
+
+	var isTrades, oosTrades, liveTrades    []db.Trade
+	var isReturns, oosReturns, liveReturns []db.DailyReturn
+
+	for _, tr := range *res.Trades {
+		switch getSegment(ts, tr.ExitDate) {
+			case segmentInSample: isTrades   = append(isTrades,   tr)
+			case segmentLive    : liveTrades = append(liveTrades, tr)
+			default             : oosTrades  = append(oosTrades,  tr)
+		}
+	}
+
+	for _, dr := range *returns {
+		day := dr.Day.ToDateTime(true, time.UTC)
+
+		switch getSegment(ts, &day) {
+			case segmentInSample: isReturns   = append(isReturns,   dr)
+			case segmentLive    : liveReturns = append(liveReturns, dr)
+			default             : oosReturns  = append(oosReturns,  dr)
+		}
+	}
+
+	res.Segments = &Segments{
+		InSampleFrom: ts.InSampleFrom,
+		InSampleTo  : ts.InSampleTo,
+		LiveFrom    : ts.RunningSince,
+		InSample    : calcSegment(ts, res.systems, isTrades,   isReturns,   nil),
+	}
+
+	var isMetrics *SegmentMetrics
+	if res.Segments.InSample != nil {
+		isMetrics = &res.Segments.InSample.Metrics
+	}
+
+	res.Segments.OutOfSample = calcSegment(ts, res.systems, oosTrades,  oosReturns,  isMetrics)
+	res.Segments.Live        = calcSegment(ts, res.systems, liveTrades, liveReturns, isMetrics)
+}
+
+//=============================================================================
+
+func getSegment(ts *db.TradingSystem, t *time.Time) int {
+	if ts.RunningSince != nil && !t.Before(*ts.RunningSince) {
+		return segmentLive
+	}
+
+	if hasInSample(ts) {
+		day := NewIntDate(t)
+
+		if (ts.InSampleFrom.IsNil() || day >= ts.InSampleFrom) && (ts.InSampleTo.IsNil() || day <= ts.InSampleTo) {
+			return segmentInSample
+		}
+	}
+
+	return segmentOutOfSample
+}
+
+//=============================================================================
+
+func hasInSample(ts *db.TradingSystem) bool {
+	return !ts.InSampleFrom.IsNil() || !ts.InSampleTo.IsNil()
+}
+
+//=============================================================================
+//--- The segment's analysis does not repeat the trading system and its trades, which
+//--- are already in the main response
+
+func calcSegment(ts *db.TradingSystem, systems map[uint]*db.TradingSystem, trades []db.Trade, returns []db.DailyReturn, isMetrics *SegmentMetrics) *Segment {
+	if len(trades) == 0 {
+		return nil
+	}
+
+	analysis := runAnalysis(ts, systems, &trades, &returns)
+
+	s := &Segment{
+		Metrics : calcSegmentMetrics(analysis),
+		Analysis: analysis,
+	}
+
+	analysis.TradingSystem = nil
+	analysis.Trades        = nil
+
+	if isMetrics != nil {
+		s.Degradation = &Degradation{
+			NetAvgTrade      : calcDegradation(s.Metrics.NetAvgTrade,       isMetrics.NetAvgTrade),
+			NetWinPerc       : calcDegradation(s.Metrics.NetWinPerc,        isMetrics.NetWinPerc),
+			SharpeRatio      : calcDegradation(s.Metrics.SharpeRatio,       isMetrics.SharpeRatio),
+			NetProfitPerMonth: calcDegradation(s.Metrics.NetProfitPerMonth, isMetrics.NetProfitPerMonth),
+		}
+	}
+
+	return s
+}
+
+//=============================================================================
+
+func calcSegmentMetrics(res *AnalysisResponse) SegmentMetrics {
+	trades   := *res.Trades
+	winCount := 0
+
+	for _, tr := range trades {
+		if tr.GrossProfit - 2 * res.costOf(&tr) > 0 {
+			winCount++
+		}
+	}
+
+	first := trades[0].EntryDate
+	last  := trades[len(trades) -1].ExitDate
+	days  := last.Sub(*first).Hours() / 24
+	if days < 1 {
+		days = 1
+	}
+
+	return SegmentMetrics{
+		Trades           : len(trades),
+		NetProfit        : core.Trunc2d(res.Net.Profit.Total),
+		NetAvgTrade      : res.Net.AverageTrade.Total,
+		NetWinPerc       : core.Trunc2d(float64(winCount) / float64(len(trades)) * 100),
+		SharpeRatio      : res.Distributions.AnnualSharpeRatio,
+		NetProfitPerMonth: core.Trunc2d(res.Net.Profit.Total / days * consts.DaysPerMonth),
+	}
+}
+
+//=============================================================================
+
+func calcDegradation(value, reference float64) float64 {
+	if reference == 0 {
+		return 0
+	}
+
+	return core.Trunc2d(value / reference)
+}
+
+//=============================================================================
@@ -0,0 +1,155 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package performance
+
+import (
+	"math"
+
+	"github.com/tradalia/portfolio-trader/pkg/core"
+	"github.com/tradalia/portfolio-trader/pkg/core/stats"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+)
+
+//=============================================================================
+
+const (
+	BootstrapSamples    = 2000
+	BootstrapConfidence = 0.95
+	BootstrapSeed       = 1
+)
+
+//=============================================================================
+
+type ConfidenceInterval struct {
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+}
+
+//=============================================================================
+//--- All tests are run on net trade returns. The Sharpe ratio is per trade (not annualized)
+//--- and probabilities are percentages
+
+type Significance struct {
+	Trades              int                `json:"trades"`
+	TStatistic          float64            `json:"tStatistic"`
+	PValue              float64            `json:"pValue"`
+	AvgTradeInterval    ConfidenceInterval `json:"avgTradeInterval"`
+	SharpeRatio         float64            `json:"sharpeRatio"`
+	SharpeInterval      ConfidenceInterval `json:"sharpeInterval"`
+	Skewness            float64            `json:"skewness"`
+	Kurtosis            float64            `json:"kurtosis"`
+	ProbabilisticSharpe float64            `json:"probabilisticSharpe"`
+	Trials              int                `json:"trials"`
+	ExpectedMaxSharpe   float64            `json:"expectedMaxSharpe"`
+	DeflatedSharpe      float64            `json:"deflatedSharpe"`
+}
+
+//=============================================================================
+//--- 'trials' is the number of configurations tried to get this one (i.e. the steps
+//--- of a filter optimization) and it is used to deflate the Sharpe ratio
+
+func CalcSignificance(res *AnalysisResponse, trials int) *Significance {
+	data := *res.netProfits(res.Trades, db.TradeTypeAll)
+
+	n := len(data)
+	if n < 3 {
+		return nil
+	}
+
+	if trials < 1 {
+		trials = 1
+	}
+
+	mean   := stats.Mean(data)
+	stdDev := stats.StdDev(data, mean)
+	if stdDev == 0 {
+		return nil
+	}
+
+	sr       := mean / stdDev
+	skewness := stats.MomentSkewness(data, mean, stdDev)
+	kurtosis := stats.Kurtosis(data, mean, stdDev)
+	tStat, p := stats.TTest(data)
+
+	avgLow, avgUpp := stats.Bootstrap(data, BootstrapSamples, BootstrapConfidence, BootstrapSeed, stats.Mean[float64])
+	srLow,  srUpp  := stats.Bootstrap(data, BootstrapSamples, BootstrapConfidence, BootstrapSeed, sharpeRatio)
+
+	//--- Variance of the Sharpe ratio estimator, used to get the expected max Sharpe over all trials
+
+	srVariance := (1 - skewness * sr + (kurtosis - 1) / 4 * sr * sr) / float64(n - 1)
+	maxSr      := stats.ExpectedMaxSharpeRatio(trials, srVariance)
+
+	return &Significance{
+		Trades             : n,
+		TStatistic         : core.Trunc2d(tStat),
+		PValue             : truncProbability(p),
+		AvgTradeInterval   : ConfidenceInterval{ Lower: core.Trunc2d(avgLow), Upper: core.Trunc2d(avgUpp) },
+		SharpeRatio        : truncProbability(sr),
+		SharpeInterval     : ConfidenceInterval{ Lower: truncProbability(srLow), Upper: truncProbability(srUpp) },
+		Skewness           : core.Trunc2d(skewness),
+		Kurtosis           : core.Trunc2d(kurtosis),
+		ProbabilisticSharpe: toPercentage(stats.ProbabilisticSharpeRatio(sr, 0, n, skewness, kurtosis)),
+		Trials             : trials,
+		ExpectedMaxSharpe  : truncProbability(maxSr),
+		DeflatedSharpe     : toPercentage(stats.ProbabilisticSharpeRatio(sr, maxSr, n, skewness, kurtosis)),
+	}
+}
+
+//=============================================================================
+
+func sharpeRatio(data []float64) float64 {
+	mean   := stats.Mean(data)
+	stdDev := stats.StdDev(data, mean)
+
+	if stdDev == 0 {
+		return 0
+	}
+
+	return mean / stdDev
+}
+
+//=============================================================================
+//--- Probabilities and per-trade Sharpe ratios are small numbers: keep 4 decimals.
+//--- NaN values cannot be serialized, so they are converted to 0
+
+func truncProbability(value float64) float64 {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0
+	}
+
+	return math.Floor(value * 10000) / 10000
+}
+
+//=============================================================================
+
+func toPercentage(value float64) float64 {
+	if math.IsNaN(value) {
+		return 0
+	}
+
+	return core.Trunc2d(value * 100)
+}
+
+//=============================================================================
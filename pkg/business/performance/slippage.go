@@ -0,0 +1,167 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package performance
+
+import (
+	"time"
+
+	"github.com/tradalia/portfolio-trader/pkg/core"
+	"github.com/tradalia/portfolio-trader/pkg/core/stats"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+)
+
+//=============================================================================
+//--- Slippage is positive when the broker price is worse than the strategy price.
+//--- Latency is the delay (in seconds) of the broker execution
+
+type SlippageInfo struct {
+	Trades        int              `json:"trades"`
+	AvgPoints     float64          `json:"avgPoints"`
+	AvgCurrency   float64          `json:"avgCurrency"`
+	TotalCurrency float64          `json:"totalCurrency"`
+	AvgLatency    float64          `json:"avgLatency"`
+	MaxLatency    float64          `json:"maxLatency"`
+	Histogram     *stats.Histogram `json:"histogram"`
+}
+
+//=============================================================================
+
+type SlippageEquities struct {
+	Time              []time.Time `json:"time"`
+	TheoreticalEquity []float64   `json:"theoreticalEquity"`
+	BrokerEquity      []float64   `json:"brokerEquity"`
+}
+
+//=============================================================================
+
+type Slippage struct {
+	Trades   int              `json:"trades"`
+	Entry    SlippageInfo     `json:"entry"`
+	Exit     SlippageInfo     `json:"exit"`
+	Equities SlippageEquities `json:"equities"`
+}
+
+//=============================================================================
+
+type slippageData struct {
+	points   []float64
+	currency []float64
+	latency  []float64
+}
+
+//-----------------------------------------------------------------------------
+
+func (sd *slippageData) add(points float64, currency float64, latency float64) {
+	sd.points   = append(sd.points,   points)
+	sd.currency = append(sd.currency, currency)
+	sd.latency  = append(sd.latency,  latency)
+}
+
+//=============================================================================
+//--- Only trades with both entry and exit broker information are used
+
+func calcSlippage(res *AnalysisResponse) {
+	slip := &Slippage{}
+
+	entry := slippageData{}
+	exit  := slippageData{}
+
+	theoEquity := 0.0
+	brokEquity := 0.0
+
+	for _, tr := range *res.Trades {
+		if tr.EntryDateAtBroker == nil || tr.ExitDateAtBroker == nil {
+			continue
+		}
+
+		ts   := res.systemOf(&tr)
+		cost := ts.CostPerOperation
+
+		entryPoints, exitPoints := calcSlippagePoints(&tr)
+		multiplier := ts.PointValue * float64(tr.Contracts)
+
+		entryCurr := entryPoints * multiplier
+		exitCurr  := exitPoints  * multiplier
+
+		entry.add(entryPoints, entryCurr, tr.EntryDateAtBroker.Sub(*tr.EntryDate).Seconds())
+		exit .add(exitPoints,  exitCurr,  tr.ExitDateAtBroker .Sub(*tr.ExitDate) .Seconds())
+
+		theoEquity += tr.GrossProfit - 2 * cost
+		brokEquity += tr.GrossProfit - entryCurr - exitCurr - 2 * cost
+
+		slip.Equities.Time              = append(slip.Equities.Time,              *tr.ExitDate)
+		slip.Equities.TheoreticalEquity = append(slip.Equities.TheoreticalEquity, core.Trunc2d(theoEquity))
+		slip.Equities.BrokerEquity      = append(slip.Equities.BrokerEquity,      core.Trunc2d(brokEquity))
+	}
+
+	slip.Trades = len(entry.points)
+
+	if slip.Trades == 0 {
+		return
+	}
+
+	slip.Entry = newSlippageInfo(&entry)
+	slip.Exit  = newSlippageInfo(&exit)
+
+	res.Slippage = slip
+}
+
+//=============================================================================
+
+func calcSlippagePoints(tr *db.Trade) (float64, float64) {
+	if tr.TradeType == db.TradeTypeLong {
+		return tr.EntryPriceAtBroker - tr.EntryPrice, tr.ExitPrice - tr.ExitPriceAtBroker
+	}
+
+	return tr.EntryPrice - tr.EntryPriceAtBroker, tr.ExitPriceAtBroker - tr.ExitPrice
+}
+
+//=============================================================================
+
+func newSlippageInfo(sd *slippageData) SlippageInfo {
+	si := SlippageInfo{
+		Trades     : len(sd.points),
+		AvgPoints  : core.Trunc2d(stats.Mean(sd.points)),
+		AvgCurrency: core.Trunc2d(stats.Mean(sd.currency)),
+		AvgLatency : core.Trunc2d(stats.Mean(sd.latency)),
+		MaxLatency : core.Trunc2d(stats.Max (sd.latency)),
+	}
+
+	for _, v := range sd.currency {
+		si.TotalCurrency += v
+	}
+
+	si.TotalCurrency = core.Trunc2d(si.TotalCurrency)
+
+	//--- The histogram's gaussian cannot be built when all values are the same
+
+	if stats.Min(sd.currency) != stats.Max(sd.currency) {
+		si.Histogram = stats.NewHistogram(sd.currency)
+	}
+
+	return si
+}
+
+//=============================================================================
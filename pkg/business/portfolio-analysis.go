@@ -0,0 +1,208 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package business
+
+import (
+	"github.com/tradalia/core/auth"
+	"github.com/tradalia/core/req"
+	"github.com/tradalia/portfolio-trader/pkg/business/performance"
+	"github.com/tradalia/portfolio-trader/pkg/business/report"
+	"github.com/tradalia/portfolio-trader/pkg/core"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+//--- The "exchange" timezone is meaningless for a portfolio, so UTC is used
+
+func RunPortfolioPerformanceAnalysis(tx *gorm.DB, c *auth.Context, portfolioId uint, req *performance.AnalysisRequest) (*performance.PortfolioAnalysisResponse, error) {
+
+	//--- Get portfolio and its trading systems
+
+	p, err := getPortfolioAndCheckAccess(tx, c, portfolioId)
+	if err != nil {
+		return nil, err
+	}
+
+	systems, err := getPortfolioTradingSystems(tx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return runPortfolioAnalysis(tx, c, p, systems, req)
+}
+
+//=============================================================================
+
+func GetPortfolioFactsheet(tx *gorm.DB, c *auth.Context, portfolioId uint, req *performance.AnalysisRequest) ([]byte, error) {
+	res, err := RunPortfolioPerformanceAnalysis(tx, c, portfolioId, req)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := report.NewPortfolioFactsheet(res)
+	if err != nil {
+		c.Log.Error("GetPortfolioFactsheet: Cannot build factsheet", "id", portfolioId, "error", err)
+		return nil, err
+	}
+
+	return report.Render(f)
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+//--- Analyses a group of trading systems as a portfolio. Used also for groups that
+//--- are not in the portfolio tree (e.g. smart groups)
+
+func runPortfolioAnalysis(tx *gorm.DB, c *auth.Context, p *db.Portfolio, systems []*db.TradingSystem, req *performance.AnalysisRequest) (*performance.PortfolioAnalysisResponse, error) {
+	pts := &db.TradingSystem{
+		Name    : p.Name,
+		Timezone: "UTC",
+	}
+
+	for _, ts := range systems {
+		pts.MarginValue += ts.MarginValue
+	}
+
+	loc, err := core.GetLocation(req.Timezone, pts)
+	if err != nil {
+		c.Log.Error("RunPortfolioPerformanceAnalysis: Bad timezone", "timezone", req.Timezone, "error", err)
+		return nil, err
+	}
+
+	fromTime, toTime, err := calcPerformancePeriod(req.DaysBack, req.FromDate, req.ToDate, loc)
+	if err != nil {
+		c.Log.Error("RunPortfolioPerformanceAnalysis: Bad fromDate or toDate", "fromDate", req.FromDate, "toDate", req.ToDate, "error", err)
+		return nil, err
+	}
+
+	//--- Collect trades and daily returns of all systems
+
+	var trades  []db.Trade
+	var returns []db.DailyReturn
+
+	for _, ts := range systems {
+		list, err := db.FindTradesByTsIdFromTime(tx, ts.Id, fromTime, toTime)
+		if err != nil {
+			return nil, err
+		}
+		trades = append(trades, *list...)
+
+		drList, err := db.FindDailyReturnsByTsIdFromTime(tx, ts.Id, fromTime, toTime)
+		if err != nil {
+			return nil, err
+		}
+		returns = append(returns, *drList...)
+	}
+
+	shiftTradesTimezone(&trades, loc)
+
+	res := performance.GetPortfolioAnalysis(p, systems, &trades, &returns)
+	res.Significance = performance.CalcSignificance(&res.AnalysisResponse, req.Trials)
+
+	//--- Compare with benchmark (if requested)
+
+	if req.BenchmarkId != 0 {
+		res.Benchmark, err = compareWithBenchmark(tx, c, pts, performance.MergeDailyReturns(&returns), req)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+//=============================================================================
+
+func getPortfolioAndCheckAccess(tx *gorm.DB, c *auth.Context, id uint) (*db.Portfolio, error) {
+	p, err := db.GetPortfolioById(tx, id)
+	if err != nil {
+		c.Log.Error("getPortfolio: Cannot get the portfolio", "id", id, "error", err)
+		return nil, err
+	}
+
+	if p == nil {
+		return nil, req.NewNotFoundError("Portfolio was not found: %v", id)
+	}
+
+	if ! c.Session.IsAdmin() {
+		if p.Username != c.Session.Username {
+			return nil, req.NewForbiddenError("Portfolio not owned by user: %v", id)
+		}
+	}
+
+	return p, nil
+}
+
+//=============================================================================
+//--- Returns the trading systems of the portfolio and of all its children. Visited
+//--- portfolios are tracked to survive circular loops
+
+func getPortfolioTradingSystems(tx *gorm.DB, p *db.Portfolio) ([]*db.TradingSystem, error) {
+	poList, err := db.GetPortfoliosByUser(tx, p.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	childMap := map[uint][]uint{}
+	for _, po := range *poList {
+		childMap[po.ParentId] = append(childMap[po.ParentId], po.Id)
+	}
+
+	visited := map[uint]bool{ p.Id: true }
+	queue   := []uint{ p.Id }
+	ids     := []uint{}
+
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		ids   = append(ids, id)
+
+		for _, childId := range childMap[id] {
+			if !visited[childId] {
+				visited[childId] = true
+				queue = append(queue, childId)
+			}
+		}
+	}
+
+	tsList, err := db.FindTradingSystemsByPortfolioIds(tx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*db.TradingSystem
+	for i := range *tsList {
+		list = append(list, &(*tsList)[i])
+	}
+
+	return list, nil
+}
+
+//=============================================================================
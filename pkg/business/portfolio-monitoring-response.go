@@ -24,7 +24,12 @@ THE SOFTWARE.
 
 package business
 
-import "time"
+import (
+	"time"
+
+	"github.com/tradalia/portfolio-trader/pkg/core"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+)
 
 //=============================================================================
 //===
@@ -32,9 +37,20 @@ import "time"
 //===
 //=============================================================================
 
+const (
+	MonitoringProfitGross = "gross"
+	MonitoringProfitNet   = "net"
+)
+
+//-----------------------------------------------------------------------------
+//--- Profit selects the gross or net series. If empty, both are returned. Systems can
+//--- be given as a list of ids or as a smart group
+
 type PortfolioMonitoringParams struct {
-	TsIds  []uint `form:"tsIds"  binding:"required,min=1,dive"`
-	Period    int `form:"period" binding:"required,min=1,max=5000"`
+	TsIds   []uint `form:"tsIds"   binding:"required_without=GroupId,dive"`
+	GroupId   uint `form:"groupId"`
+	Period     int `form:"period"  binding:"required,min=1,max=5000"`
+	Profit  string `form:"profit"  binding:"omitempty,oneof=gross net"`
 }
 
 //=============================================================================
@@ -47,6 +63,49 @@ type BaseMonitoring struct {
 	NetDrawdown   *[]float64   `json:"netDrawdown"`
 }
 
+//-----------------------------------------------------------------------------
+
+func (bm *BaseMonitoring) init(size int, params *PortfolioMonitoringParams) {
+	times := make([]time.Time, size)
+	bm.Time = &times
+
+	if params.Profit != MonitoringProfitNet {
+		gross := make([]float64, size)
+		bm.GrossProfit = &gross
+	}
+
+	if params.Profit != MonitoringProfitGross {
+		net := make([]float64, size)
+		bm.NetProfit = &net
+	}
+}
+
+//-----------------------------------------------------------------------------
+
+func (bm *BaseMonitoring) set(i int, t time.Time, grossProfit float64, netProfit float64) {
+	(*bm.Time)[i] = t
+
+	if bm.GrossProfit != nil {
+		(*bm.GrossProfit)[i] = grossProfit
+	}
+
+	if bm.NetProfit != nil {
+		(*bm.NetProfit)[i] = netProfit
+	}
+}
+
+//-----------------------------------------------------------------------------
+
+func (bm *BaseMonitoring) buildDrawdowns() {
+	if bm.GrossProfit != nil {
+		bm.GrossDrawdown,_ = core.BuildDrawDown(bm.GrossProfit)
+	}
+
+	if bm.NetProfit != nil {
+		bm.NetDrawdown,_ = core.BuildDrawDown(bm.NetProfit)
+	}
+}
+
 //=============================================================================
 
 type TradingSystemMonitoring struct {
@@ -57,13 +116,20 @@ type TradingSystemMonitoring struct {
 
 //=============================================================================
 
-func NewTradingSystemMonitoring(size int) *TradingSystemMonitoring {
-	tsa := &TradingSystemMonitoring{}
+func NewTradingSystemMonitoring(ts *db.TradingSystem, size int, params *PortfolioMonitoringParams) *TradingSystemMonitoring {
+	tsa := &TradingSystemMonitoring{
+		Id  : ts.Id,
+		Name: ts.Name,
+	}
+
+	tsa.init(size, params)
 
 	return tsa
 }
 
 //=============================================================================
+//--- Portfolio series are aligned on a daily calendar, summing the trades of the trading
+//--- systems by exit day. Trading system series have a point for each trade
 
 type PortfolioMonitoringResponse struct {
 	BaseMonitoring
@@ -25,17 +25,42 @@ THE SOFTWARE.
 package business
 
 import (
+	"sort"
+	"time"
+
+	"github.com/tradalia/core/auth"
+	"github.com/tradalia/core/datatype"
 	"github.com/tradalia/core/req"
-	"github.com/tradalia/portfolio-trader/pkg/core"
 	"github.com/tradalia/portfolio-trader/pkg/db"
 	"gorm.io/gorm"
-	"sort"
-	"time"
 )
 
 //=============================================================================
 
-func GetPortfolioMonitoring(tx *gorm.DB, params *PortfolioMonitoringParams) (*PortfolioMonitoringResponse, error) {
+func GetPortfolioMonitoring(tx *gorm.DB, c *auth.Context, params *PortfolioMonitoringParams) (*PortfolioMonitoringResponse, error) {
+
+	//--- Resolve the smart group (if any)
+
+	if params.GroupId != 0 {
+		sg, err := getSmartGroupAndCheckAccess(tx, c, params.GroupId)
+		if err != nil {
+			return nil, err
+		}
+
+		systems, err := getSmartGroupTradingSystems(tx, sg)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(systems) == 0 {
+			return nil, req.NewBadRequestError("Smart group has no trading systems: %v", sg.Id)
+		}
+
+		params.TsIds = []uint{}
+		for _, ts := range systems {
+			params.TsIds = append(params.TsIds, ts.Id)
+		}
+	}
 
 	//--- Get list of trading systems and check length
 
@@ -49,7 +74,7 @@ func GetPortfolioMonitoring(tx *gorm.DB, params *PortfolioMonitoringParams) (*Po
 		return nil, req.NewNotFoundError("Missing some trading systems (input:%v. found:%v)", len(params.TsIds), len(tsMap))
 	}
 
-	//--- Get trading systems daily data
+	//--- Get trading systems trades
 
 	fromTime := calcFromTime(params.Period)
 	idsArray := calcIdsArrayFromSourceIds(tsMap)
@@ -60,8 +85,8 @@ func GetPortfolioMonitoring(tx *gorm.DB, params *PortfolioMonitoringParams) (*Po
 	}
 
 	trMap := buildSortedMapOfInfo(trades)
-	res   := buildMonitoringResult(trMap, tsMap)
-	buildTotalInfo(res)
+	res   := buildMonitoringResult(trMap, tsMap, idsArray, params)
+	buildTotalInfo(res, trMap, tsMap, fromTime, params)
 
 	return res, nil
 }
@@ -87,6 +112,10 @@ func calcIdsArrayFromSourceIds(tsMap map[uint]*db.TradingSystem) []uint {
 		list = append(list, k)
 	}
 
+	sort.Slice(list, func(i, j int) bool {
+		return list[i] < list[j]
+	})
+
 	return list
 }
 
@@ -95,14 +124,9 @@ func calcIdsArrayFromSourceIds(tsMap map[uint]*db.TradingSystem) []uint {
 func buildSortedMapOfInfo(list *[]db.Trade) *map[uint][]*db.Trade {
 	trMap := map[uint][]*db.Trade{}
 
-	for _, trade := range *list {
-		trList, ok := trMap[trade.TradingSystemId]
-
-		if !ok {
-			trList = []*db.Trade{}
-		}
-
-		trMap[trade.TradingSystemId] = append(trList, &trade)
+	for i := range *list {
+		trade := &(*list)[i]
+		trMap[trade.TradingSystemId] = append(trMap[trade.TradingSystemId], trade)
 	}
 
 	for _, list := range trMap {
@@ -115,21 +139,14 @@ func buildSortedMapOfInfo(list *[]db.Trade) *map[uint][]*db.Trade {
 }
 
 //=============================================================================
+//--- Trading systems without trades in the period are returned with empty series
 
-func buildMonitoringResult(trMap *map[uint][]*db.Trade, tsMap map[uint]*db.TradingSystem) *PortfolioMonitoringResponse {
+func buildMonitoringResult(trMap *map[uint][]*db.Trade, tsMap map[uint]*db.TradingSystem, ids []uint, params *PortfolioMonitoringParams) *PortfolioMonitoringResponse {
 	res := &PortfolioMonitoringResponse{}
+	res.TradingSystems = make([]*TradingSystemMonitoring, len(ids))
 
-	if len(*trMap) != 0 {
-		res.TradingSystems = make([]*TradingSystemMonitoring, len(*trMap))
-	} else {
-		return res
-	}
-
-	i := 0
-	for key, list := range *trMap {
-		ts := tsMap[key]
-		res.TradingSystems[i] = buildTradingSystemMonitoring(ts, list)
-		i++
+	for i, id := range ids {
+		res.TradingSystems[i] = buildTradingSystemMonitoring(tsMap[id], (*trMap)[id], params)
 	}
 
 	return res
@@ -137,27 +154,22 @@ func buildMonitoringResult(trMap *map[uint][]*db.Trade, tsMap map[uint]*db.Tradi
 
 //=============================================================================
 
-func buildTradingSystemMonitoring(ts *db.TradingSystem, list []*db.Trade) *TradingSystemMonitoring {
-	tsa := NewTradingSystemMonitoring(len(list))
-	tsa.Id   = ts.Id
-	tsa.Name = ts.Name
+func buildTradingSystemMonitoring(ts *db.TradingSystem, list []*db.Trade, params *PortfolioMonitoringParams) *TradingSystemMonitoring {
+	tsa := NewTradingSystemMonitoring(ts, len(list), params)
 
 	currRawProfit := 0.0
 	currNetProfit := 0.0
 
 	//--- build data for a single trading system
 
-	for _, tr := range list {
+	for i, tr := range list {
 		currRawProfit += tr.GrossProfit
 		currNetProfit += tr.GrossProfit - float64(ts.CostPerOperation) * 2
 
-		//tsa.Time[i]        = *tr.ExitDate
-		//tsa.GrossProfit[i] = currRawProfit
-		//tsa.NetProfit[i]   = currNetProfit
+		tsa.set(i, *tr.ExitDate, currRawProfit, currNetProfit)
 	}
 
-	tsa.GrossDrawdown,_ = core.BuildDrawDown(tsa.GrossProfit)
-	tsa.NetDrawdown,  _ = core.BuildDrawDown(tsa.NetProfit)
+	tsa.buildDrawdowns()
 
 	return tsa
 }
@@ -170,56 +182,68 @@ type TotalInfo struct {
 }
 
 //-----------------------------------------------------------------------------
+//--- The portfolio equity has a point for each calendar day in the period, so that
+//--- days without trades carry the previous value. It is built from the same trades
+//--- of the trading systems, each one on the day of its exit (like daily returns), so
+//--- that it is the sum of the trading systems' equities
 
-func buildTotalInfo(pm *PortfolioMonitoringResponse) {
-	timeSum := map[time.Time]*TotalInfo{}
+func buildTotalInfo(pm *PortfolioMonitoringResponse, trMap *map[uint][]*db.Trade, tsMap map[uint]*db.TradingSystem, fromTime time.Time, params *PortfolioMonitoringParams) {
+	daySum := map[datatype.IntDate]*TotalInfo{}
 
 	//--- Collect all days with associated sums
 
-	for _, tsm := range (*pm).TradingSystems {
-		for i, t := range *tsm.Time {
-			ds, ok := timeSum[t]
+	for id, list := range *trMap {
+		ts    := tsMap[id]
+		dayOf := getDayFunction(ts)
+
+		for _, tr := range list {
+			day := dayOf(tr.ExitDate)
+			ds, ok := daySum[day]
 
 			if !ok {
 				ds = &TotalInfo{}
-				timeSum[t] = ds
+				daySum[day] = ds
 			}
 
-			ds.grossProfit += (*tsm.GrossProfit)[i]
-			ds.netProfit   += (*tsm.NetProfit)[i]
+			ds.grossProfit += tr.GrossProfit
+			ds.netProfit   += tr.GrossProfit - float64(ts.CostPerOperation) * 2
 		}
 	}
 
-	//--- Convert map into list and sort it
+	//--- Loop on all days and build total arrays. Days are in the exchange timezone, so
+	//--- the first one can be before the start of the period
 
-	var res []time.Time
+	fromDay := datatype.ToIntDate(&fromTime)
+	toDay   := datatype.Today(time.UTC)
 
-	for k, _ := range timeSum {
-		res = append(res, k)
+	for day := range daySum {
+		fromDay = min(fromDay, day)
+		toDay   = max(toDay,   day)
 	}
 
-	sort.SliceStable(res, func(i, j int) bool {
-		return res[i].Before(res[j])
-	})
+	size := 0
+	for day := fromDay; day <= toDay; day = day.AddDays(1) {
+		size++
+	}
 
-	//pm.Time = res
+	pm.init(size, params)
 
-	//--- Loop on all days and build total arrays
+	currRawProfit := 0.0
+	currNetProfit := 0.0
 
-	//pm.GrossProfit   = make([]float64, len(res))
-	//pm.NetProfit     = make([]float64, len(res))
-	//pm.GrossDrawdown = make([]float64, len(res))
-	//pm.NetDrawdown   = make([]float64, len(res))
+	i := 0
+	for day := fromDay; day <= toDay; day = day.AddDays(1) {
+		ds, ok := daySum[day]
+		if ok {
+			currRawProfit += ds.grossProfit
+			currNetProfit += ds.netProfit
+		}
 
-	//for i, day := range res {
-		//ds := timeSum[day]
-		//
-		//pm.GrossProfit[i] = ds.grossProfit
-		//pm.NetProfit[i]   = ds.netProfit
-	//}
+		pm.set(i, day.ToDateTime(false, time.UTC), currRawProfit, currNetProfit)
+		i++
+	}
 
-	pm.GrossDrawdown,_ = core.BuildDrawDown(pm.GrossProfit)
-	pm.NetDrawdown,  _ = core.BuildDrawDown(pm.NetProfit)
+	pm.buildDrawdowns()
 }
 
 //=============================================================================
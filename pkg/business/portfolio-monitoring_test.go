@@ -0,0 +1,80 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package business
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tradalia/portfolio-trader/pkg/db"
+)
+
+//=============================================================================
+//--- The portfolio equity must end with the sum of the trading systems' equities
+
+func TestBuildTotalInfo(t *testing.T) {
+	tsMap := map[uint]*db.TradingSystem{
+		1: { Id: 1, CostPerOperation: 2, Timezone: "America/Chicago" },
+		2: { Id: 2, CostPerOperation: 5 },
+	}
+
+	now    := time.Now().UTC()
+	params := &PortfolioMonitoringParams{ Period: 10 }
+
+	trades := &[]db.Trade{
+		*newTrade(addMinutes(&now, -5*24*60), 100, 1, ""),
+		*newTrade(addMinutes(&now, -3*24*60), -40, 1, ""),
+		*newTrade(addMinutes(&now, -9*24*60 -60), 70, 1, ""),
+	}
+
+	(*trades)[0].TradingSystemId = 1
+	(*trades)[1].TradingSystemId = 2
+	(*trades)[2].TradingSystemId = 1
+
+	trMap := buildSortedMapOfInfo(trades)
+	res   := buildMonitoringResult(trMap, tsMap, []uint{ 1, 2 }, params)
+	buildTotalInfo(res, trMap, tsMap, calcFromTime(params.Period), params)
+
+	gross, net := 0.0, 0.0
+	for _, tsm := range res.TradingSystems {
+		if n := len(*tsm.GrossProfit); n > 0 {
+			gross += (*tsm.GrossProfit)[n -1]
+			net   += (*tsm.NetProfit)[n -1]
+		}
+	}
+
+	last := len(*res.Time) -1
+
+	if (*res.GrossProfit)[last] != gross || (*res.NetProfit)[last] != net {
+		t.Errorf("Portfolio equity is not the sum of the systems. Expected %v/%v but got %v/%v", gross, net, (*res.GrossProfit)[last], (*res.NetProfit)[last])
+	}
+
+	if gross != 130 || net != 130 - 2*2*2 - 5*2 {
+		t.Errorf("Bad systems equity: %v/%v", gross, net)
+	}
+}
+
+//=============================================================================
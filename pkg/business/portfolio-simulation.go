@@ -0,0 +1,180 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package business
+
+import (
+	"time"
+
+	"github.com/tradalia/core/auth"
+	"github.com/tradalia/core/req"
+	"github.com/tradalia/portfolio-trader/pkg/business/filter"
+	"github.com/tradalia/portfolio-trader/pkg/business/simulation"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+type ContractMultiplier struct {
+	TradingSystemId uint    `json:"tradingSystemId"`
+	Multiplier      float64 `json:"multiplier" binding:"gte=0"`
+}
+
+//=============================================================================
+//--- AddTsIds and RemoveTsIds change the selection to try a hypothetical membership
+
+type SimulationRequest struct {
+	SystemSelection
+	AddTsIds    []uint               `json:"addTsIds"`
+	RemoveTsIds []uint               `json:"removeTsIds"`
+	Multipliers []ContractMultiplier `json:"multipliers" binding:"dive"`
+	DaysBack    int                  `json:"daysBack"    binding:"min=0,max=10000"`
+}
+
+//=============================================================================
+
+func SimulatePortfolio(tx *gorm.DB, c *auth.Context, sr *SimulationRequest) (*simulation.SimulationResponse, error) {
+	systems, added, err := getSimulatedTradingSystems(tx, c, sr)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []uint
+	for _, ts := range systems {
+		ids = append(ids, ts.Id)
+	}
+
+	filters, err := db.FindTradingFiltersByTsIds(tx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	filterMap := map[uint]*db.TradingFilter{}
+	for i, tf := range *filters {
+		filterMap[tf.TradingSystemId] = &(*filters)[i]
+	}
+
+	multMap := map[uint]float64{}
+	for _, cm := range sr.Multipliers {
+		multMap[cm.TradingSystemId] = cm.Multiplier
+	}
+
+	var inputs []*simulation.SystemInput
+
+	for _, ts := range systems {
+		//--- Filters need the whole history, the period is applied by the simulation
+
+		trades, err := db.FindTradesByTsIdFromTime(tx, ts.Id, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		//--- A system without filter is never switched off
+
+		tf, ok := filterMap[ts.Id]
+		if !ok {
+			tf = &db.TradingFilter{ TradingSystemId: ts.Id }
+		}
+
+		multiplier, ok := multMap[ts.Id]
+		if !ok {
+			multiplier = 1
+		}
+
+		inputs = append(inputs, &simulation.SystemInput{
+			TradingSystem: ts,
+			Analysis     : filter.RunAnalysis(ts, tf, trades),
+			Multiplier   : multiplier,
+			Added        : added[ts.Id],
+		})
+	}
+
+	var fromTime *time.Time
+	if sr.DaysBack > 0 {
+		from := time.Now().UTC().AddDate(0, 0, -sr.DaysBack)
+		fromTime = &from
+	}
+
+	return simulation.Simulate(inputs, fromTime), nil
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func getSimulatedTradingSystems(tx *gorm.DB, c *auth.Context, sr *SimulationRequest) ([]*db.TradingSystem, map[uint]bool, error) {
+	var systems []*db.TradingSystem
+	var err error
+
+	if sr.PortfolioId != 0 || sr.GroupId != 0 || len(sr.TsIds) > 0 {
+		systems, err = getSelectedTradingSystems(tx, c, &sr.SystemSelection)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	removed := map[uint]bool{}
+	for _, id := range sr.RemoveTsIds {
+		removed[id] = true
+	}
+
+	var list []*db.TradingSystem
+	idSet := map[uint]bool{}
+
+	for _, ts := range systems {
+		if !removed[ts.Id] {
+			list = append(list, ts)
+			idSet[ts.Id] = true
+		}
+	}
+
+	added := map[uint]bool{}
+
+	for _, id := range sr.AddTsIds {
+		if idSet[id] || removed[id] {
+			continue
+		}
+
+		ts, err := getTradingSystemAndCheckAccess(tx, c, id)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		list = append(list, ts)
+		idSet[id] = true
+		added[id] = true
+	}
+
+	if len(list) == 0 {
+		return nil, nil, req.NewBadRequestError("No trading systems to simulate")
+	}
+
+	return list, added, nil
+}
+
+//=============================================================================
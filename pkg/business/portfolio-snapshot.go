@@ -0,0 +1,196 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package business
+
+import (
+	"math"
+	"time"
+
+	"github.com/tradalia/core/auth"
+	"github.com/tradalia/core/datatype"
+	"github.com/tradalia/portfolio-trader/pkg/core"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+func GetPortfolioSnapshots(tx *gorm.DB, c *auth.Context, portfolioId uint, daysBack int, offset int, limit int) (*[]db.PortfolioSnapshot, error) {
+	p, err := getPortfolioAndCheckAccess(tx, c, portfolioId)
+	if err != nil {
+		return nil, err
+	}
+
+	fromDay := datatype.IntDate(0)
+	if daysBack > 0 {
+		fromDay = datatype.Today(time.UTC).AddDays(-daysBack)
+	}
+
+	return db.FindPortfolioSnapshots(tx, p.Id, fromDay, offset, limit)
+}
+
+//=============================================================================
+
+func TakePortfolioSnapshot(tx *gorm.DB, c *auth.Context, portfolioId uint) (*db.PortfolioSnapshot, error) {
+	c.Log.Info("TakePortfolioSnapshot: Recording portfolio snapshot", "id", portfolioId)
+
+	p, err := getPortfolioAndCheckAccess(tx, c, portfolioId)
+	if err != nil {
+		return nil, err
+	}
+
+	return takePortfolioSnapshot(tx, p)
+}
+
+//=============================================================================
+//--- Records today's snapshot of all portfolios of the user. A snapshot taken earlier
+//--- in the same day is overwritten
+
+func TakeUserPortfolioSnapshots(tx *gorm.DB, username string) (int, error) {
+	poList, err := db.GetPortfoliosByUser(tx, username)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range *poList {
+		_, err = takePortfolioSnapshot(tx, &(*poList)[i])
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(*poList), nil
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func takePortfolioSnapshot(tx *gorm.DB, p *db.Portfolio) (*db.PortfolioSnapshot, error) {
+	systems, err := getPortfolioTradingSystems(tx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	today := datatype.Today(time.UTC)
+
+	ps, err := db.GetPortfolioSnapshotByDay(tx, p.Id, today)
+	if err != nil {
+		return nil, err
+	}
+
+	if ps == nil {
+		ps = &db.PortfolioSnapshot{
+			PortfolioId: p.Id,
+			Username   : p.Username,
+			Day        : today,
+		}
+	}
+
+	now := time.Now()
+	ps.PortfolioName  = p.Name
+	ps.Timestamp      = &now
+	ps.TradingSystems = len(systems)
+	ps.RunningSystems = 0
+	ps.ActiveSystems  = 0
+	ps.MarginInUse    = 0
+	ps.OpenExposure   = 0
+
+	for _, ts := range systems {
+		if ts.Running {
+			ps.RunningSystems++
+
+			if ts.Active {
+				margin, err := calcMargin(tx, ts)
+				if err != nil {
+					return nil, err
+				}
+
+				ps.ActiveSystems++
+				ps.MarginInUse += margin
+			}
+		}
+
+		exposure, err := calcOpenExposure(tx, ts)
+		if err != nil {
+			return nil, err
+		}
+
+		ps.OpenExposure += exposure
+	}
+
+	equity, err := calcPortfolioEquity(tx, systems)
+	if err != nil {
+		return nil, err
+	}
+
+	_, maxDrawdown := core.BuildDrawDown(&equity)
+
+	ps.NetProfit    = core.Trunc2d(equity[len(equity) -1])
+	ps.Drawdown     = core.Trunc2d(-calcCurrentDrawdown(equity))
+	ps.MaxDrawdown  = core.Trunc2d(maxDrawdown)
+	ps.MarginInUse  = core.Trunc2d(ps.MarginInUse)
+	ps.OpenExposure = core.Trunc2d(ps.OpenExposure)
+
+	if ps.Id == 0 {
+		err = db.AddPortfolioSnapshot(tx, ps)
+	} else {
+		err = db.UpdatePortfolioSnapshot(tx, ps)
+	}
+
+	return ps, err
+}
+
+//=============================================================================
+//--- Executions not yet matched to a trade make the open position. Its notional value
+//--- uses the price of the last execution. Only the unmatched part of shared fills counts
+//--- (i.e. the entry of a stop and reverse), and orphaned executions are skipped
+
+func calcOpenExposure(tx *gorm.DB, ts *db.TradingSystem) (float64, error) {
+	list, err := db.FindUnmatchedExecutionsByTsId(tx, ts.Id)
+	if err != nil {
+		return 0, err
+	}
+
+	quantity := 0
+	price    := 0.0
+
+	for _, be := range *list {
+		if be.Side == db.ExecutionSideBuy {
+			quantity += be.Remaining()
+		} else {
+			quantity -= be.Remaining()
+		}
+
+		price = be.Price
+	}
+
+	return math.Abs(float64(quantity)) * price * ts.PointValue, nil
+}
+
+//=============================================================================
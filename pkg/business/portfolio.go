@@ -30,8 +30,27 @@ import (
 	"github.com/tradalia/portfolio-trader/pkg/db"
 	"gorm.io/gorm"
 	"log/slog"
+	"sort"
 )
 
+//=============================================================================
+//--- On update, a missing parent keeps the current one while 0 moves the portfolio to the root
+
+type PortfolioSpec struct {
+	Name     string `json:"name"     binding:"required,max=64"`
+	ParentId *uint  `json:"parentId"`
+}
+
+//-----------------------------------------------------------------------------
+
+func (s *PortfolioSpec) getParentId() uint {
+	if s.ParentId == nil {
+		return 0
+	}
+
+	return *s.ParentId
+}
+
 //=============================================================================
 
 func GetPortfolios(tx *gorm.DB, c *auth.Context, filter map[string]any, offset int, limit int) (*[]db.Portfolio, error) {
@@ -65,6 +84,190 @@ func GetPortfolioTree(tx *gorm.DB, c *auth.Context, filter map[string]any, offse
 	return buildPortfolioTree(c.Log, poList, tsList), nil
 }
 
+//=============================================================================
+
+func AddPortfolio(tx *gorm.DB, c *auth.Context, spec *PortfolioSpec) (*db.Portfolio, error) {
+	c.Log.Info("AddPortfolio: Creating new portfolio", "name", spec.Name, "parentId", spec.getParentId())
+
+	p := &db.Portfolio{
+		Username: c.Session.Username,
+		Name    : spec.Name,
+	}
+
+	if spec.getParentId() != 0 {
+		parent, err := getPortfolioAndCheckAccess(tx, c, *spec.ParentId)
+		if err != nil {
+			return nil, err
+		}
+
+		//--- An admin can add portfolios to other users' trees
+
+		p.ParentId = parent.Id
+		p.Username = parent.Username
+	}
+
+	err := db.AddPortfolio(tx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Log.Info("AddPortfolio: Portfolio created", "id", p.Id)
+	return p, nil
+}
+
+//=============================================================================
+
+func UpdatePortfolio(tx *gorm.DB, c *auth.Context, id uint, spec *PortfolioSpec) (*db.Portfolio, error) {
+	c.Log.Info("UpdatePortfolio: Updating portfolio", "id", id, "name", spec.Name, "parentId", spec.getParentId())
+
+	p, err := getPortfolioAndCheckAccess(tx, c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	parentId := p.ParentId
+	if spec.ParentId != nil {
+		parentId = *spec.ParentId
+	}
+
+	if parentId != p.ParentId && parentId != 0 {
+		parent, err := getPortfolioAndCheckAccess(tx, c, parentId)
+		if err != nil {
+			return nil, err
+		}
+
+		if parent.Username != p.Username {
+			return nil, req.NewBadRequestError("Parent portfolio belongs to another user: %v", parent.Id)
+		}
+
+		poList, err := db.GetPortfoliosByUser(tx, p.Username)
+		if err != nil {
+			return nil, err
+		}
+
+		parentMap := map[uint]uint{}
+		for _, po := range *poList {
+			parentMap[po.Id] = po.ParentId
+		}
+
+		if parent.Id == p.Id || isAncestor(parentMap, p.Id, parent.Id) {
+			return nil, req.NewBadRequestError("Portfolio cannot be moved under itself or one of its children: %v", parent.Id)
+		}
+	}
+
+	p.Name     = spec.Name
+	p.ParentId = parentId
+
+	err = db.UpdatePortfolio(tx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+//=============================================================================
+//--- Only empty portfolios can be deleted, to avoid losing the structure by mistake
+
+func DeletePortfolio(tx *gorm.DB, c *auth.Context, id uint) error {
+	c.Log.Info("DeletePortfolio: Deleting portfolio", "id", id)
+
+	p, err := getPortfolioAndCheckAccess(tx, c, id)
+	if err != nil {
+		return err
+	}
+
+	children, err := db.FindPortfoliosByParentId(tx, p.Id)
+	if err != nil {
+		return err
+	}
+
+	if len(*children) > 0 {
+		return req.NewBadRequestError("Portfolio has children portfolios: %v", p.Id)
+	}
+
+	tsList, err := db.FindTradingSystemsByPortfolioIds(tx, []uint{ p.Id })
+	if err != nil {
+		return err
+	}
+
+	if len(*tsList) > 0 {
+		return req.NewBadRequestError("Portfolio has trading systems: %v", p.Id)
+	}
+
+	err = db.DeleteRiskRulesByPortfolioId(tx, p.Id)
+	if err != nil {
+		return err
+	}
+
+	err = db.DeleteRiskEventsByPortfolioId(tx, p.Id)
+	if err != nil {
+		return err
+	}
+
+	//--- Snapshots are kept, as they are the history of the portfolio
+
+	return db.DeletePortfolio(tx, p.Id)
+}
+
+//=============================================================================
+
+func AssignTradingSystem(tx *gorm.DB, c *auth.Context, id uint, tsId uint) (*db.TradingSystem, error) {
+	c.Log.Info("AssignTradingSystem: Assigning trading system to portfolio", "id", id, "tsId", tsId)
+
+	p, err := getPortfolioAndCheckAccess(tx, c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ts, err := getTradingSystemAndCheckAccess(tx, c, tsId)
+	if err != nil {
+		return nil, err
+	}
+
+	if ts.Username != p.Username {
+		return nil, req.NewBadRequestError("Trading system and portfolio belong to different users: %v", tsId)
+	}
+
+	ts.PortfolioId = &p.Id
+
+	err = db.UpdateTradingSystem(tx, ts)
+	if err != nil {
+		return nil, req.NewServerErrorByError(err)
+	}
+
+	return ts, nil
+}
+
+//=============================================================================
+
+func UnassignTradingSystem(tx *gorm.DB, c *auth.Context, id uint, tsId uint) (*db.TradingSystem, error) {
+	c.Log.Info("UnassignTradingSystem: Removing trading system from portfolio", "id", id, "tsId", tsId)
+
+	p, err := getPortfolioAndCheckAccess(tx, c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ts, err := getTradingSystemAndCheckAccess(tx, c, tsId)
+	if err != nil {
+		return nil, err
+	}
+
+	if ts.PortfolioId == nil || *ts.PortfolioId != p.Id {
+		return nil, req.NewBadRequestError("Trading system is not in the portfolio: %v", tsId)
+	}
+
+	ts.PortfolioId = nil
+
+	err = db.UpdateTradingSystem(tx, ts)
+	if err != nil {
+		return nil, req.NewServerErrorByError(err)
+	}
+
+	return ts, nil
+}
+
 //=============================================================================
 //===
 //=== Private methods
@@ -75,8 +278,8 @@ func buildPortfolioTree(log *slog.Logger, poList *[]db.Portfolio, tsList *[]db.T
 
 	//--- Step 1: Collect all nodes into a map
 
-	nodeMap := map[uint]*PortfolioTree{}
 	fullMap := map[uint]*PortfolioTree{}
+	var ids []uint
 
 	for _, p := range *poList {
 		pt := &PortfolioTree{
@@ -84,41 +287,79 @@ func buildPortfolioTree(log *slog.Logger, poList *[]db.Portfolio, tsList *[]db.T
 			Children:       []*PortfolioTree{},
 			TradingSystems: []*db.TradingSystem{},
 		}
-		nodeMap[p.Id] = pt
 		fullMap[p.Id] = pt
+		ids = append(ids, p.Id)
 	}
 
-	//--- Step 2: Build the tree
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	//--- Step 2: Find parents. Missing parents make the node a root and circular loops
+	//--- are broken at the lowest id found in the loop
+
+	parentMap := map[uint]uint{}
 
-	for key, p := range fullMap {
-		if p.ParentId != 0 {
-			parent := fullMap[p.ParentId]
-			parent.AddChild(p)
-			delete(nodeMap, key)
+	for _, id := range ids {
+		parentId := fullMap[id].ParentId
+		if _, ok := fullMap[parentId]; ok && parentId != id {
+			parentMap[id] = parentId
 		}
 	}
 
-	//--- Step 2: Add trading system information
-
-	for _, ts := range *tsList {
-		aux := ts
-		portfolio := fullMap[*ts.PortfolioId]
-		portfolio.AddTradingSystem(&aux)
+	for _, id := range ids {
+		if isAncestor(parentMap, id, parentMap[id]) {
+			log.Error("buildPortfolioTree: Portfolios have circular loops (!)", "id", id)
+			delete(parentMap, id)
+		}
 	}
 
-	//--- Step 3: Return tree
+	//--- Step 3: Build the tree
 
-	if len(*poList) > 0 && len(nodeMap) == 0 {
-		log.Error("Portfolios have circular loops (!)")
+	var result []*PortfolioTree
+
+	for _, id := range ids {
+		parentId, ok := parentMap[id]
+		if ok {
+			fullMap[parentId].AddChild(fullMap[id])
+		} else {
+			result = append(result, fullMap[id])
+		}
 	}
 
-	var result []*PortfolioTree
+	//--- Step 4: Add trading system information
+
+	for _, ts := range *tsList {
+		if ts.PortfolioId == nil {
+			continue
+		}
 
-	for _, p := range nodeMap {
-		result = append(result, p)
+		portfolio, ok := fullMap[*ts.PortfolioId]
+		if ok {
+			aux := ts
+			portfolio.AddTradingSystem(&aux)
+		}
 	}
 
 	return &result
 }
 
 //=============================================================================
+//--- Tells if id is found walking up the parents, starting from parentId
+
+func isAncestor(parentMap map[uint]uint, id uint, parentId uint) bool {
+	visited := map[uint]bool{}
+
+	for parentId != 0 && !visited[parentId] {
+		if parentId == id {
+			return true
+		}
+
+		visited[parentId] = true
+		parentId = parentMap[parentId]
+	}
+
+	return false
+}
+
+//=============================================================================
@@ -0,0 +1,359 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package report
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/tradalia/portfolio-trader/pkg/business/performance"
+	"github.com/tradalia/portfolio-trader/pkg/core/stats"
+	"github.com/vicanso/go-charts/v2"
+	"github.com/wcharczuk/go-chart/v2/drawing"
+)
+
+//=============================================================================
+
+//go:embed factsheet.html
+var factsheetTemplate string
+
+var factsheetTmpl = template.Must(template.New("factsheet").Funcs(template.FuncMap{
+	"isNegative": func(s string) bool { return strings.HasPrefix(s, "-") },
+}).Parse(factsheetTemplate))
+
+//=============================================================================
+
+var months = []string{ "Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec" }
+
+//=============================================================================
+
+type Metric struct {
+	Label string
+	Value string
+}
+
+//=============================================================================
+
+type Chart struct {
+	Title string
+	Svg   template.HTML
+}
+
+//=============================================================================
+
+type Table struct {
+	Title  string
+	Header []string
+	Rows   [][]string
+}
+
+//=============================================================================
+
+type Factsheet struct {
+	Title       string
+	Subtitle    string
+	GeneratedAt string
+	Metrics     []Metric
+	Charts      []Chart
+	Tables      []Table
+}
+
+//=============================================================================
+
+func NewTradingSystemFactsheet(res *performance.AnalysisResponse) (*Factsheet, error) {
+	ts := res.TradingSystem
+
+	f := &Factsheet{
+		Title      : ts.Name,
+		Subtitle   : fmt.Sprintf("%s - %s %s - Period: %s / %s", ts.DataSymbol, ts.StrategyType, ts.MarketType, res.General.FromDate, res.General.ToDate),
+		GeneratedAt: time.Now().UTC().Format(time.DateTime) + " UTC",
+	}
+
+	f.AddPerformanceMetrics(res)
+
+	err := f.AddPerformanceCharts(res)
+	if err != nil {
+		return nil, err
+	}
+
+	f.AddPerformanceTables(res)
+
+	return f, nil
+}
+
+//=============================================================================
+
+func NewPortfolioFactsheet(res *performance.PortfolioAnalysisResponse) (*Factsheet, error) {
+	f := &Factsheet{
+		Title      : res.Portfolio.Name,
+		Subtitle   : fmt.Sprintf("Portfolio of %d trading systems - Period: %s / %s", len(res.Contributions), res.General.FromDate, res.General.ToDate),
+		GeneratedAt: time.Now().UTC().Format(time.DateTime) + " UTC",
+	}
+
+	f.AddPerformanceMetrics(&res.AnalysisResponse)
+
+	if res.Diversification != nil {
+		f.AddMetric("Diversification ratio", formatNumber(res.Diversification.DiversificationRatio))
+		f.AddMetric("Avg correlation",       formatNumber(res.Diversification.AverageCorrelation))
+	}
+
+	err := f.AddPerformanceCharts(&res.AnalysisResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	f.AddPerformanceTables(&res.AnalysisResponse)
+
+	contrib := Table{
+		Title : "Contributions",
+		Header: []string{ "Trading system", "Trades", "Net profit", "Profit %", "Max drawdown", "Drawdown %" },
+	}
+
+	for _, c := range res.Contributions {
+		contrib.Rows = append(contrib.Rows, []string{
+			c.Name,
+			fmt.Sprintf("%d", c.Trades),
+			formatNumber(c.NetProfit),
+			formatNumber(c.ProfitShare),
+			formatNumber(c.MaxDrawdown),
+			formatNumber(c.DrawdownShare),
+		})
+	}
+
+	f.Tables = append(f.Tables, contrib)
+
+	return f, nil
+}
+
+//=============================================================================
+
+func (f *Factsheet) AddMetric(label string, value string) {
+	f.Metrics = append(f.Metrics, Metric{ Label: label, Value: value })
+}
+
+//=============================================================================
+
+func (f *Factsheet) AddPerformanceMetrics(res *performance.AnalysisResponse) {
+	f.AddMetric("Net profit",       formatNumber(res.Net.Profit.Total))
+	f.AddMetric("Max drawdown",     formatNumber(res.Net.MaxDrawdown.Total))
+	f.AddMetric("Average trade",    formatNumber(res.Net.AverageTrade.Total))
+	f.AddMetric("Trades",           fmt.Sprintf("%d", res.AllEquities.Trades))
+	f.AddMetric("Sharpe (annual)",  formatNumber(res.Distributions.AnnualSharpeRatio))
+	f.AddMetric("Std dev (annual)", formatNumber(res.Distributions.AnnualStandardDev))
+
+	if res.Distributions.TradesAllNet != nil {
+		f.AddMetric("Trade skewness", formatNumber(res.Distributions.TradesAllNet.Skewness))
+	}
+
+	if res.Net.MaxDrawdown.Total != 0 {
+		f.AddMetric("Return / drawdown", formatNumber(-res.Net.Profit.Total / res.Net.MaxDrawdown.Total))
+	}
+}
+
+//=============================================================================
+
+func (f *Factsheet) AddPerformanceCharts(res *performance.AnalysisResponse) error {
+	eq := res.AllEquities
+	if eq == nil || eq.Trades == 0 {
+		return nil
+	}
+
+	var xAxis []string
+	for _, t := range *eq.Time {
+		xAxis = append(xAxis, t.Format(time.DateOnly))
+	}
+
+	err := f.AddLineChart("Equity (net)", xAxis, *eq.NetEquity)
+	if err == nil {
+		err = f.AddLineChart("Drawdown (net)", xAxis, *eq.NetDrawdown)
+		if err == nil {
+			err = f.AddHistogramChart("Trade distribution (net)", res.Distributions.TradesAllNet)
+			if err == nil {
+				err = f.AddHistogramChart("Daily distribution", res.Distributions.Daily)
+			}
+		}
+	}
+
+	return err
+}
+
+//=============================================================================
+
+func (f *Factsheet) AddPerformanceTables(res *performance.AnalysisResponse) {
+	annual := Table{
+		Title : "Annual performance",
+		Header: []string{ "Year", "Trades", "Net profit", "Avg trade", "Win %", "Max drawdown" },
+	}
+
+	for _, a := range *res.Aggregates.Annual {
+		annual.Rows = append(annual.Rows, []string{
+			a.Period,
+			fmt.Sprintf("%d", a.Trades),
+			formatNumber(a.NetProfit),
+			formatNumber(a.NetAvgTrade),
+			formatNumber(a.NetWinPerc),
+			formatNumber(a.NetMaxDrawdown),
+		})
+	}
+
+	monthly := Table{
+		Title : "Monthly returns (net)",
+		Header: append(append([]string{ "Year" }, months...), "Total"),
+	}
+
+	for _, ym := range res.Aggregates.YearMonth {
+		row := []string{ fmt.Sprintf("%d", ym.Year) }
+		for _, v := range ym.Months {
+			row = append(row, formatNumber(v))
+		}
+
+		monthly.Rows = append(monthly.Rows, append(row, formatNumber(ym.Total)))
+	}
+
+	f.Tables = append(f.Tables, annual, monthly)
+}
+
+//=============================================================================
+
+func (f *Factsheet) AddLineChart(title string, xAxis []string, values []float64) error {
+	p, err := charts.LineRender(
+		[][]float64{ values },
+		charts.SVGTypeOption(),
+		charts.XAxisDataOptionFunc(xAxis, charts.FalseFlag()),
+		func(opt *charts.ChartOption) {
+			opt.BackgroundColor   = drawing.ColorFromHex("FFFFFF")
+			opt.XAxis.SplitNumber = 6
+			opt.XAxis.FontSize    = 8
+			opt.SymbolShow        = charts.FalseFlag()
+			opt.LineStrokeWidth   = 1.5
+			opt.ValueFormatter    = func(f float64) string {
+				return fmt.Sprintf("%.0f", f)
+			}
+			opt.Width        = 520
+			opt.Height       = 260
+			opt.YAxisOptions = []charts.YAxisOption{ { FontSize: 8 }}
+			opt.Padding      = charts.Box{ Top: 8, Left: 4, Right: 12, Bottom: 0}
+		},
+	)
+
+	return f.addChart(title, p, err)
+}
+
+//=============================================================================
+
+func (f *Factsheet) AddHistogramChart(title string, d *performance.Distribution) error {
+	if d == nil || d.Histogram == nil || len(d.Histogram.Bars) == 0 {
+		return nil
+	}
+
+	p, err := charts.BarRender(
+		[][]float64{ toFloats(d.Histogram.Bars) },
+		charts.SVGTypeOption(),
+		charts.XAxisDataOptionFunc(histogramLabels(d.Histogram)),
+		func(opt *charts.ChartOption) {
+			opt.BackgroundColor = drawing.ColorFromHex("FFFFFF")
+			opt.XAxis.FontSize  = 7
+			opt.Width           = 520
+			opt.Height          = 260
+			opt.YAxisOptions    = []charts.YAxisOption{ { FontSize: 8 }}
+			opt.Padding         = charts.Box{ Top: 8, Left: 4, Right: 12, Bottom: 0}
+		},
+	)
+
+	return f.addChart(title, p, err)
+}
+
+//=============================================================================
+
+func Render(f *Factsheet) ([]byte, error) {
+	var buf bytes.Buffer
+
+	err := factsheetTmpl.Execute(&buf, f)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func (f *Factsheet) addChart(title string, p *charts.Painter, err error) error {
+	if err != nil {
+		return err
+	}
+
+	buf, err := p.Bytes()
+	if err != nil {
+		return err
+	}
+
+	f.Charts = append(f.Charts, Chart{
+		Title: title,
+		Svg  : template.HTML(buf),
+	})
+
+	return nil
+}
+
+//=============================================================================
+
+func histogramLabels(h *stats.Histogram) []string {
+	var labels []string
+
+	for _, r := range h.Ranges {
+		labels = append(labels, fmt.Sprintf("%.0f", (r.MinValue + r.MaxValue) / 2))
+	}
+
+	return labels
+}
+
+//=============================================================================
+
+func toFloats(values []int) []float64 {
+	var res []float64
+
+	for _, v := range values {
+		res = append(res, float64(v))
+	}
+
+	return res
+}
+
+//=============================================================================
+
+func formatNumber(value float64) string {
+	return fmt.Sprintf("%.2f", value)
+}
+
+//=============================================================================
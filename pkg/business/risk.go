@@ -0,0 +1,530 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package business
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/tradalia/core/auth"
+	"github.com/tradalia/core/datatype"
+	"github.com/tradalia/core/msg"
+	"github.com/tradalia/core/req"
+	"github.com/tradalia/portfolio-trader/pkg/business/performance"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+type RiskRuleSpec struct {
+	RuleType  string  `json:"ruleType"  binding:"required,oneof=dailyLoss drawdown activeSystems margin"`
+	Threshold float64 `json:"threshold" binding:"gt=0"`
+	Enabled   bool    `json:"enabled"`
+}
+
+//=============================================================================
+
+func GetRiskRules(tx *gorm.DB, c *auth.Context, portfolioId uint) (*[]db.RiskRule, error) {
+	_, err := getPortfolioAndCheckAccess(tx, c, portfolioId)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.FindRiskRulesByPortfolioId(tx, portfolioId)
+}
+
+//=============================================================================
+
+func AddRiskRule(tx *gorm.DB, c *auth.Context, portfolioId uint, spec *RiskRuleSpec) (*db.RiskRule, error) {
+	c.Log.Info("AddRiskRule: Creating new risk rule", "portfolioId", portfolioId, "ruleType", spec.RuleType, "threshold", spec.Threshold)
+
+	p, err := getPortfolioAndCheckAccess(tx, c, portfolioId)
+	if err != nil {
+		return nil, err
+	}
+
+	rr := &db.RiskRule{
+		PortfolioId: p.Id,
+		Username   : p.Username,
+		RuleType   : spec.RuleType,
+		Threshold  : spec.Threshold,
+		Enabled    : spec.Enabled,
+		ResetDay   : datatype.Today(time.UTC),
+	}
+
+	err = db.AddRiskRule(tx, rr)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Log.Info("AddRiskRule: Risk rule created", "id", rr.Id)
+	return rr, nil
+}
+
+//=============================================================================
+
+func UpdateRiskRule(tx *gorm.DB, c *auth.Context, portfolioId uint, id uint, spec *RiskRuleSpec) (*db.RiskRule, error) {
+	c.Log.Info("UpdateRiskRule: Updating risk rule", "portfolioId", portfolioId, "id", id)
+
+	rr, err := getRiskRuleAndCheckAccess(tx, c, portfolioId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rr.RuleType  = spec.RuleType
+	rr.Threshold = spec.Threshold
+	rr.Enabled   = spec.Enabled
+
+	err = db.UpdateRiskRule(tx, rr)
+	if err != nil {
+		return nil, err
+	}
+
+	return rr, nil
+}
+
+//=============================================================================
+//--- Daily loss and drawdown will be measured starting from tomorrow
+
+func ResetRiskRule(tx *gorm.DB, c *auth.Context, portfolioId uint, id uint) (*db.RiskRule, error) {
+	c.Log.Info("ResetRiskRule: Resetting risk rule", "portfolioId", portfolioId, "id", id)
+
+	rr, err := getRiskRuleAndCheckAccess(tx, c, portfolioId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rr.ResetDay = datatype.Today(time.UTC)
+
+	err = db.UpdateRiskRule(tx, rr)
+	if err != nil {
+		return nil, err
+	}
+
+	return rr, nil
+}
+
+//=============================================================================
+
+func DeleteRiskRule(tx *gorm.DB, c *auth.Context, portfolioId uint, id uint) error {
+	c.Log.Info("DeleteRiskRule: Deleting risk rule", "portfolioId", portfolioId, "id", id)
+
+	_, err := getRiskRuleAndCheckAccess(tx, c, portfolioId, id)
+	if err != nil {
+		return err
+	}
+
+	return db.DeleteRiskRule(tx, id)
+}
+
+//=============================================================================
+
+func GetRiskEvents(tx *gorm.DB, c *auth.Context, portfolioId uint, offset int, limit int) (*[]db.RiskEvent, error) {
+	_, err := getPortfolioAndCheckAccess(tx, c, portfolioId)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.FindRiskEventsByPortfolioId(tx, portfolioId, offset, limit)
+}
+
+//=============================================================================
+//--- Manual evaluation of the rules of a portfolio
+
+func CheckPortfolioRiskRules(tx *gorm.DB, c *auth.Context, portfolioId uint) ([]*db.RiskEvent, error) {
+	p, err := getPortfolioAndCheckAccess(tx, c, portfolioId)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := db.FindRiskRulesByPortfolioId(tx, p.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return evaluateRiskRules(tx, p, *rules)
+}
+
+//=============================================================================
+//--- Evaluates the rules of the portfolio of the trading system and of all its
+//--- parents, because rules apply to the whole subtree
+
+func CheckTradingSystemRiskRules(tx *gorm.DB, ts *db.TradingSystem) ([]*db.RiskEvent, error) {
+	if ts.PortfolioId == nil {
+		return nil, nil
+	}
+
+	poList, err := db.GetPortfoliosByUser(tx, ts.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	poMap := map[uint]*db.Portfolio{}
+	for i, p := range *poList {
+		poMap[p.Id] = &(*poList)[i]
+	}
+
+	var events []*db.RiskEvent
+	visited := map[uint]bool{}
+
+	for id := *ts.PortfolioId; id != 0 && !visited[id]; {
+		visited[id] = true
+
+		p, ok := poMap[id]
+		if !ok {
+			break
+		}
+
+		rules, err := db.FindRiskRulesByPortfolioId(tx, p.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		list, err := evaluateRiskRules(tx, p, *rules)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, list...)
+		id = p.ParentId
+	}
+
+	return events, nil
+}
+
+//=============================================================================
+//--- Scheduled evaluation of the enabled rules of a portfolio
+
+func CheckScheduledRiskRules(tx *gorm.DB, portfolioId uint) ([]*db.RiskEvent, error) {
+	p, err := db.GetPortfolioById(tx, portfolioId)
+	if err != nil {
+		return nil, err
+	}
+
+	if p == nil {
+		slog.Warn("CheckScheduledRiskRules: Portfolio not found. Skipping its rules", "portfolioId", portfolioId)
+		return nil, nil
+	}
+
+	rules, err := db.FindRiskRulesByPortfolioId(tx, p.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return evaluateRiskRules(tx, p, *rules)
+}
+
+//=============================================================================
+//--- Must be called after the transaction has been committed
+
+func PublishRiskEvents(events []*db.RiskEvent) {
+	for _, e := range events {
+		message := fmt.Sprintf("Rule '%s' breached (value %.2f, threshold %.2f). Systems switched to inactive: %s", e.RuleType, e.Value, e.Threshold, e.TsIds)
+		params  := map[string]any{
+			"portfolioId": e.PortfolioId,
+			"riskRuleId" : e.RiskRuleId,
+			"ruleType"   : e.RuleType,
+			"threshold"  : e.Threshold,
+			"value"      : e.Value,
+			"tsIds"      : e.TsIds,
+		}
+
+		err := msg.SendEvent(e.Username, msg.EventLevelError, "Portfolio risk limit breached", message, params)
+		if err != nil {
+			slog.Error("PublishRiskEvents: Cannot publish risk event", "portfolioId", e.PortfolioId, "ruleType", e.RuleType, "error", err)
+		}
+	}
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func getRiskRuleAndCheckAccess(tx *gorm.DB, c *auth.Context, portfolioId uint, id uint) (*db.RiskRule, error) {
+	_, err := getPortfolioAndCheckAccess(tx, c, portfolioId)
+	if err != nil {
+		return nil, err
+	}
+
+	rr, err := db.GetRiskRuleById(tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if rr == nil || rr.PortfolioId != portfolioId {
+		return nil, req.NewNotFoundError("Risk rule was not found: %v", id)
+	}
+
+	return rr, nil
+}
+
+//=============================================================================
+//--- Rules are evaluated in order. A breached rule switches the systems to inactive,
+//--- so the following rules only see the systems that are still active. An event is
+//--- recorded only when some system has been switched off.
+//--- A breached daily loss or drawdown rule is reset, otherwise systems turned on
+//--- again by the user would be immediately switched off because of the same losses
+
+func evaluateRiskRules(tx *gorm.DB, p *db.Portfolio, rules []db.RiskRule) ([]*db.RiskEvent, error) {
+	var events  []*db.RiskEvent
+	var days    []datatype.IntDate
+	var profits []float64
+
+	systems, err := getPortfolioTradingSystems(tx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	var active []*db.TradingSystem
+	for _, ts := range systems {
+		if ts.Running && ts.Active {
+			active = append(active, ts)
+		}
+	}
+
+	sort.Slice(active, func(i, j int) bool {
+		return active[i].Id < active[j].Id
+	})
+
+	for _, rr := range rules {
+		if !rr.Enabled || len(active) == 0 {
+			continue
+		}
+
+		var value float64
+		var toStop []*db.TradingSystem
+
+		switch rr.RuleType {
+			case db.RiskRuleTypeDailyLoss, db.RiskRuleTypeDrawdown:
+				if days == nil {
+					days, profits, err = calcPortfolioDailyProfits(tx, systems)
+					if err != nil {
+						return nil, err
+					}
+				}
+
+				equity := calcEquityAfter(days, profits, rr.ResetDay)
+
+				if rr.RuleType == db.RiskRuleTypeDailyLoss {
+					value = calcDailyLoss(equity)
+				} else {
+					value = calcCurrentDrawdown(equity)
+				}
+
+				if value > rr.Threshold {
+					toStop = active
+				}
+
+			case db.RiskRuleTypeActiveSystems:
+				value = float64(len(active))
+				for i := len(active) -1; i >= 0 && float64(i+1) > rr.Threshold; i-- {
+					toStop = append(toStop, active[i])
+				}
+
+			case db.RiskRuleTypeMargin:
+				margins := make([]float64, len(active))
+				for i, ts := range active {
+					margins[i], err = calcMargin(tx, ts)
+					if err != nil {
+						return nil, err
+					}
+					value += margins[i]
+				}
+
+				margin := value
+				for i := len(active) -1; i >= 0 && margin > rr.Threshold; i-- {
+					toStop = append(toStop, active[i])
+					margin -= margins[i]
+				}
+
+			default:
+				slog.Error("evaluateRiskRules: Unknown rule type", "id", rr.Id, "ruleType", rr.RuleType)
+				continue
+		}
+
+		if len(toStop) == 0 {
+			continue
+		}
+
+		e, err := stopTradingSystems(tx, p, &rr, value, toStop)
+		if err != nil {
+			return nil, err
+		}
+
+		if rr.RuleType == db.RiskRuleTypeDailyLoss || rr.RuleType == db.RiskRuleTypeDrawdown {
+			rr.ResetDay = datatype.Today(time.UTC)
+
+			err = db.UpdateRiskRule(tx, &rr)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		events = append(events, e)
+		active = active[:len(active) - len(toStop)]
+	}
+
+	return events, nil
+}
+
+//=============================================================================
+//--- Daily equity of the portfolio, up to today (the last day has no return if
+//--- systems did not trade today)
+
+func calcPortfolioEquity(tx *gorm.DB, systems []*db.TradingSystem) ([]float64, error) {
+	days, profits, err := calcPortfolioDailyProfits(tx, systems)
+	if err != nil {
+		return nil, err
+	}
+
+	return calcEquityAfter(days, profits, 0), nil
+}
+
+//=============================================================================
+//--- Daily net profits of the portfolio, up to today
+
+func calcPortfolioDailyProfits(tx *gorm.DB, systems []*db.TradingSystem) ([]datatype.IntDate, []float64, error) {
+	var returns []db.DailyReturn
+
+	for _, ts := range systems {
+		list, err := db.FindDailyReturnsByTradingSystemId(tx, ts.Id)
+		if err != nil {
+			return nil, nil, err
+		}
+		returns = append(returns, *list...)
+	}
+
+	today := datatype.Today(time.UTC)
+	returns = append(returns, db.DailyReturn{ Day: today })
+
+	days, series := performance.AlignDailyNetReturns(systems, &returns)
+	profits      := make([]float64, len(days))
+
+	for t := range days {
+		for i := range series {
+			profits[t] += series[i][t]
+		}
+	}
+
+	return days, profits, nil
+}
+
+//=============================================================================
+//--- Equity built from the profits of the days after fromDay
+
+func calcEquityAfter(days []datatype.IntDate, profits []float64, fromDay datatype.IntDate) []float64 {
+	equity := []float64{}
+	sum    := 0.0
+
+	for t, day := range days {
+		if day > fromDay {
+			sum += profits[t]
+			equity = append(equity, sum)
+		}
+	}
+
+	return equity
+}
+
+//=============================================================================
+
+func calcDailyLoss(equity []float64) float64 {
+	n := len(equity)
+	if n == 0 {
+		return 0
+	}
+
+	previous := 0.0
+	if n > 1 {
+		previous = equity[n-2]
+	}
+
+	return math.Max(0, previous - equity[n-1])
+}
+
+//=============================================================================
+
+func calcCurrentDrawdown(equity []float64) float64 {
+	peak := 0.0
+	for _, value := range equity {
+		peak = math.Max(peak, value)
+	}
+
+	if len(equity) == 0 {
+		return 0
+	}
+
+	return peak - equity[len(equity) -1]
+}
+
+//=============================================================================
+//--- Systems in automatic mode are switched to manual, otherwise the activation
+//--- filter could turn them on again with the next trades
+
+func stopTradingSystems(tx *gorm.DB, p *db.Portfolio, rr *db.RiskRule, value float64, list []*db.TradingSystem) (*db.RiskEvent, error) {
+	var ids []uint
+
+	for _, ts := range list {
+		slog.Warn("stopTradingSystems: Switching trading system to inactive", "portfolioId", p.Id, "ruleType", rr.RuleType, "tsId", ts.Id, "tsName", ts.Name)
+
+		ts.AutoActivation = false
+		err := changeActive(tx, ts, false)
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, ts.Id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	data, _ := json.Marshal(ids)
+	now     := time.Now()
+
+	e := &db.RiskEvent{
+		PortfolioId: p.Id,
+		RiskRuleId : rr.Id,
+		Username   : p.Username,
+		Timestamp  : &now,
+		RuleType   : rr.RuleType,
+		Threshold  : rr.Threshold,
+		Value      : math.Round(value*100) / 100,
+		TsIds      : string(data),
+	}
+
+	return e, db.AddRiskEvent(tx, e)
+}
+
+//=============================================================================
@@ -0,0 +1,118 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package business
+
+import (
+	"time"
+
+	"github.com/tradalia/core/auth"
+	"github.com/tradalia/core/req"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+type RollPeriodSpec struct {
+	DataSymbol string     `json:"dataSymbol" binding:"required,max=16"`
+	Symbol     string     `json:"symbol"     binding:"required,max=16"`
+	FromDate   *time.Time `json:"fromDate"   binding:"required"`
+	ToDate     *time.Time `json:"toDate"     binding:"required"`
+}
+
+//=============================================================================
+
+func GetRollPeriods(tx *gorm.DB, c *auth.Context, filter map[string]any, offset int, limit int) (*[]db.RollPeriod, error) {
+	if ! c.Session.IsAdmin() {
+		filter["username"] = c.Session.Username
+	}
+
+	return db.GetRollPeriods(tx, filter, offset, limit)
+}
+
+//=============================================================================
+
+func AddRollPeriod(tx *gorm.DB, c *auth.Context, spec *RollPeriodSpec) (*db.RollPeriod, error) {
+	c.Log.Info("AddRollPeriod: Creating new roll period", "dataSymbol", spec.DataSymbol, "symbol", spec.Symbol)
+
+	if spec.ToDate.Before(*spec.FromDate) {
+		return nil, req.NewBadRequestError("The from date is after the to date")
+	}
+
+	periods, err := db.FindRollPeriodsByDataSymbol(tx, c.Session.Username, spec.DataSymbol)
+	if err != nil {
+		return nil, err
+	}
+
+	//--- Only one source can be authoritative at any given time
+
+	for _, rp := range *periods {
+		if !spec.FromDate.After(*rp.ToDate) && !spec.ToDate.Before(*rp.FromDate) {
+			return nil, req.NewBadRequestError("Roll period overlaps an existing one: %v", rp.Id)
+		}
+	}
+
+	rp := &db.RollPeriod{
+		Username  : c.Session.Username,
+		DataSymbol: spec.DataSymbol,
+		Symbol    : spec.Symbol,
+		FromDate  : spec.FromDate,
+		ToDate    : spec.ToDate,
+	}
+
+	err = db.AddRollPeriod(tx, rp)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Log.Info("AddRollPeriod: Roll period created", "id", rp.Id)
+	return rp, nil
+}
+
+//=============================================================================
+
+func DeleteRollPeriod(tx *gorm.DB, c *auth.Context, id uint) error {
+	c.Log.Info("DeleteRollPeriod: Deleting roll period", "id", id)
+
+	rp, err := db.GetRollPeriodById(tx, id)
+	if err != nil {
+		return err
+	}
+
+	if rp == nil {
+		return req.NewNotFoundError("Roll period was not found: %v", id)
+	}
+
+	if ! c.Session.IsAdmin() {
+		if rp.Username != c.Session.Username {
+			return req.NewForbiddenError("Roll period not owned by user: %v", id)
+		}
+	}
+
+	return db.DeleteRollPeriod(tx, id)
+}
+
+//=============================================================================
@@ -0,0 +1,94 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package business
+
+import (
+	"github.com/tradalia/core/auth"
+	"github.com/tradalia/core/req"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+//--- Selects a set of trading systems: a list of ids, a portfolio (with all its
+//--- children) or a smart group
+
+type SystemSelection struct {
+	TsIds       []uint `json:"tsIds"`
+	PortfolioId uint   `json:"portfolioId"`
+	GroupId     uint   `json:"groupId"`
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func getSelectedTradingSystems(tx *gorm.DB, c *auth.Context, sel *SystemSelection) ([]*db.TradingSystem, error) {
+	if sel.PortfolioId != 0 {
+		p, err := getPortfolioAndCheckAccess(tx, c, sel.PortfolioId)
+		if err != nil {
+			return nil, err
+		}
+
+		return getPortfolioTradingSystems(tx, p)
+	}
+
+	if sel.GroupId != 0 {
+		sg, err := getSmartGroupAndCheckAccess(tx, c, sel.GroupId)
+		if err != nil {
+			return nil, err
+		}
+
+		return getSmartGroupTradingSystems(tx, sg)
+	}
+
+	if len(sel.TsIds) == 0 {
+		return nil, req.NewBadRequestError("Either a list of trading systems, a portfolio or a smart group is required")
+	}
+
+	var list []*db.TradingSystem
+	idSet := map[uint]bool{}
+
+	for _, id := range sel.TsIds {
+		if idSet[id] {
+			continue
+		}
+
+		ts, err := getTradingSystemAndCheckAccess(tx, c, id)
+		if err != nil {
+			return nil, err
+		}
+
+		idSet[id] = true
+		list = append(list, ts)
+	}
+
+	return list, nil
+}
+
+//=============================================================================
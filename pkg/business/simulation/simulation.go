@@ -0,0 +1,252 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package simulation
+
+import (
+	"sort"
+	"time"
+
+	"github.com/tradalia/core/datatype"
+	"github.com/tradalia/portfolio-trader/pkg/business/filter"
+	"github.com/tradalia/portfolio-trader/pkg/core"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+)
+
+//=============================================================================
+
+type SystemInput struct {
+	TradingSystem *db.TradingSystem
+	Analysis      *filter.AnalysisResponse
+	Multiplier    float64
+	Added         bool
+}
+
+//=============================================================================
+
+type SystemResult struct {
+	Id             uint    `json:"id"`
+	Name           string  `json:"name"`
+	Multiplier     float64 `json:"multiplier"`
+	Added          bool    `json:"added"`
+	Active         bool    `json:"active"`
+	UnfProfit      float64 `json:"unfProfit"`
+	FilProfit      float64 `json:"filProfit"`
+	UnfMaxDrawdown float64 `json:"unfMaxDrawdown"`
+	FilMaxDrawdown float64 `json:"filMaxDrawdown"`
+}
+
+//=============================================================================
+
+type Summary struct {
+	UnfProfit      float64 `json:"unfProfit"`
+	FilProfit      float64 `json:"filProfit"`
+	UnfMaxDrawdown float64 `json:"unfMaxDrawdown"`
+	FilMaxDrawdown float64 `json:"filMaxDrawdown"`
+}
+
+//=============================================================================
+
+type SimulationResponse struct {
+	TradingSystems     []*SystemResult    `json:"tradingSystems"`
+	Summary            Summary            `json:"summary"`
+	Days               []datatype.IntDate `json:"days"`
+	UnfilteredEquity   []float64          `json:"unfilteredEquity"`
+	FilteredEquity     []float64          `json:"filteredEquity"`
+	UnfilteredDrawdown []float64          `json:"unfilteredDrawdown"`
+	FilteredDrawdown   []float64          `json:"filteredDrawdown"`
+	ActiveSystems      []int              `json:"activeSystems"`
+}
+
+//=============================================================================
+//--- Combines the filter analysis of each system on a daily calendar. Analyses must
+//--- cover the whole history of the systems (filters need it) while only trades
+//--- exited after fromTime are taken into account. A system is active on a day if
+//--- its filter was on after its last trade exited up to that day
+
+func Simulate(inputs []*SystemInput, fromTime *time.Time) *SimulationResponse {
+	res := &SimulationResponse{
+		TradingSystems    : []*SystemResult{},
+		Days              : []datatype.IntDate{},
+		UnfilteredEquity  : []float64{},
+		FilteredEquity    : []float64{},
+		UnfilteredDrawdown: []float64{},
+		FilteredDrawdown  : []float64{},
+		ActiveSystems     : []int{},
+	}
+
+	//--- Collect days
+
+	daySet := map[datatype.IntDate]bool{}
+
+	for _, in := range inputs {
+		for i := range in.Analysis.Equities.Time {
+			if isInPeriod(in.Analysis.Equities.Time[i], fromTime) {
+				daySet[datatype.ToIntDate(&in.Analysis.Equities.Time[i])] = true
+			}
+		}
+	}
+
+	for day := range daySet {
+		res.Days = append(res.Days, day)
+	}
+
+	sort.Slice(res.Days, func(i, j int) bool {
+		return res.Days[i] < res.Days[j]
+	})
+
+	dayIndex := map[datatype.IntDate]int{}
+	for i, day := range res.Days {
+		dayIndex[day] = i
+	}
+
+	//--- Aggregate profits and activations
+
+	unfProfits := make([]float64, len(res.Days))
+	filProfits := make([]float64, len(res.Days))
+	res.ActiveSystems = make([]int, len(res.Days))
+
+	for _, in := range inputs {
+		sr := calcSystem(in, fromTime, dayIndex, unfProfits, filProfits, res.ActiveSystems)
+		res.TradingSystems = append(res.TradingSystems, sr)
+	}
+
+	//--- Build equities and drawdowns
+
+	res.UnfilteredEquity = *core.BuildEquity(&unfProfits)
+	res.FilteredEquity   = *core.BuildEquity(&filProfits)
+
+	unfDrawdown, maxUnfDD := core.BuildDrawDown(&res.UnfilteredEquity)
+	filDrawdown, maxFilDD := core.BuildDrawDown(&res.FilteredEquity)
+
+	res.UnfilteredDrawdown = *unfDrawdown
+	res.FilteredDrawdown   = *filDrawdown
+
+	if len(res.Days) > 0 {
+		res.Summary.UnfProfit = core.Trunc2d(res.UnfilteredEquity[len(res.Days) -1])
+		res.Summary.FilProfit = core.Trunc2d(res.FilteredEquity  [len(res.Days) -1])
+	}
+
+	res.Summary.UnfMaxDrawdown = core.Trunc2d(maxUnfDD)
+	res.Summary.FilMaxDrawdown = core.Trunc2d(maxFilDD)
+
+	return res
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func calcSystem(in *SystemInput, fromTime *time.Time, dayIndex map[datatype.IntDate]int, unfProfits, filProfits []float64, active []int) *SystemResult {
+	ts := in.TradingSystem
+	e  := in.Analysis.Equities
+
+	sr := &SystemResult{
+		Id        : ts.Id,
+		Name      : ts.Name,
+		Multiplier: in.Multiplier,
+		Added     : in.Added,
+		Active    : true,
+	}
+
+	var unfList, filList []float64
+
+	//--- Activation changes: the state after the trade at index i is FilterActivation[i]
+
+	changes := make([]int8, len(active))
+	for i := range changes {
+		changes[i] = -1
+	}
+
+	prevFiltered := 0.0
+
+	for i, t := range e.Time {
+		filtered := (e.FilteredEquity[i] - prevFiltered) * in.Multiplier
+		prevFiltered = e.FilteredEquity[i]
+
+		if !isInPeriod(t, fromTime) {
+			continue
+		}
+
+		idx       := dayIndex[datatype.ToIntDate(&t)]
+		unfProfit := e.NetProfit[i] * in.Multiplier
+
+		unfProfits[idx] += unfProfit
+		filProfits[idx] += filtered
+		unfList = append(unfList, unfProfit)
+		filList = append(filList, filtered)
+
+		changes[idx] = e.FilterActivation[i]
+	}
+
+	//--- Systems without trades before the period start as active
+
+	state := int8(1)
+	for i := len(e.Time) -1; i >= 0; i-- {
+		if !isInPeriod(e.Time[i], fromTime) {
+			state = e.FilterActivation[i]
+			break
+		}
+	}
+
+	for i := range active {
+		if changes[i] != -1 {
+			state = changes[i]
+		}
+
+		if state != 0 {
+			active[i]++
+		}
+	}
+
+	if len(e.FilterActivation) > 0 {
+		sr.Active = e.FilterActivation[len(e.FilterActivation) -1] != 0
+	}
+
+	unfEquity := core.BuildEquity(&unfList)
+	filEquity := core.BuildEquity(&filList)
+	_, maxUnfDD := core.BuildDrawDown(unfEquity)
+	_, maxFilDD := core.BuildDrawDown(filEquity)
+
+	if len(unfList) > 0 {
+		sr.UnfProfit = core.Trunc2d((*unfEquity)[len(unfList) -1])
+		sr.FilProfit = core.Trunc2d((*filEquity)[len(filList) -1])
+	}
+
+	sr.UnfMaxDrawdown = core.Trunc2d(maxUnfDD)
+	sr.FilMaxDrawdown = core.Trunc2d(maxFilDD)
+
+	return sr
+}
+
+//=============================================================================
+
+func isInPeriod(t time.Time, fromTime *time.Time) bool {
+	return fromTime == nil || !t.Before(*fromTime)
+}
+
+//=============================================================================
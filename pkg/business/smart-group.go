@@ -0,0 +1,317 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package business
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/tradalia/core/auth"
+	"github.com/tradalia/core/req"
+	"github.com/tradalia/portfolio-trader/pkg/business/performance"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+//--- Criteria are in AND. Inside each criteria values are in OR, except for tags
+//--- where the system must have all of them. Empty criteria match everything
+
+type SmartGroupQuery struct {
+	Tags          []string      `json:"tags"`
+	StrategyTypes []string      `json:"strategyTypes"`
+	MarketTypes   []string      `json:"marketTypes"`
+	Timeframes    []int         `json:"timeframes"`
+	DataSymbols   []string      `json:"dataSymbols"`
+	Statuses      []db.TsStatus `json:"statuses"`
+}
+
+//-----------------------------------------------------------------------------
+
+func (q *SmartGroupQuery) Matches(ts *db.TradingSystem) bool {
+	tags := ParseTags(ts.Tags)
+
+	for _, tag := range q.Tags {
+		if !slices.Contains(tags, strings.ToLower(tag)) {
+			return false
+		}
+	}
+
+	return matchesAny(q.StrategyTypes, ts.StrategyType) &&
+		   matchesAny(q.MarketTypes,   ts.MarketType)   &&
+		   matchesAny(q.DataSymbols,   ts.DataSymbol)   &&
+		   (len(q.Timeframes) == 0 || slices.Contains(q.Timeframes, ts.Timeframe)) &&
+		   (len(q.Statuses)   == 0 || slices.Contains(q.Statuses,   ts.Status))
+}
+
+//=============================================================================
+
+type SmartGroupSpec struct {
+	Name  string          `json:"name"  binding:"required,max=64"`
+	Query SmartGroupQuery `json:"query"`
+}
+
+//=============================================================================
+
+type SmartGroup struct {
+	Id       uint            `json:"id"`
+	Username string          `json:"username"`
+	Name     string          `json:"name"`
+	Query    SmartGroupQuery `json:"query"`
+}
+
+//=============================================================================
+//--- Tags arrive from the inventory as a free string: they can be separated by
+//--- commas, semicolons or spaces and are compared ignoring the case
+
+func ParseTags(tags string) []string {
+	var list []string
+
+	fields := strings.FieldsFunc(tags, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\t'
+	})
+
+	for _, tag := range fields {
+		tag = strings.ToLower(tag)
+		if !slices.Contains(list, tag) {
+			list = append(list, tag)
+		}
+	}
+
+	return list
+}
+
+//=============================================================================
+
+func GetSmartGroups(tx *gorm.DB, c *auth.Context, filter map[string]any, offset int, limit int) (*[]*SmartGroup, error) {
+	if ! c.Session.IsAdmin() {
+		filter["username"] = c.Session.Username
+	}
+
+	list, err := db.GetSmartGroups(tx, filter, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []*SmartGroup{}
+	for i := range *list {
+		res = append(res, toSmartGroup(&(*list)[i]))
+	}
+
+	return &res, nil
+}
+
+//=============================================================================
+
+func AddSmartGroup(tx *gorm.DB, c *auth.Context, spec *SmartGroupSpec) (*SmartGroup, error) {
+	c.Log.Info("AddSmartGroup: Creating new smart group", "name", spec.Name)
+
+	sg := &db.SmartGroup{
+		Username: c.Session.Username,
+		Name    : spec.Name,
+		Query   : toQueryJson(&spec.Query),
+	}
+
+	err := db.AddSmartGroup(tx, sg)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Log.Info("AddSmartGroup: Smart group created", "id", sg.Id)
+	return toSmartGroup(sg), nil
+}
+
+//=============================================================================
+
+func UpdateSmartGroup(tx *gorm.DB, c *auth.Context, id uint, spec *SmartGroupSpec) (*SmartGroup, error) {
+	c.Log.Info("UpdateSmartGroup: Updating smart group", "id", id, "name", spec.Name)
+
+	sg, err := getSmartGroupAndCheckAccess(tx, c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	sg.Name  = spec.Name
+	sg.Query = toQueryJson(&spec.Query)
+
+	err = db.UpdateSmartGroup(tx, sg)
+	if err != nil {
+		return nil, err
+	}
+
+	return toSmartGroup(sg), nil
+}
+
+//=============================================================================
+
+func DeleteSmartGroup(tx *gorm.DB, c *auth.Context, id uint) error {
+	c.Log.Info("DeleteSmartGroup: Deleting smart group", "id", id)
+
+	_, err := getSmartGroupAndCheckAccess(tx, c, id)
+	if err != nil {
+		return err
+	}
+
+	return db.DeleteSmartGroup(tx, id)
+}
+
+//=============================================================================
+
+func GetSmartGroupTradingSystems(tx *gorm.DB, c *auth.Context, id uint) ([]*db.TradingSystem, error) {
+	sg, err := getSmartGroupAndCheckAccess(tx, c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := getSmartGroupTradingSystems(tx, sg)
+	if err != nil {
+		return nil, err
+	}
+
+	if list == nil {
+		list = []*db.TradingSystem{}
+	}
+
+	return list, nil
+}
+
+//=============================================================================
+
+func RunSmartGroupPerformanceAnalysis(tx *gorm.DB, c *auth.Context, id uint, req *performance.AnalysisRequest) (*performance.PortfolioAnalysisResponse, error) {
+	sg, err := getSmartGroupAndCheckAccess(tx, c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	systems, err := getSmartGroupTradingSystems(tx, sg)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &db.Portfolio{
+		Username: sg.Username,
+		Name    : sg.Name,
+	}
+
+	return runPortfolioAnalysis(tx, c, p, systems, req)
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func getSmartGroupAndCheckAccess(tx *gorm.DB, c *auth.Context, id uint) (*db.SmartGroup, error) {
+	sg, err := db.GetSmartGroupById(tx, id)
+	if err != nil {
+		c.Log.Error("getSmartGroup: Cannot get the smart group", "id", id, "error", err)
+		return nil, err
+	}
+
+	if sg == nil {
+		return nil, req.NewNotFoundError("Smart group was not found: %v", id)
+	}
+
+	if ! c.Session.IsAdmin() {
+		if sg.Username != c.Session.Username {
+			return nil, req.NewForbiddenError("Smart group not owned by user: %v", id)
+		}
+	}
+
+	return sg, nil
+}
+
+//=============================================================================
+//--- Systems are those of the group's owner, ordered by id
+
+func getSmartGroupTradingSystems(tx *gorm.DB, sg *db.SmartGroup) ([]*db.TradingSystem, error) {
+	query := fromQueryJson(sg.Query)
+
+	tsList, err := db.GetTradingSystemsByUser(tx, sg.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*db.TradingSystem
+	for i := range *tsList {
+		ts := &(*tsList)[i]
+		if query.Matches(ts) {
+			list = append(list, ts)
+		}
+	}
+
+	slices.SortFunc(list, func(a, b *db.TradingSystem) int {
+		return int(a.Id) - int(b.Id)
+	})
+
+	return list, nil
+}
+
+//=============================================================================
+
+func matchesAny(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//=============================================================================
+
+func toSmartGroup(sg *db.SmartGroup) *SmartGroup {
+	return &SmartGroup{
+		Id      : sg.Id,
+		Username: sg.Username,
+		Name    : sg.Name,
+		Query   : *fromQueryJson(sg.Query),
+	}
+}
+
+//=============================================================================
+
+func toQueryJson(q *SmartGroupQuery) string {
+	data, _ := json.Marshal(q)
+	return string(data)
+}
+
+//=============================================================================
+
+func fromQueryJson(data string) *SmartGroupQuery {
+	q := &SmartGroupQuery{}
+	_ = json.Unmarshal([]byte(data), q)
+	return q
+}
+
+//=============================================================================
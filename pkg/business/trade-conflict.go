@@ -0,0 +1,169 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package business
+
+import (
+	"encoding/json"
+
+	"github.com/tradalia/core/auth"
+	"github.com/tradalia/core/req"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+const (
+	ConflictActionKeep    = "keep"
+	ConflictActionReplace = "replace"
+	ConflictActionAdd     = "add"
+)
+
+//-----------------------------------------------------------------------------
+//--- keep    : the existing trade is kept and the new one is discarded
+//--- replace : the existing trade is replaced by the new one
+//--- add     : the new trade is added as well
+
+type TradeConflictResolveRequest struct {
+	Action string `json:"action" binding:"required,oneof=keep replace add"`
+}
+
+//=============================================================================
+
+func GetTradeConflicts(tx *gorm.DB, c *auth.Context, tsId uint, status string) (*[]db.TradeConflict, error) {
+	_, err := getTradingSystemAndCheckAccess(tx, c, tsId)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.FindTradeConflictsByTradingSystemId(tx, tsId, status)
+}
+
+//=============================================================================
+
+func ResolveTradeConflict(tx *gorm.DB, c *auth.Context, tsId uint, conflictId uint, tcrr *TradeConflictResolveRequest) (*db.TradeConflict, error) {
+	c.Log.Info("ResolveTradeConflict: Resolving trade conflict", "id", tsId, "conflictId", conflictId, "action", tcrr.Action)
+
+	ts, err := getTradingSystemAndCheckAccess(tx, c, tsId)
+	if err != nil {
+		return nil, err
+	}
+
+	tc, err := db.GetTradeConflictById(tx, conflictId)
+	if err != nil {
+		return nil, err
+	}
+
+	if tc == nil || tc.TradingSystemId != tsId {
+		return nil, req.NewNotFoundError("Trade conflict was not found: %v", conflictId)
+	}
+
+	if tc.Status != db.TradeConflictStatusPending {
+		return nil, req.NewUnprocessableEntityError("Trade conflict was already resolved: %v", conflictId)
+	}
+
+	newTrade := &db.Trade{}
+	err = json.Unmarshal([]byte(tc.NewValue), newTrade)
+	if err != nil {
+		return nil, req.NewServerErrorByError(err)
+	}
+
+	var changed []*db.Trade
+
+	switch tcrr.Action {
+		case ConflictActionKeep:
+			tc.Status = db.TradeConflictStatusKept
+
+		case ConflictActionReplace:
+			changed, err = replaceConflictingTrade(tx, c, ts, tc, newTrade)
+			tc.Status = db.TradeConflictStatusReplaced
+
+		case ConflictActionAdd:
+			newTrade.Id              = 0
+			newTrade.TradingSystemId = tsId
+			err = db.AddTrade(tx, newTrade)
+			changed   = []*db.Trade{ newTrade }
+			tc.Status = db.TradeConflictStatusAdded
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.UpdateTradeConflict(tx, tc)
+	if err != nil {
+		return nil, err
+	}
+
+	if tc.Status != db.TradeConflictStatusKept {
+		err = refreshTradingSystemStats(tx, ts, changed...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return tc, nil
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+//--- Returns the trade before and after the replacement, to rebuild the days of both
+
+func replaceConflictingTrade(tx *gorm.DB, c *auth.Context, ts *db.TradingSystem, tc *db.TradeConflict, newTrade *db.Trade) ([]*db.Trade, error) {
+	if tc.TradeId == nil {
+		return nil, req.NewUnprocessableEntityError("Trade conflict has no trade to replace: %v", tc.Id)
+	}
+
+	tr, err := db.GetTradeById(tx, *tc.TradeId)
+	if err != nil {
+		return nil, err
+	}
+
+	if tr == nil {
+		return nil, req.NewNotFoundError("Trade was not found: %v", *tc.TradeId)
+	}
+
+	oldTrade := *tr
+	oldValue := toJson(tr)
+	replaceTrade(tr, newTrade)
+
+	err = db.UpdateTrade(tx, tr)
+	if err != nil {
+		return nil, err
+	}
+
+	err = addTradeAudit(tx, c, ts, &tr.Id, oldTrade.String(), db.TradeAuditActionUpdate, "Trade conflict resolution", oldValue, toJson(tr))
+	if err != nil {
+		return nil, err
+	}
+
+	return []*db.Trade{ &oldTrade, tr }, nil
+}
+
+//=============================================================================
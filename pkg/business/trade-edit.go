@@ -0,0 +1,377 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package business
+
+import (
+	"time"
+
+	"github.com/tradalia/core/auth"
+	"github.com/tradalia/core/req"
+	"github.com/tradalia/portfolio-trader/pkg/core"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+//--- Trades whose exit date falls in [FromDate .. ToDate] are deleted
+
+type TradeRangeDeleteRequest struct {
+	FromDate *time.Time `json:"fromDate" binding:"required"`
+	ToDate   *time.Time `json:"toDate"   binding:"required"`
+	Reason   string     `json:"reason"`
+}
+
+//-----------------------------------------------------------------------------
+
+type TradeRangeDeleteResponse struct {
+	Deleted int `json:"deleted"`
+}
+
+//-----------------------------------------------------------------------------
+//--- Only the provided fields are changed
+
+type TradeUpdateRequest struct {
+	TradeType             *string    `json:"tradeType"`
+	EntryDate             *time.Time `json:"entryDate"`
+	EntryPrice            *float64   `json:"entryPrice"`
+	EntryLabel            *string    `json:"entryLabel"`
+	ExitDate              *time.Time `json:"exitDate"`
+	ExitPrice             *float64   `json:"exitPrice"`
+	ExitLabel             *string    `json:"exitLabel"`
+	GrossProfit           *float64   `json:"grossProfit"`
+	Contracts             *int       `json:"contracts"`
+	MaxAdverseExcursion   *float64   `json:"maxAdverseExcursion"`
+	MaxFavorableExcursion *float64   `json:"maxFavorableExcursion"`
+	Reason                string     `json:"reason"`
+}
+
+//-----------------------------------------------------------------------------
+
+type TradeExclusionRequest struct {
+	Excluded bool   `json:"excluded"`
+	Reason   string `json:"reason"`
+}
+
+//=============================================================================
+
+func DeleteTradesInRange(tx *gorm.DB, c *auth.Context, tsId uint, trdr *TradeRangeDeleteRequest) (*TradeRangeDeleteResponse, error) {
+	c.Log.Info("DeleteTradesInRange: Deleting trades of trading system", "id", tsId, "from", trdr.FromDate, "to", trdr.ToDate)
+
+	ts, err := getTradingSystemAndCheckAccess(tx, c, tsId)
+	if err != nil {
+		return nil, err
+	}
+
+	if trdr.ToDate.Before(*trdr.FromDate) {
+		return nil, req.NewBadRequestError("The from date is after the to date")
+	}
+
+	trades, err := db.FindTradesByTsIdInRange(tx, tsId, *trdr.FromDate, *trdr.ToDate)
+	if err != nil {
+		return nil, err
+	}
+
+	var deleted []*db.Trade
+
+	for i := range *trades {
+		tr := &(*trades)[i]
+		err = deleteTrade(tx, c, ts, tr, trdr.Reason)
+		if err != nil {
+			return nil, err
+		}
+
+		deleted = append(deleted, tr)
+	}
+
+	if len(deleted) > 0 {
+		err = refreshTradingSystemStats(tx, ts, deleted...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c.Log.Info("DeleteTradesInRange: Trades deleted", "id", tsId, "deleted", len(*trades))
+
+	return &TradeRangeDeleteResponse{
+		Deleted: len(*trades),
+	}, nil
+}
+
+//=============================================================================
+
+func DeleteTrade(tx *gorm.DB, c *auth.Context, tsId uint, tradeId uint, reason string) error {
+	c.Log.Info("DeleteTrade: Deleting trade", "id", tsId, "tradeId", tradeId)
+
+	ts, tr, err := getTradeAndCheckAccess(tx, c, tsId, tradeId)
+	if err != nil {
+		return err
+	}
+
+	err = deleteTrade(tx, c, ts, tr, reason)
+	if err != nil {
+		return err
+	}
+
+	return refreshTradingSystemStats(tx, ts, tr)
+}
+
+//=============================================================================
+
+func UpdateTrade(tx *gorm.DB, c *auth.Context, tsId uint, tradeId uint, tur *TradeUpdateRequest) (*db.Trade, error) {
+	c.Log.Info("UpdateTrade: Updating trade", "id", tsId, "tradeId", tradeId)
+
+	ts, tr, err := getTradeAndCheckAccess(tx, c, tsId, tradeId)
+	if err != nil {
+		return nil, err
+	}
+
+	oldTrade := *tr
+	oldValue := toJson(tr)
+	applyTradeUpdate(tr, tur)
+
+	if tr.TradeType != db.TradeTypeLong && tr.TradeType != db.TradeTypeShort {
+		return nil, req.NewBadRequestError("Invalid trade type: %s", tr.TradeType)
+	}
+
+	if tr.EntryDate == nil || tr.ExitDate == nil || tr.ExitDate.Before(*tr.EntryDate) {
+		return nil, req.NewBadRequestError("The exit date is before the entry date")
+	}
+
+	if tr.Contracts <= 0 {
+		return nil, req.NewBadRequestError("Contracts must be positive: %d", tr.Contracts)
+	}
+
+	err = db.UpdateTrade(tx, tr)
+	if err != nil {
+		return nil, err
+	}
+
+	err = adjustRuntimeDailyReturns(tx, ts, &oldTrade, tr)
+	if err != nil {
+		return nil, err
+	}
+
+	err = addTradeAudit(tx, c, ts, &tr.Id, oldTrade.String(), db.TradeAuditActionUpdate, tur.Reason, oldValue, toJson(tr))
+	if err != nil {
+		return nil, err
+	}
+
+	err = refreshTradingSystemStats(tx, ts, &oldTrade, tr)
+	if err != nil {
+		return nil, err
+	}
+
+	return tr, nil
+}
+
+//=============================================================================
+
+func SetTradeExcluded(tx *gorm.DB, c *auth.Context, tsId uint, tradeId uint, ter *TradeExclusionRequest) (*db.Trade, error) {
+	c.Log.Info("SetTradeExcluded: Changing exclusion of trade", "id", tsId, "tradeId", tradeId, "excluded", ter.Excluded)
+
+	ts, tr, err := getTradeAndCheckAccess(tx, c, tsId, tradeId)
+	if err != nil {
+		return nil, err
+	}
+
+	if tr.Excluded == ter.Excluded {
+		return tr, nil
+	}
+
+	oldTrade   := *tr
+	oldValue   := toJson(tr)
+	tr.Excluded = ter.Excluded
+
+	err = db.UpdateTrade(tx, tr)
+	if err != nil {
+		return nil, err
+	}
+
+	err = adjustRuntimeDailyReturns(tx, ts, &oldTrade, tr)
+	if err != nil {
+		return nil, err
+	}
+
+	action := db.TradeAuditActionInclude
+	if tr.Excluded {
+		action = db.TradeAuditActionExclude
+	}
+
+	err = addTradeAudit(tx, c, ts, &tr.Id, tr.String(), action, ter.Reason, oldValue, toJson(tr))
+	if err != nil {
+		return nil, err
+	}
+
+	err = refreshTradingSystemStats(tx, ts, tr)
+	if err != nil {
+		return nil, err
+	}
+
+	return tr, nil
+}
+
+//=============================================================================
+
+func GetTradeAudits(tx *gorm.DB, c *auth.Context, tsId uint) (*[]db.TradeAudit, error) {
+	_, err := getTradingSystemAndCheckAccess(tx, c, tsId)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.FindTradeAuditsByTradingSystemId(tx, tsId)
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func getTradeAndCheckAccess(tx *gorm.DB, c *auth.Context, tsId uint, tradeId uint) (*db.TradingSystem, *db.Trade, error) {
+	ts, err := getTradingSystemAndCheckAccess(tx, c, tsId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tr, err := db.GetTradeById(tx, tradeId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if tr == nil || tr.TradingSystemId != tsId {
+		return nil, nil, req.NewNotFoundError("Trade was not found: %v", tradeId)
+	}
+
+	return ts, tr, nil
+}
+
+//=============================================================================
+
+func deleteTrade(tx *gorm.DB, c *auth.Context, ts *db.TradingSystem, tr *db.Trade, reason string) error {
+	err := db.UnmatchExecutionsByTradeId(tx, tr.Id)
+	if err != nil {
+		return err
+	}
+
+	err = db.DeleteTrade(tx, tr.Id)
+	if err != nil {
+		return err
+	}
+
+	err = adjustRuntimeDailyReturns(tx, ts, tr, nil)
+	if err != nil {
+		return err
+	}
+
+	return addTradeAudit(tx, c, ts, &tr.Id, tr.String(), db.TradeAuditActionDelete, reason, toJson(tr), "")
+}
+
+//=============================================================================
+
+func applyTradeUpdate(tr *db.Trade, tur *TradeUpdateRequest) {
+	if tur.TradeType != nil {
+		tr.TradeType = *tur.TradeType
+	}
+	if tur.EntryDate != nil {
+		tr.EntryDate = tur.EntryDate
+	}
+	if tur.EntryPrice != nil {
+		tr.EntryPrice = *tur.EntryPrice
+	}
+	if tur.EntryLabel != nil {
+		tr.EntryLabel = *tur.EntryLabel
+	}
+	if tur.ExitDate != nil {
+		tr.ExitDate = tur.ExitDate
+	}
+	if tur.ExitPrice != nil {
+		tr.ExitPrice = *tur.ExitPrice
+	}
+	if tur.ExitLabel != nil {
+		tr.ExitLabel = *tur.ExitLabel
+	}
+	if tur.GrossProfit != nil {
+		tr.GrossProfit = *tur.GrossProfit
+	}
+	if tur.Contracts != nil {
+		tr.Contracts = *tur.Contracts
+	}
+	if tur.MaxAdverseExcursion != nil {
+		tr.MaxAdverseExcursion = tur.MaxAdverseExcursion
+	}
+	if tur.MaxFavorableExcursion != nil {
+		tr.MaxFavorableExcursion = tur.MaxFavorableExcursion
+	}
+}
+
+//=============================================================================
+
+func addTradeAudit(tx *gorm.DB, c *auth.Context, ts *db.TradingSystem, tradeId *uint, tradeKey string, action string, reason string, oldValue string, newValue string) error {
+	now := time.Now().UTC()
+
+	return db.AddTradeAudit(tx, &db.TradeAudit{
+		TradingSystemId: ts.Id,
+		TradeId        : tradeId,
+		TradeKey       : tradeKey,
+		Username       : c.Session.Username,
+		Timestamp      : &now,
+		Action         : action,
+		Reason         : reason,
+		OldValue       : oldValue,
+		NewValue       : newValue,
+	})
+}
+
+//=============================================================================
+//--- After a manual change, the daily returns of the days of the changed trades and the
+//--- statistics are rebuilt from the trades that are left, ignoring the excluded ones.
+//--- Runtime daily returns are not rebuilt: they must be adjusted before calling this
+
+func refreshTradingSystemStats(tx *gorm.DB, ts *db.TradingSystem, changed ...*db.Trade) error {
+	trades, err := db.FindTradesByTradingSystemId(tx, ts.Id)
+	if err != nil {
+		return err
+	}
+
+	_, err = UpdateDailyReturns(tx, ts, trades, GetTradeDays(ts, changed))
+	if err != nil {
+		return err
+	}
+
+	updateFirstLastTrade(ts, trades)
+
+	fromDate := time.Now().Add(-time.Hour * 24 * core.LastStatsDays)
+	recent, err := db.FindTradesByTsIdFromTime(tx, ts.Id, &fromDate, nil)
+	if err != nil {
+		return err
+	}
+
+	core.UpdateLastStats(ts, recent)
+
+	return db.UpdateTradingSystem(tx, ts)
+}
+
+//=============================================================================
@@ -0,0 +1,109 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package business
+
+import (
+	"testing"
+
+	"github.com/tradalia/portfolio-trader/pkg/db"
+)
+
+//=============================================================================
+//--- Runtime daily returns are not rebuilt from trades, so they must be adjusted
+//--- when a live trade changes
+
+func TestAdjustRuntimeDailyReturns(t *testing.T) {
+	profit := 80.0
+
+	tests := []struct {
+		name     string
+		origin   string
+		excluded bool
+		action   string
+		start    float64
+		expected float64
+		trades   int
+	}{
+		{ "exclude live trade",     db.TradeOriginLive,     false, "exclude", 130,  30, 1 },
+		{ "include live trade",     db.TradeOriginLive,     true,  "include",  30, 130, 2 },
+		{ "exclude backtest trade", db.TradeOriginBacktest, false, "exclude", 130, 130, 2 },
+		{ "update live trade",      db.TradeOriginLive,     false, "update",  130, 110, 2 },
+		{ "delete live trade",      db.TradeOriginLive,     false, "delete",  130,  30, 1 },
+	}
+
+	for _, test := range tests {
+		tx, mem := newMemoryDb(t)
+		c := newTestContext()
+
+		ts := &db.TradingSystem{ Id: 1, Username: "user" }
+		tr := newTrade(date(2025, 1, 10), 100, 1, "NQ")
+		tr.TradingSystemId = ts.Id
+		tr.Origin          = test.origin
+		tr.Excluded        = test.excluded
+
+		other := newTrade(addMinutes(date(2025, 1, 10), 120), 30, 1, "NQ")
+		other.TradingSystemId = ts.Id
+		other.Origin          = db.TradeOriginLive
+
+		trades := 2
+		if test.excluded {
+			trades = 1
+		}
+
+		mem.add(ts, tr, other)
+		mem.add(&db.DailyReturn{ TradingSystemId: ts.Id, Day: 20250110, GrossProfit: test.start, Trades: trades, Origin: db.DailyReturnOriginRuntime })
+
+		var err error
+
+		switch test.action {
+			case "exclude":
+				_, err = SetTradeExcluded(tx, c, ts.Id, tr.Id, &TradeExclusionRequest{ Excluded: true })
+			case "include":
+				_, err = SetTradeExcluded(tx, c, ts.Id, tr.Id, &TradeExclusionRequest{ Excluded: false })
+			case "update":
+				_, err = UpdateTrade(tx, c, ts.Id, tr.Id, &TradeUpdateRequest{ GrossProfit: &profit })
+			case "delete":
+				err = DeleteTrade(tx, c, ts.Id, tr.Id, "")
+		}
+
+		if err != nil {
+			t.Errorf("%v: Change failed: %v", test.name, err)
+			continue
+		}
+
+		list := getRows[db.DailyReturn](mem)
+		if len(list) != 1 || !list[0].IsFromRuntime() {
+			t.Errorf("%v: The runtime daily return must be kept: %+v", test.name, list)
+			continue
+		}
+
+		if list[0].GrossProfit != test.expected || list[0].Trades != test.trades {
+			t.Errorf("%v: Bad runtime daily return. Expected %v (%v trades) but got %v (%v trades)", test.name, test.expected, test.trades, list[0].GrossProfit, list[0].Trades)
+		}
+	}
+}
+
+//=============================================================================
@@ -0,0 +1,155 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"encoding/json"
+	"mime/multipart"
+
+	"github.com/tradalia/core/auth"
+	"github.com/tradalia/core/req"
+	"github.com/tradalia/portfolio-trader/pkg/business/tradeimport"
+	"github.com/tradalia/portfolio-trader/pkg/core"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+//--- Mapping is an optional JSON (see tradeimport.Mapping) that overrides the format's
+//--- columns. Timezone is the timezone of the dates in the file ("exchange" by default).
+//--- Symbol is the instrument used to generate the trades (see the roll calendar).
+//--- Trades deleted or edited by the user are not imported again, unless Restore is true
+
+type TradeImportRequest struct {
+	File     *multipart.FileHeader `form:"file"     binding:"required"`
+	Format   string                `form:"format"   binding:"required"`
+	Mapping  string                `form:"mapping"`
+	Timezone string                `form:"timezone"`
+	Symbol   string                `form:"symbol"`
+	Restore  bool                  `form:"restore"`
+}
+
+//-----------------------------------------------------------------------------
+
+type TradeImportResponse struct {
+	Parsed    int `json:"parsed"`
+	Added     int `json:"added"`
+	Skipped   int `json:"skipped"`
+	Blocked   int `json:"blocked"`
+	Replaced  int `json:"replaced"`
+	Discarded int `json:"discarded"`
+	Conflicts int `json:"conflicts"`
+}
+
+//=============================================================================
+
+func ImportTrades(tx *gorm.DB, c *auth.Context, tsId uint, tir *TradeImportRequest) (*TradeImportResponse, error) {
+	c.Log.Info("ImportTrades: Importing trades into trading system", "id", tsId, "format", tir.Format, "file", tir.File.Filename)
+
+	ts, err := getTradingSystemAndCheckAccess(tx, c, tsId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !tradeimport.IsValidFormat(tir.Format) {
+		return nil, req.NewBadRequestError("Unknown format: %s", tir.Format)
+	}
+
+	var custom *tradeimport.Mapping
+	if tir.Mapping != "" {
+		custom = &tradeimport.Mapping{}
+		err = json.Unmarshal([]byte(tir.Mapping), custom)
+		if err != nil {
+			return nil, req.NewBadRequestError("Bad mapping: %v", err)
+		}
+	}
+
+	timezone := tir.Timezone
+	if timezone == "" {
+		timezone = "exchange"
+	}
+
+	loc, err := core.GetLocation(timezone, ts)
+	if err != nil {
+		return nil, req.NewBadRequestError("Bad timezone: %s", timezone)
+	}
+
+	//--- Parse file
+
+	file, err := tir.File.Open()
+	if err != nil {
+		return nil, req.NewServerErrorByError(err)
+	}
+	defer file.Close()
+
+	newTrades, err := tradeimport.Parse(file, tradeimport.GetMapping(tir.Format, custom), loc)
+	if err != nil {
+		c.Log.Error("ImportTrades: Cannot parse file", "id", tsId, "error", err)
+		return nil, err
+	}
+
+	for _, tr := range newTrades {
+		tr.Symbol = tir.Symbol
+	}
+
+	//--- Merge trades and regenerate the daily returns of the imported days. Daily returns
+	//--- sent by the runtime or marked to market are kept
+
+	trades, err := db.FindTradesByTradingSystemId(tx, tsId)
+	if err != nil {
+		return nil, err
+	}
+
+	trades, mr, err := MergeTrades(tx, ts, trades, newTrades, tir.Restore)
+	if err != nil {
+		return nil, err
+	}
+
+	if mr.Added > 0 || mr.Replaced > 0 {
+		_, err = UpdateDailyReturns(tx, ts, trades, GetTradeDays(ts, newTrades))
+		if err != nil {
+			return nil, err
+		}
+
+		err = db.UpdateTradingSystem(tx, ts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c.Log.Info("ImportTrades: Trades imported", "id", tsId, "parsed", len(newTrades), "added", mr.Added, "blocked", mr.Blocked, "conflicts", mr.Conflicts)
+
+	return &TradeImportResponse{
+		Parsed   : len(newTrades),
+		Added    : mr.Added,
+		Skipped  : mr.Duplicates,
+		Blocked  : mr.Blocked,
+		Replaced : mr.Replaced,
+		Discarded: mr.Discarded,
+		Conflicts: mr.Conflicts,
+	}, nil
+}
+
+//=============================================================================
@@ -0,0 +1,320 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/tradalia/portfolio-trader/pkg/consts"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+//--- Blocked trades are the ones deleted or edited by the user, that are not added again
+
+type MergeReport struct {
+	Added      int `json:"added"`
+	Duplicates int `json:"duplicates"`
+	Blocked    int `json:"blocked"`
+	Replaced   int `json:"replaced"`
+	Discarded  int `json:"discarded"`
+	Conflicts  int `json:"conflicts"`
+}
+
+//=============================================================================
+//--- Adds new trades to the trading system, skipping duplicates. Used both by the
+//--- runtime listener and by the trade import. Returns the full list of trades, sorted
+//--- by entry date.
+//--- Example: we have @NQ and we run the strategy on the full period to get lots of data.
+//--- Then, when switching to live, the instrument will switch to something like @NQM25 for roughly
+//--- 180 days. @NQ and @NQM25 are slightly different and there will be near duplicates between
+//--- them. The roll calendar of the data symbol tells which source is authoritative for each
+//--- period: its trades win, the others are discarded. Near duplicates that cannot be decided
+//--- using the calendar are not added and are reported as conflicts, for review.
+//--- Trades deleted or edited by the user are blocked, unless restoreRemoved is true (i.e.
+//--- the user imports them again on purpose)
+
+func MergeTrades(tx *gorm.DB, ts *db.TradingSystem, trades *[]db.Trade, newTrades []*db.Trade, restoreRemoved bool) (*[]db.Trade, *MergeReport, error) {
+	periods, err := db.FindRollPeriodsByDataSymbol(tx, ts.Username, ts.DataSymbol)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conflicts, err := db.FindTradeConflictsByTradingSystemId(tx, ts.Id, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	//--- Trades already reported must not be reported again when the runtime sends them
+
+	var knownKeys []string
+	for _, tc := range *conflicts {
+		knownKeys = append(knownKeys, tc.TradeKey)
+	}
+
+	var removedKeys []string
+
+	if !restoreRemoved {
+		audits, err := db.FindTradeAuditsByActions(tx, ts.Id, []string{ db.TradeAuditActionUpdate, db.TradeAuditActionDelete, db.TradeAuditActionDeleteAll })
+		if err != nil {
+			return nil, nil, err
+		}
+
+		removedKeys = getRemovedTradeKeys(audits)
+	}
+
+	return mergeTrades(tx, ts, trades, newTrades, periods, knownKeys, removedKeys)
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+//--- Trades whose key is in knownKeys are skipped as duplicates, the ones in removedKeys
+//--- are skipped as blocked
+
+func mergeTrades(tx *gorm.DB, ts *db.TradingSystem, trades *[]db.Trade, newTrades []*db.Trade, periods *[]db.RollPeriod, knownKeys []string, removedKeys []string) (*[]db.Trade, *MergeReport, error) {
+	list := *trades
+	rep  := &MergeReport{}
+
+	tradeSet := map[string]bool{}
+	for _, dbt := range *trades {
+		tradeSet[dbt.String()] = true
+	}
+
+	for _, key := range knownKeys {
+		tradeSet[key] = true
+	}
+
+	removedSet := map[string]bool{}
+	for _, key := range removedKeys {
+		removedSet[key] = true
+	}
+
+	var err error
+
+	for _, dbTr := range newTrades {
+		key := dbTr.String()
+		if tradeSet[key] {
+			rep.Duplicates++
+			continue
+		}
+
+		if removedSet[key] {
+			rep.Blocked++
+			continue
+		}
+
+		tradeSet[key]        = true
+		dbTr.TradingSystemId = ts.Id
+		authSymbol          := getAuthoritativeSymbol(periods, dbTr.ExitDate)
+		index               := findNearDuplicate(ts, list, dbTr)
+
+		if index == -1 {
+			if authSymbol != "" && dbTr.Symbol != "" && dbTr.Symbol != authSymbol {
+				err = addTradeConflict(tx, ts, nil, dbTr, db.TradeConflictTypeNotAuthoritative, db.TradeConflictStatusDiscarded)
+				rep.Discarded++
+			} else {
+				err = db.AddTrade(tx, dbTr)
+				list = append(list, *dbTr)
+				rep.Added++
+			}
+		} else {
+			old := &list[index]
+
+			if authSymbol != "" && dbTr.Symbol == authSymbol && old.Symbol != authSymbol {
+				err = addTradeConflict(tx, ts, old, dbTr, db.TradeConflictTypeNearDuplicate, db.TradeConflictStatusReplaced)
+				if err == nil {
+					replaceTrade(old, dbTr)
+					err = db.UpdateTrade(tx, old)
+				}
+				rep.Replaced++
+			} else if authSymbol != "" && old.Symbol == authSymbol {
+				err = addTradeConflict(tx, ts, old, dbTr, db.TradeConflictTypeNearDuplicate, db.TradeConflictStatusDiscarded)
+				rep.Discarded++
+			} else {
+				err = addTradeConflict(tx, ts, old, dbTr, db.TradeConflictTypeNearDuplicate, db.TradeConflictStatusPending)
+				rep.Conflicts++
+			}
+		}
+
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	//--- Sort final list as new trades could be in the past
+
+	sort.Slice(list, func(i,j int) bool {
+		return list[i].EntryDate.Before(*list[j].EntryDate)
+	})
+
+	updateFirstLastTrade(ts, &list)
+
+	return &list, rep, nil
+}
+
+//=============================================================================
+//--- Trades deleted or edited by the user must not come back when the runtime sends
+//--- them again. Deleting all trades starts from scratch
+
+func getRemovedTradeKeys(audits *[]db.TradeAudit) []string {
+	var keys []string
+
+	for _, ta := range *audits {
+		if ta.Action == db.TradeAuditActionDeleteAll {
+			keys = nil
+		} else if ta.TradeKey != "" {
+			keys = append(keys, ta.TradeKey)
+		}
+	}
+
+	return keys
+}
+
+//=============================================================================
+//--- It is better to use the exit date for first/last trade because a trade could last
+//--- for 7+ days and the IDLE flag is impacted
+
+func updateFirstLastTrade(ts *db.TradingSystem, trades *[]db.Trade) {
+	ts.FirstTrade = nil
+	ts.LastTrade  = nil
+
+	for _, tr := range *trades {
+		if tr.Excluded {
+			continue
+		}
+
+		if ts.FirstTrade == nil || ts.FirstTrade.After(*tr.ExitDate) {
+			ts.FirstTrade = tr.ExitDate
+		}
+
+		if ts.LastTrade == nil || ts.LastTrade.Before(*tr.ExitDate) {
+			ts.LastTrade = tr.ExitDate
+		}
+	}
+}
+
+//=============================================================================
+//--- Returns the symbol that must provide the trades at the given time, or "" if any
+//--- symbol is fine
+
+func getAuthoritativeSymbol(periods *[]db.RollPeriod, t *time.Time) string {
+	for _, rp := range *periods {
+		if !t.Before(*rp.FromDate) && !t.After(*rp.ToDate) {
+			return rp.Symbol
+		}
+	}
+
+	return ""
+}
+
+//=============================================================================
+
+func findNearDuplicate(ts *db.TradingSystem, trades []db.Trade, t *db.Trade) int {
+	maxDelta  := time.Duration(max(ts.Timeframe, 1)) * time.Minute
+	maxProfit := consts.NearDuplicateTicks * ts.Increment * ts.PointValue * float64(t.Contracts)
+
+	for i, tr := range trades {
+		if tr.TradeType != t.TradeType || tr.Contracts != t.Contracts {
+			continue
+		}
+
+		if absDuration(tr.EntryDate.Sub(*t.EntryDate)) > maxDelta || absDuration(tr.ExitDate.Sub(*t.ExitDate)) > maxDelta {
+			continue
+		}
+
+		if math.Abs(tr.GrossProfit - t.GrossProfit) <= maxProfit {
+			return i
+		}
+	}
+
+	return -1
+}
+
+//=============================================================================
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+
+	return d
+}
+
+//=============================================================================
+//--- Keeps the identity of the old trade (id, exclusion and broker info)
+
+func replaceTrade(old *db.Trade, t *db.Trade) {
+	old.EntryDate             = t.EntryDate
+	old.EntryPrice            = t.EntryPrice
+	old.EntryLabel            = t.EntryLabel
+	old.ExitDate              = t.ExitDate
+	old.ExitPrice             = t.ExitPrice
+	old.ExitLabel             = t.ExitLabel
+	old.GrossProfit           = t.GrossProfit
+	old.MaxAdverseExcursion   = t.MaxAdverseExcursion
+	old.MaxFavorableExcursion = t.MaxFavorableExcursion
+	old.Origin                = t.Origin
+	old.Symbol                = t.Symbol
+}
+
+//=============================================================================
+
+func addTradeConflict(tx *gorm.DB, ts *db.TradingSystem, old *db.Trade, t *db.Trade, conflictType string, status string) error {
+	now := time.Now().UTC()
+
+	tc := &db.TradeConflict{
+		TradingSystemId: ts.Id,
+		Timestamp      : &now,
+		ConflictType   : conflictType,
+		Status         : status,
+		Symbol         : t.Symbol,
+		TradeKey       : t.String(),
+		NewValue       : toJson(t),
+	}
+
+	if old != nil {
+		tc.TradeId  = &old.Id
+		tc.OldValue = toJson(old)
+	}
+
+	return db.AddTradeConflict(tx, tc)
+}
+
+//=============================================================================
+
+func toJson(tr *db.Trade) string {
+	data, _ := json.Marshal(tr)
+	return string(data)
+}
+
+//=============================================================================
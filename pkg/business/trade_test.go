@@ -0,0 +1,447 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package business
+
+import (
+	"log/slog"
+	"maps"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tradalia/core/auth"
+	"github.com/tradalia/core/datatype"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+var mergeTs = &db.TradingSystem{
+	Id        : 1,
+	Timeframe : 5,
+	Increment : 0.25,
+	PointValue: 20,
+}
+
+//=============================================================================
+
+func TestGetAuthoritativeSymbol(t *testing.T) {
+	periods := &[]db.RollPeriod{
+		{ Symbol: "NQM25", FromDate: date(2025, 3, 14), ToDate: date(2025, 6, 13) },
+		{ Symbol: "NQU25", FromDate: date(2025, 6, 14), ToDate: date(2025, 9, 12) },
+	}
+
+	tests := []struct {
+		time   *time.Time
+		symbol string
+	}{
+		{ date(2025, 1,  1), ""      },
+		{ date(2025, 3, 14), "NQM25" },
+		{ date(2025, 5,  1), "NQM25" },
+		{ date(2025, 6, 13), "NQM25" },
+		{ date(2025, 6, 14), "NQU25" },
+		{ date(2025, 9, 13), ""      },
+	}
+
+	for _, test := range tests {
+		symbol := getAuthoritativeSymbol(periods, test.time)
+		if symbol != test.symbol {
+			t.Errorf("Bad symbol for %v. Expected '%v' but got '%v'", test.time, test.symbol, symbol)
+		}
+	}
+}
+
+//=============================================================================
+
+func TestFindNearDuplicate(t *testing.T) {
+	trades := []db.Trade{
+		*newTrade(date(2025, 1, 2), 100, 1, ""),
+		*newTrade(date(2025, 1, 5), 200, 1, ""),
+	}
+
+	tests := []struct {
+		name  string
+		trade *db.Trade
+		index int
+	}{
+		{ "same trade",          newTrade(date(2025, 1, 5), 200, 1, ""),                    1 },
+		{ "profit within ticks", newTrade(date(2025, 1, 5), 215, 1, ""),                    1 },
+		{ "profit too far",      newTrade(date(2025, 1, 5), 230, 1, ""),                   -1 },
+		{ "time within bar",     newTrade(addMinutes(date(2025, 1, 2), 4), 100, 1, ""),     0 },
+		{ "time too far",        newTrade(addMinutes(date(2025, 1, 2), 6), 100, 1, ""),    -1 },
+		{ "other contracts",     newTrade(date(2025, 1, 2), 100, 2, ""),                   -1 },
+	}
+
+	for _, test := range tests {
+		index := findNearDuplicate(mergeTs, trades, test.trade)
+		if index != test.index {
+			t.Errorf("%v: Expected %v but got %v", test.name, test.index, index)
+		}
+	}
+
+	short := newTrade(date(2025, 1, 2), 100, 1, "")
+	short.TradeType = db.TradeTypeShort
+
+	if index := findNearDuplicate(mergeTs, trades, short); index != -1 {
+		t.Errorf("other type: Expected -1 but got %v", index)
+	}
+}
+
+//=============================================================================
+
+func TestGetRemovedTradeKeys(t *testing.T) {
+	audits := &[]db.TradeAudit{
+		{ Action: db.TradeAuditActionDelete,    TradeKey: "a" },
+		{ Action: db.TradeAuditActionDeleteAll                },
+		{ Action: db.TradeAuditActionDelete,    TradeKey: "b" },
+		{ Action: db.TradeAuditActionUpdate,    TradeKey: "c" },
+	}
+
+	keys := getRemovedTradeKeys(audits)
+	if len(keys) != 2 || keys[0] != "b" || keys[1] != "c" {
+		t.Errorf("Bad keys. Expected [b c] but got %v", keys)
+	}
+}
+
+//=============================================================================
+
+func TestMergeTrades(t *testing.T) {
+	tx := newDryRunDb(t)
+
+	periods := &[]db.RollPeriod{
+		{ Symbol: "NQM25", FromDate: date(2025, 3, 14), ToDate: date(2025, 6, 13) },
+	}
+
+	trades := &[]db.Trade{
+		*newTrade(date(2025, 1, 10), 100, 1, "NQ"),
+		*newTrade(date(2025, 2, 10), 100, 1, "NQ"),
+		*newTrade(date(2025, 4, 10), 100, 1, "NQ"),
+		*newTrade(date(2025, 5, 10), 100, 1, "NQM25"),
+	}
+
+	deleted := newTrade(date(2025, 1, 20), 300, 1, "NQ")
+
+	newTrades := []*db.Trade{
+		newTrade(date(2025, 1, 10), 100, 1, "NQ"),
+		deleted,
+		newTrade(date(2024, 12, 1), 50, 1, "NQ"),
+		newTrade(date(2025, 2, 10), 110, 1, "NQ"),
+		newTrade(date(2025, 4, 10), 105, 1, "NQM25"),
+		newTrade(date(2025, 5, 10), 105, 1, "NQ"),
+		newTrade(date(2025, 5, 20), 100, 1, "NQ"),
+	}
+
+	list, rep, err := mergeTrades(tx, mergeTs, trades, newTrades, periods, nil, []string{ deleted.String() })
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	expected := MergeReport{ Added: 1, Duplicates: 1, Blocked: 1, Replaced: 1, Discarded: 2, Conflicts: 1 }
+	if *rep != expected {
+		t.Errorf("Bad report. Expected %+v but got %+v", expected, *rep)
+	}
+
+	if len(*list) != 5 {
+		t.Fatalf("Bad number of trades. Expected 5 but got %v", len(*list))
+	}
+
+	if !(*list)[0].EntryDate.Equal(*date(2024, 12, 1)) {
+		t.Errorf("Trades are not sorted: first trade is %v", (*list)[0].EntryDate)
+	}
+
+	if (*list)[3].Symbol != "NQM25" || (*list)[3].GrossProfit != 105 {
+		t.Errorf("Trade was not replaced by the authoritative one: %v", (*list)[3])
+	}
+
+	if !mergeTs.FirstTrade.Equal(*(*list)[0].ExitDate) || !mergeTs.LastTrade.Equal(*(*list)[4].ExitDate) {
+		t.Errorf("Bad first/last trade: %v, %v", mergeTs.FirstTrade, mergeTs.LastTrade)
+	}
+}
+
+//=============================================================================
+
+func TestMergeTradesRestore(t *testing.T) {
+	tests := []struct {
+		name    string
+		restore bool
+		added   int
+		blocked int
+	}{
+		{ "runtime", false, 0, 1 },
+		{ "restore", true,  1, 0 },
+	}
+
+	for _, test := range tests {
+		tx, mem := newMemoryDb(t)
+
+		ts := &db.TradingSystem{ Id: 1, Username: "user" }
+		tr := newTrade(date(2025, 1, 10), 100, 1, "NQ")
+
+		mem.add(&db.TradeAudit{ TradingSystemId: ts.Id, TradeKey: tr.String(), Action: db.TradeAuditActionDelete })
+
+		_, rep, err := MergeTrades(tx, ts, &[]db.Trade{}, []*db.Trade{ tr }, test.restore)
+		if err != nil {
+			t.Errorf("%v: Merge failed: %v", test.name, err)
+			continue
+		}
+
+		if rep.Added != test.added || rep.Blocked != test.blocked {
+			t.Errorf("%v: Bad report. Expected %v added and %v blocked but got %+v", test.name, test.added, test.blocked, *rep)
+		}
+	}
+}
+
+//=============================================================================
+
+func TestResolveTradeConflict(t *testing.T) {
+	tests := []struct {
+		name     string
+		action   string
+		trade    *db.Trade
+		expected map[datatype.IntDate]float64
+	}{
+		{ "replace on same day",  ConflictActionReplace, newTrade(date(2025, 1, 10), 150, 1, "NQ"), map[datatype.IntDate]float64{ 20250105: 50, 20250110: 150 } },
+		{ "replace on other day", ConflictActionReplace, newTrade(date(2025, 1, 12), 150, 1, "NQ"), map[datatype.IntDate]float64{ 20250105: 50, 20250112: 150 } },
+		{ "add",                  ConflictActionAdd,     newTrade(date(2025, 1, 10),  30, 1, "NQ"), map[datatype.IntDate]float64{ 20250105: 50, 20250110: 130 } },
+	}
+
+	for _, test := range tests {
+		tx, mem := newMemoryDb(t)
+
+		ts := &db.TradingSystem{ Id: 1, Username: "user" }
+		tr := newTrade(date(2025, 1, 10), 100, 1, "NQ")
+		tr.TradingSystemId = ts.Id
+
+		mem.add(ts, tr)
+		mem.add(
+			&db.DailyReturn{ TradingSystemId: ts.Id, Day: 20250105, GrossProfit: 50,  Trades: 1, Origin: db.DailyReturnOriginTrades },
+			&db.DailyReturn{ TradingSystemId: ts.Id, Day: 20250110, GrossProfit: 100, Trades: 1, Origin: db.DailyReturnOriginTrades },
+			&db.TradeConflict{ TradingSystemId: ts.Id, TradeId: &tr.Id, Status: db.TradeConflictStatusPending, NewValue: toJson(test.trade) },
+		)
+
+		_, err := ResolveTradeConflict(tx, newTestContext(), ts.Id, 1, &TradeConflictResolveRequest{ Action: test.action })
+		if err != nil {
+			t.Errorf("%v: Resolution failed: %v", test.name, err)
+			continue
+		}
+
+		profits := map[datatype.IntDate]float64{}
+		for _, dr := range getRows[db.DailyReturn](mem) {
+			profits[dr.Day] += dr.GrossProfit
+		}
+
+		if !maps.Equal(profits, test.expected) {
+			t.Errorf("%v: Bad daily returns. Expected %v but got %v", test.name, test.expected, profits)
+		}
+	}
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func date(year int, month time.Month, day int) *time.Time {
+	t := time.Date(year, month, day, 10, 0, 0, 0, time.UTC)
+	return &t
+}
+
+//=============================================================================
+
+func addMinutes(t *time.Time, minutes int) *time.Time {
+	res := t.Add(time.Duration(minutes) * time.Minute)
+	return &res
+}
+
+//=============================================================================
+
+func newTrade(entry *time.Time, profit float64, contracts int, symbol string) *db.Trade {
+	exit := entry.Add(time.Hour)
+
+	return &db.Trade{
+		TradeType  : db.TradeTypeLong,
+		EntryDate  : entry,
+		ExitDate   : &exit,
+		GrossProfit: profit,
+		Contracts  : contracts,
+		Symbol     : symbol,
+	}
+}
+
+//=============================================================================
+//--- Statements are built but not executed, so no database is needed
+
+func newDryRunDb(t *testing.T) *gorm.DB {
+	dialector := mysql.New(mysql.Config{ SkipInitializeWithVersion: true })
+
+	tx, err := gorm.Open(dialector, &gorm.Config{ DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true })
+	if err != nil {
+		t.Fatalf("Cannot create database: %v", err)
+	}
+
+	return tx
+}
+
+//=============================================================================
+
+//=============================================================================
+
+func newTestContext() *auth.Context {
+	return &auth.Context{
+		Session: &auth.UserSession{ Username: "user" },
+		Log    : slog.Default(),
+	}
+}
+
+//=============================================================================
+//--- A dry run database that keeps rows in memory. Queries return all rows of the
+//--- model, filtered by id only when the id is the condition
+
+type memoryDb struct {
+	rows   map[reflect.Type][]reflect.Value
+	lastId map[reflect.Type]uint64
+}
+
+//=============================================================================
+
+func newMemoryDb(t *testing.T) (*gorm.DB, *memoryDb) {
+	tx  := newDryRunDb(t)
+	mem := &memoryDb{ rows: map[reflect.Type][]reflect.Value{}, lastId: map[reflect.Type]uint64{} }
+
+	_ = tx.Callback().Query() .After("gorm:query") .Register("test:query",  mem.query)
+	_ = tx.Callback().Create().After("gorm:create").Register("test:create", mem.save)
+	_ = tx.Callback().Update().After("gorm:update").Register("test:update", mem.save)
+	_ = tx.Callback().Delete().After("gorm:delete").Register("test:delete", mem.delete)
+
+	return tx, mem
+}
+
+//=============================================================================
+
+func (m *memoryDb) add(models ...any) {
+	for _, model := range models {
+		m.store(reflect.ValueOf(model).Elem())
+	}
+}
+
+//=============================================================================
+
+func (m *memoryDb) store(v reflect.Value) {
+	id := v.FieldByName("Id")
+
+	if id.Uint() == 0 {
+		id.SetUint(m.lastId[v.Type()] +1)
+	}
+
+	m.lastId[v.Type()] = max(m.lastId[v.Type()], id.Uint())
+
+	rows := m.rows[v.Type()]
+	for i, row := range rows {
+		if row.FieldByName("Id").Uint() == id.Uint() {
+			rows[i] = copyValue(v)
+			return
+		}
+	}
+
+	m.rows[v.Type()] = append(rows, copyValue(v))
+}
+
+//=============================================================================
+
+func (m *memoryDb) query(tx *gorm.DB) {
+	dest := reflect.ValueOf(tx.Statement.Dest).Elem()
+	if dest.Kind() != reflect.Slice {
+		return
+	}
+
+	id, byId := getIdCondition(tx)
+
+	for _, row := range m.rows[dest.Type().Elem()] {
+		if !byId || row.FieldByName("Id").Uint() == id {
+			dest.Set(reflect.Append(dest, copyValue(row)))
+		}
+	}
+}
+
+//=============================================================================
+
+func (m *memoryDb) save(tx *gorm.DB) {
+	dest := reflect.ValueOf(tx.Statement.Dest)
+	if dest.Kind() == reflect.Pointer && dest.Elem().Kind() == reflect.Struct {
+		m.store(dest.Elem())
+	}
+}
+
+//=============================================================================
+
+func (m *memoryDb) delete(tx *gorm.DB) {
+	rowType := reflect.TypeOf(tx.Statement.Dest).Elem()
+	id, byId := getIdCondition(tx)
+
+	var rows []reflect.Value
+	for _, row := range m.rows[rowType] {
+		if byId && row.FieldByName("Id").Uint() != id {
+			rows = append(rows, row)
+		}
+	}
+
+	m.rows[rowType] = rows
+}
+
+//=============================================================================
+
+func getRows[T any](m *memoryDb) []T {
+	var list []T
+	for _, row := range m.rows[reflect.TypeFor[T]()] {
+		list = append(list, row.Interface().(T))
+	}
+
+	return list
+}
+
+//=============================================================================
+
+func getIdCondition(tx *gorm.DB) (uint64, bool) {
+	if !strings.HasSuffix(tx.Statement.SQL.String(), ".`id` = ?") || len(tx.Statement.Vars) != 1 {
+		return 0, false
+	}
+
+	return reflect.ValueOf(tx.Statement.Vars[0]).Convert(reflect.TypeFor[uint64]()).Uint(), true
+}
+
+//=============================================================================
+
+func copyValue(v reflect.Value) reflect.Value {
+	res := reflect.New(v.Type()).Elem()
+	res.Set(v)
+	return res
+}
+
+//=============================================================================
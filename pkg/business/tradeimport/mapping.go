@@ -0,0 +1,214 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package tradeimport
+
+//=============================================================================
+//--- Supported formats. Each one is a preset mapping that can be partially
+//--- overridden by the caller
+
+const (
+	FormatTradeStation = "tradestation"
+	FormatMultiCharts  = "multicharts"
+	FormatNinjaTrader  = "ninjatrader"
+	FormatGeneric      = "generic"
+)
+
+//=============================================================================
+//--- Maps trade fields to the column names found in the file header. When Paired is
+//--- true, each trade is made of 2 rows (entry and exit) that share the same trade
+//--- number: dates, prices and labels are read from the Entry* columns on both rows,
+//--- the profit from the exit row and the contracts from the entry row.
+//--- If the time column is empty, the date column must contain date and time.
+//--- Layouts use the Go notation (i.e. "01/02/2006 15:04")
+
+type Mapping struct {
+	Separator   string   `json:"separator"`
+	Paired      bool     `json:"paired"`
+	DateLayout  string   `json:"dateLayout"`
+	TimeLayout  string   `json:"timeLayout"`
+	TradeNumber string   `json:"tradeNumber"`
+	TradeType   string   `json:"tradeType"`
+	EntryDate   string   `json:"entryDate"`
+	EntryTime   string   `json:"entryTime"`
+	EntryPrice  string   `json:"entryPrice"`
+	EntryLabel  string   `json:"entryLabel"`
+	ExitDate    string   `json:"exitDate"`
+	ExitTime    string   `json:"exitTime"`
+	ExitPrice   string   `json:"exitPrice"`
+	ExitLabel   string   `json:"exitLabel"`
+	GrossProfit string   `json:"grossProfit"`
+	Contracts   string   `json:"contracts"`
+	Mae         string   `json:"mae"`
+	Mfe         string   `json:"mfe"`
+	LongValues  []string `json:"longValues"`
+	ShortValues []string `json:"shortValues"`
+}
+
+//=============================================================================
+//--- Generic CSV: one trade per row, comma separated, with this header:
+//---   tradeType,entryDate,entryPrice,entryLabel,exitDate,exitPrice,exitLabel,grossProfit,contracts,mae,mfe
+//--- tradeType is LO/SH (or long/short), dates are "2006-01-02 15:04:05", labels, mae
+//--- and mfe are optional
+
+var presets = map[string]Mapping{
+	FormatGeneric: {
+		Separator  : ",",
+		DateLayout : "2006-01-02 15:04:05",
+		TradeType  : "tradeType",
+		EntryDate  : "entryDate",
+		EntryPrice : "entryPrice",
+		EntryLabel : "entryLabel",
+		ExitDate   : "exitDate",
+		ExitPrice  : "exitPrice",
+		ExitLabel  : "exitLabel",
+		GrossProfit: "grossProfit",
+		Contracts  : "contracts",
+		Mae        : "mae",
+		Mfe        : "mfe",
+		LongValues : []string{ "lo", "long" },
+		ShortValues: []string{ "sh", "short" },
+	},
+
+	//--- "List of Trades" from the strategy performance report
+
+	FormatTradeStation: {
+		Separator  : ",",
+		Paired     : true,
+		DateLayout : "01/02/2006 15:04",
+		TradeNumber: "#",
+		TradeType  : "Type",
+		EntryDate  : "Date/Time",
+		EntryPrice : "Price",
+		EntryLabel : "Signal",
+		GrossProfit: "Shares/Ctrts/Units - Profit/Loss",
+		Contracts  : "Shares/Ctrts/Units - Profit/Loss",
+		LongValues : []string{ "buy" },
+		ShortValues: []string{ "sell short" },
+	},
+
+	//--- "List of Trades" from the strategy performance report
+
+	FormatMultiCharts: {
+		Separator  : ",",
+		Paired     : true,
+		DateLayout : "01/02/2006",
+		TimeLayout : "15:04",
+		TradeNumber: "Trade #",
+		TradeType  : "Type",
+		EntryDate  : "Date",
+		EntryTime  : "Time",
+		EntryPrice : "Price",
+		EntryLabel : "Signal",
+		GrossProfit: "Profit",
+		Contracts  : "Contracts",
+		LongValues : []string{ "entry long",  "buy" },
+		ShortValues: []string{ "entry short", "sell short" },
+	},
+
+	//--- Trades grid export (NinjaTrader 8, US locale)
+
+	FormatNinjaTrader: {
+		Separator  : ",",
+		DateLayout : "1/2/2006 3:04:05 PM",
+		TradeType  : "Market pos.",
+		EntryDate  : "Entry time",
+		EntryPrice : "Entry price",
+		EntryLabel : "Entry name",
+		ExitDate   : "Exit time",
+		ExitPrice  : "Exit price",
+		ExitLabel  : "Exit name",
+		GrossProfit: "Profit",
+		Contracts  : "Qty",
+		Mae        : "MAE",
+		Mfe        : "MFE",
+		LongValues : []string{ "long" },
+		ShortValues: []string{ "short" },
+	},
+}
+
+//=============================================================================
+
+func IsValidFormat(format string) bool {
+	_, ok := presets[format]
+	return ok
+}
+
+//=============================================================================
+//--- Returns the preset mapping of the format, overridden by the non empty fields
+//--- of the custom mapping (if any)
+
+func GetMapping(format string, custom *Mapping) Mapping {
+	m := presets[format]
+
+	if custom == nil {
+		return m
+	}
+
+	override(&m.Separator,   custom.Separator)
+	override(&m.DateLayout,  custom.DateLayout)
+	override(&m.TimeLayout,  custom.TimeLayout)
+	override(&m.TradeNumber, custom.TradeNumber)
+	override(&m.TradeType,   custom.TradeType)
+	override(&m.EntryDate,   custom.EntryDate)
+	override(&m.EntryTime,   custom.EntryTime)
+	override(&m.EntryPrice,  custom.EntryPrice)
+	override(&m.EntryLabel,  custom.EntryLabel)
+	override(&m.ExitDate,    custom.ExitDate)
+	override(&m.ExitTime,    custom.ExitTime)
+	override(&m.ExitPrice,   custom.ExitPrice)
+	override(&m.ExitLabel,   custom.ExitLabel)
+	override(&m.GrossProfit, custom.GrossProfit)
+	override(&m.Contracts,   custom.Contracts)
+	override(&m.Mae,         custom.Mae)
+	override(&m.Mfe,         custom.Mfe)
+
+	if custom.Paired {
+		m.Paired = true
+	}
+
+	if len(custom.LongValues) > 0 {
+		m.LongValues = custom.LongValues
+	}
+
+	if len(custom.ShortValues) > 0 {
+		m.ShortValues = custom.ShortValues
+	}
+
+	return m
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func override(field *string, value string) {
+	if value != "" {
+		*field = value
+	}
+}
+
+//=============================================================================
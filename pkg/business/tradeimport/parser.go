@@ -0,0 +1,341 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package tradeimport
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tradalia/core/req"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+)
+
+//=============================================================================
+
+type parser struct {
+	mapping Mapping
+	loc     *time.Location
+	columns map[string]int
+	line    int
+}
+
+//=============================================================================
+//--- Parses a trade list. Dates are interpreted in the given location and returned in UTC
+
+func Parse(r io.Reader, m Mapping, loc *time.Location) ([]*db.Trade, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord  = -1
+	cr.LazyQuotes       = true
+	cr.TrimLeadingSpace = true
+
+	if m.Separator != "" {
+		cr.Comma = []rune(m.Separator)[0]
+	}
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, req.NewBadRequestError("Cannot read file header: %v", err)
+	}
+
+	p := &parser{
+		mapping: m,
+		loc    : loc,
+		columns: map[string]int{},
+		line   : 1,
+	}
+
+	for i, name := range header {
+		name = strings.TrimPrefix(name, "\ufeff")
+		p.columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	err = p.checkColumns()
+	if err != nil {
+		return nil, err
+	}
+
+	var trades []*db.Trade
+	var entry  []string
+
+	for {
+		row, err := cr.Read()
+		p.line++
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, p.error("Bad CSV format: %v", err)
+		}
+
+		if isEmpty(row) {
+			continue
+		}
+
+		if !m.Paired {
+			tr, err := p.buildTrade(row, row)
+			if err != nil {
+				return nil, err
+			}
+
+			trades = append(trades, tr)
+			continue
+		}
+
+		//--- Paired rows: the entry row is kept until the exit row arrives
+
+		if p.getTradeType(row) != "" {
+			entry = row
+			continue
+		}
+
+		if entry == nil {
+			return nil, p.error("Exit row without an entry row")
+		}
+
+		if m.TradeNumber != "" && p.get(entry, m.TradeNumber) != p.get(row, m.TradeNumber) {
+			return nil, p.error("Trade number of exit row does not match the entry row")
+		}
+
+		tr, err := p.buildTrade(entry, row)
+		if err != nil {
+			return nil, err
+		}
+
+		trades = append(trades, tr)
+		entry = nil
+	}
+
+	return trades, nil
+}
+
+//=============================================================================
+//===
+//=== Private methods
+//===
+//=============================================================================
+
+func (p *parser) checkColumns() error {
+	m := p.mapping
+	required := []string{ m.TradeType, m.EntryDate, m.EntryPrice, m.GrossProfit, m.Contracts }
+
+	if !m.Paired {
+		required = append(required, m.ExitDate, m.ExitPrice)
+	}
+
+	for _, col := range required {
+		if !p.hasColumn(col) {
+			return req.NewBadRequestError("Missing column in file header: '%s'", col)
+		}
+	}
+
+	return nil
+}
+
+//=============================================================================
+//--- For paired formats, the exit fields are read from the exit row using the entry columns
+
+func (p *parser) buildTrade(entryRow, exitRow []string) (*db.Trade, error) {
+	m := p.mapping
+
+	exitDate, exitTime, exitPrice, exitLabel := m.ExitDate, m.ExitTime, m.ExitPrice, m.ExitLabel
+	if m.Paired {
+		exitDate, exitTime, exitPrice, exitLabel = m.EntryDate, m.EntryTime, m.EntryPrice, m.EntryLabel
+	}
+
+	tr := &db.Trade{
+		TradeType : p.getTradeType(entryRow),
+		EntryLabel: p.get(entryRow, m.EntryLabel),
+		ExitLabel : p.get(exitRow,  exitLabel),
+		Origin    : db.TradeOriginBacktest,
+	}
+
+	if tr.TradeType == "" {
+		return nil, p.error("Unknown trade type: '%s'", p.get(entryRow, m.TradeType))
+	}
+
+	var err error
+
+	if tr.EntryDate, err = p.getDate(entryRow, m.EntryDate, m.EntryTime); err != nil {
+		return nil, err
+	}
+
+	if tr.ExitDate, err = p.getDate(exitRow, exitDate, exitTime); err != nil {
+		return nil, err
+	}
+
+	if tr.EntryPrice, err = p.getNumber(entryRow, m.EntryPrice); err != nil {
+		return nil, err
+	}
+
+	if tr.ExitPrice, err = p.getNumber(exitRow, exitPrice); err != nil {
+		return nil, err
+	}
+
+	if tr.GrossProfit, err = p.getNumber(exitRow, m.GrossProfit); err != nil {
+		return nil, err
+	}
+
+	contracts, err := p.getNumber(entryRow, m.Contracts)
+	if err != nil {
+		return nil, err
+	}
+
+	tr.Contracts = int(math.Abs(contracts))
+
+	if p.hasColumn(m.Mae) && p.hasColumn(m.Mfe) {
+		mae, err1 := p.getNumber(exitRow, m.Mae)
+		mfe, err2 := p.getNumber(exitRow, m.Mfe)
+
+		if err1 == nil && err2 == nil {
+			mae = math.Abs(mae)
+			mfe = math.Abs(mfe)
+			tr.MaxAdverseExcursion   = &mae
+			tr.MaxFavorableExcursion = &mfe
+		}
+	}
+
+	if tr.ExitDate.Before(*tr.EntryDate) {
+		return nil, p.error("Exit date is before entry date")
+	}
+
+	return tr, nil
+}
+
+//=============================================================================
+
+func (p *parser) getTradeType(row []string) string {
+	value := strings.ToLower(p.get(row, p.mapping.TradeType))
+
+	if slices.Contains(p.mapping.LongValues, value) {
+		return db.TradeTypeLong
+	}
+
+	if slices.Contains(p.mapping.ShortValues, value) {
+		return db.TradeTypeShort
+	}
+
+	return ""
+}
+
+//=============================================================================
+
+func (p *parser) getDate(row []string, dateCol, timeCol string) (*time.Time, error) {
+	value  := p.get(row, dateCol)
+	layout := p.mapping.DateLayout
+
+	if timeCol != "" {
+		value  = value  +" "+ p.get(row, timeCol)
+		layout = layout +" "+ p.mapping.TimeLayout
+	}
+
+	t, err := time.ParseInLocation(layout, value, p.loc)
+	if err != nil {
+		return nil, p.error("Bad date '%s' (expected layout is '%s')", value, layout)
+	}
+
+	t = t.UTC()
+	return &t, nil
+}
+
+//=============================================================================
+//--- Handles currency symbols, thousand separators and negative values in parenthesis
+
+func (p *parser) getNumber(row []string, col string) (float64, error) {
+	value := p.get(row, col)
+	clean := strings.NewReplacer("$", "", "€", "", "£", "", ",", "", " ", "").Replace(value)
+
+	negative := strings.HasPrefix(clean, "(") && strings.HasSuffix(clean, ")")
+	if negative {
+		clean = clean[1:len(clean) -1]
+	}
+
+	number, err := strconv.ParseFloat(clean, 64)
+	if err != nil {
+		return 0, p.error("Bad number in column '%s': '%s'", col, value)
+	}
+
+	if negative {
+		number = -number
+	}
+
+	return number, nil
+}
+
+//=============================================================================
+
+func (p *parser) get(row []string, col string) string {
+	idx, ok := p.columns[strings.ToLower(col)]
+	if !ok || col == "" || idx >= len(row) {
+		return ""
+	}
+
+	return strings.TrimSpace(row[idx])
+}
+
+//=============================================================================
+
+func (p *parser) hasColumn(col string) bool {
+	_, ok := p.columns[strings.ToLower(col)]
+	return ok && col != ""
+}
+
+//=============================================================================
+//--- The error is built directly because the message contains file values (i.e. a '%'
+//--- sign) and req.NewBadRequestError would use it as a format
+
+func (p *parser) error(format string, params ...any) error {
+	return req.AppError{
+		Code   : http.StatusBadRequest,
+		Message: fmt.Sprintf("Line %d: ", p.line) + fmt.Sprintf(format, params...),
+	}
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func isEmpty(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+//=============================================================================
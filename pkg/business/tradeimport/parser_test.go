@@ -0,0 +1,315 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package tradeimport
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tradalia/portfolio-trader/pkg/db"
+)
+
+//=============================================================================
+
+type expTrade struct {
+	tradeType   string
+	entryDate   string
+	exitDate    string
+	entryPrice  float64
+	exitPrice   float64
+	grossProfit float64
+	contracts   int
+}
+
+//=============================================================================
+
+func TestPresets(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   string
+		exp    []expTrade
+	}{
+		{
+			name  : "generic",
+			format: FormatGeneric,
+			data  : "tradeType,entryDate,entryPrice,entryLabel,exitDate,exitPrice,exitLabel,grossProfit,contracts\n"+
+					"LO,2024-01-02 09:30:00,4750.25,LE,2024-01-02 15:00:00,4760.25,LX,500,1\n"+
+					"\n"+
+					"short,2024-01-03 10:00:00,4770,SE,2024-01-04 11:00:00,4776,SX,(600),2\n",
+			exp   : []expTrade{
+				{ db.TradeTypeLong,  "2024-01-02 09:30", "2024-01-02 15:00", 4750.25, 4760.25,  500, 1 },
+				{ db.TradeTypeShort, "2024-01-03 10:00", "2024-01-04 11:00", 4770,    4776,    -600, 2 },
+			},
+		},
+		{
+			name  : "ninjatrader",
+			format: FormatNinjaTrader,
+			data  : "Trade number,Instrument,Market pos.,Qty,Entry price,Exit price,Entry time,Exit time,Entry name,Exit name,Profit,MAE,MFE\n"+
+					"1,ES 03-24,Long,1,4750.25,4760.25,1/2/2024 9:30:00 AM,1/2/2024 3:00:00 PM,Entry,Exit,$500.00,$100.00,$600.00\n"+
+					"2,ES 03-24,Short,2,4770.00,4776.00,1/3/2024 10:00:00 AM,1/4/2024 11:00:00 AM,Entry,Exit,($600.00),$700.00,$50.00\n",
+			exp   : []expTrade{
+				{ db.TradeTypeLong,  "2024-01-02 09:30", "2024-01-02 15:00", 4750.25, 4760.25,  500, 1 },
+				{ db.TradeTypeShort, "2024-01-03 10:00", "2024-01-04 11:00", 4770,    4776,    -600, 2 },
+			},
+		},
+		{
+			name  : "tradestation",
+			format: FormatTradeStation,
+			data  : "#,Type,Signal,Date/Time,Price,Shares/Ctrts/Units - Profit/Loss\n"+
+					"1,Buy,LE,01/02/2024 09:30,4750.25,1\n"+
+					"1,Sell,LX,01/02/2024 15:00,4760.25,$500.00\n"+
+					"2,Sell Short,SE,01/03/2024 10:00,4770.00,2\n"+
+					"2,Buy to Cover,SX,01/04/2024 11:00,4776.00,\"($1,200.00)\"\n",
+			exp   : []expTrade{
+				{ db.TradeTypeLong,  "2024-01-02 09:30", "2024-01-02 15:00", 4750.25, 4760.25,   500, 1 },
+				{ db.TradeTypeShort, "2024-01-03 10:00", "2024-01-04 11:00", 4770,    4776,    -1200, 2 },
+			},
+		},
+		{
+			name  : "multicharts",
+			format: FormatMultiCharts,
+			data  : "Trade #,Type,Date,Time,Signal,Price,Contracts,Profit\n"+
+					"1,Entry Long,01/02/2024,09:30,LE,4750.25,1,\n"+
+					"1,Exit Long,01/02/2024,15:00,LX,4760.25,1,$500.00\n"+
+					"2,Entry Short,01/03/2024,10:00,SE,4770.00,2,\n"+
+					"2,Exit Short,01/04/2024,11:00,SX,4776.00,2,($600.00)\n",
+			exp   : []expTrade{
+				{ db.TradeTypeLong,  "2024-01-02 09:30", "2024-01-02 15:00", 4750.25, 4760.25,  500, 1 },
+				{ db.TradeTypeShort, "2024-01-03 10:00", "2024-01-04 11:00", 4770,    4776,    -600, 2 },
+			},
+		},
+	}
+
+	for _, test := range tests {
+		trades, err := Parse(strings.NewReader(test.data), GetMapping(test.format, nil), time.UTC)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+
+		checkTrades(t, test.name, trades, test.exp)
+	}
+}
+
+//=============================================================================
+
+func TestLocation(t *testing.T) {
+	data := "tradeType,entryDate,entryPrice,exitDate,exitPrice,grossProfit,contracts\n"+
+			"LO,2024-01-02 09:30:00,100,2024-01-02 15:00:00,110,500,1\n"
+
+	loc := time.FixedZone("EST", -5*3600)
+
+	trades, err := Parse(strings.NewReader(data), GetMapping(FormatGeneric, nil), loc)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	checkTrades(t, "location", trades, []expTrade{
+		{ db.TradeTypeLong, "2024-01-02 14:30", "2024-01-02 20:00", 100, 110, 500, 1 },
+	})
+}
+
+//=============================================================================
+
+func TestCustomMapping(t *testing.T) {
+	data := "side;open;close;in;out;pnl;size\n"+
+			"B;02/01/2024 09:30;02/01/2024 15:00;100;110;500;-3\n"
+
+	custom := &Mapping{
+		Separator  : ";",
+		DateLayout : "02/01/2006 15:04",
+		TradeType  : "side",
+		EntryDate  : "open",
+		ExitDate   : "close",
+		EntryPrice : "in",
+		ExitPrice  : "out",
+		GrossProfit: "pnl",
+		Contracts  : "size",
+		LongValues : []string{ "b" },
+		ShortValues: []string{ "s" },
+	}
+
+	trades, err := Parse(strings.NewReader(data), GetMapping(FormatGeneric, custom), time.UTC)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	checkTrades(t, "custom", trades, []expTrade{
+		{ db.TradeTypeLong, "2024-01-02 09:30", "2024-01-02 15:00", 100, 110, 500, 3 },
+	})
+}
+
+//=============================================================================
+
+func TestGetNumber(t *testing.T) {
+	tests := []struct {
+		value string
+		exp   float64
+		fail  bool
+	}{
+		{ "1250",         1250,   false },
+		{ "-1250.5",     -1250.5, false },
+		{ "$1,250.50",    1250.5, false },
+		{ "€ 1,250",      1250,   false },
+		{ "£1,250",       1250,   false },
+		{ "(1,250.50)",  -1250.5, false },
+		{ "($75.00)",    -75,     false },
+		{ " 1 250 ",      1250,   false },
+		{ "",             0,      true  },
+		{ "12%",          0,      true  },
+		{ "(12",          0,      true  },
+	}
+
+	p := &parser{
+		columns: map[string]int{ "value": 0 },
+	}
+
+	for _, test := range tests {
+		number, err := p.getNumber([]string{ test.value }, "value")
+
+		if test.fail {
+			if err == nil {
+				t.Errorf("Expected error for '%s' but got %v", test.value, number)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Unexpected error for '%s': %v", test.value, err)
+		} else if number != test.exp {
+			t.Errorf("Bad number for '%s'. Expected %v but got %v", test.value, test.exp, number)
+		}
+	}
+}
+
+//=============================================================================
+
+func TestErrors(t *testing.T) {
+	header     := "tradeType,entryDate,entryPrice,exitDate,exitPrice,grossProfit,contracts\n"
+	headerTs   := "#,Type,Signal,Date/Time,Price,Shares/Ctrts/Units - Profit/Loss\n"
+	validRow   := "LO,2024-01-02 09:30:00,100,2024-01-02 15:00:00,110,500,1\n"
+
+	tests := []struct {
+		name   string
+		format string
+		data   string
+		exp    string
+	}{
+		{
+			name  : "missing column",
+			format: FormatGeneric,
+			data  : "tradeType,entryDate,entryPrice,exitDate,exitPrice,grossProfit\n",
+			exp   : "Missing column in file header",
+		},
+		{
+			name  : "unknown type",
+			format: FormatGeneric,
+			data  : header + validRow +"XX,2024-01-02 09:30:00,100,2024-01-02 15:00:00,110,500,1\n",
+			exp   : "Line 3: Unknown trade type: 'XX'",
+		},
+		{
+			name  : "bad number with percent",
+			format: FormatGeneric,
+			data  : header +"LO,2024-01-02 09:30:00,100,2024-01-02 15:00:00,110,5%d,1\n",
+			exp   : "Line 2: Bad number in column 'grossProfit': '5%d'",
+		},
+		{
+			name  : "bad date",
+			format: FormatGeneric,
+			data  : header +"LO,02/01/2024,100,2024-01-02 15:00:00,110,500,1\n",
+			exp   : "Line 2: Bad date '02/01/2024' (expected layout is '2006-01-02 15:04:05')",
+		},
+		{
+			name  : "exit before entry",
+			format: FormatGeneric,
+			data  : header +"LO,2024-01-02 15:00:00,100,2024-01-02 09:30:00,110,500,1\n",
+			exp   : "Line 2: Exit date is before entry date",
+		},
+		{
+			name  : "exit without entry",
+			format: FormatTradeStation,
+			data  : headerTs +"1,Sell,LX,01/02/2024 15:00,4760.25,$500.00\n",
+			exp   : "Line 2: Exit row without an entry row",
+		},
+		{
+			name  : "trade number mismatch",
+			format: FormatTradeStation,
+			data  : headerTs +"1,Buy,LE,01/02/2024 09:30,4750.25,1\n"+"2,Sell,LX,01/02/2024 15:00,4760.25,$500.00\n",
+			exp   : "Line 3: Trade number of exit row does not match the entry row",
+		},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(strings.NewReader(test.data), GetMapping(test.format, nil), time.UTC)
+
+		if err == nil {
+			t.Errorf("%s: expected error but got none", test.name)
+		} else if !strings.HasPrefix(err.Error(), test.exp) {
+			t.Errorf("%s: bad error. Expected '%s' but got '%s'", test.name, test.exp, err.Error())
+		}
+	}
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func checkTrades(t *testing.T, name string, trades []*db.Trade, exp []expTrade) {
+	if len(trades) != len(exp) {
+		t.Errorf("%s: bad number of trades. Expected %v but got %v", name, len(exp), len(trades))
+		return
+	}
+
+	for i, tr := range trades {
+		e := exp[i]
+
+		if tr.TradeType != e.tradeType {
+			t.Errorf("%s: trade %d: bad type. Expected %v but got %v", name, i, e.tradeType, tr.TradeType)
+		}
+
+		if tr.EntryDate.Format(time.DateTime)[:16] != e.entryDate || tr.ExitDate.Format(time.DateTime)[:16] != e.exitDate {
+			t.Errorf("%s: trade %d: bad dates. Expected %v - %v but got %v - %v", name, i, e.entryDate, e.exitDate, tr.EntryDate, tr.ExitDate)
+		}
+
+		if tr.EntryPrice != e.entryPrice || tr.ExitPrice != e.exitPrice {
+			t.Errorf("%s: trade %d: bad prices. Expected %v - %v but got %v - %v", name, i, e.entryPrice, e.exitPrice, tr.EntryPrice, tr.ExitPrice)
+		}
+
+		if tr.GrossProfit != e.grossProfit {
+			t.Errorf("%s: trade %d: bad profit. Expected %v but got %v", name, i, e.grossProfit, tr.GrossProfit)
+		}
+
+		if tr.Contracts != e.contracts {
+			t.Errorf("%s: trade %d: bad contracts. Expected %v but got %v", name, i, e.contracts, tr.Contracts)
+		}
+	}
+}
+
+//=============================================================================
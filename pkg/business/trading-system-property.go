@@ -25,6 +25,8 @@ THE SOFTWARE.
 package business
 
 import (
+	"time"
+
 	"github.com/tradalia/core/auth"
 	"github.com/tradalia/portfolio-trader/pkg/db"
 	"gorm.io/gorm"
@@ -136,6 +138,11 @@ func SetTradingSystemRunning(tx *gorm.DB, c *auth.Context, tsId uint, req *Tradi
 	}
 
 	ts.Running = newValue
+	if newValue {
+		now := time.Now().UTC()
+		ts.RunningSince = &now
+	}
+
 	updateStatus(ts)
 	err = db.UpdateTradingSystem(tx, ts)
 	if err != nil {
@@ -211,15 +218,11 @@ func SetTradingSystemActive(tx *gorm.DB, c *auth.Context, tsId uint, req *Tradin
 		}, nil
 	}
 
-	ts.Active = newValue
-	updateStatus(ts)
-	err = db.UpdateTradingSystem(tx, ts)
+	err = changeActive(tx, ts, newValue)
 	if err != nil {
 		return nil, err
 	}
 
-	err = updateRewind(ts)
-
 	c.Log.Info("SetTradingSystemActive: Active property changed", "id", tsId, "value", req.Value)
 
 	return &TradingSystemPropertyResponse{
@@ -235,7 +238,13 @@ func SetTradingSystemActive(tx *gorm.DB, c *auth.Context, tsId uint, req *Tradin
 //=============================================================================
 
 func updateStatus(ts *db.TradingSystem) {
-	ts.SuggestedAction = db.TsActionNone
+	//--- Only running systems are checked for divergence, so the flag must not outlive them
+
+	if ! ts.Running {
+		ts.Divergent = false
+	}
+
+	ts.SuggestedAction = ts.DefaultAction()
 
 	if ! ts.Running {
 		ts.Status = db.TsStatusOff
@@ -246,6 +255,19 @@ func updateStatus(ts *db.TradingSystem) {
 	}
 }
 
+//=============================================================================
+
+func changeActive(tx *gorm.DB, ts *db.TradingSystem, value bool) error {
+	ts.Active = value
+	updateStatus(ts)
+	err := db.UpdateTradingSystem(tx, ts)
+	if err != nil {
+		return err
+	}
+
+	return updateRewind(ts)
+}
+
 //============================================================================
 
 func updateRewind(ts *db.TradingSystem) error {
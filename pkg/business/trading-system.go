@@ -54,11 +54,26 @@ func DeleteTradingSystem(tx *gorm.DB, id uint) error {
 		return err
 	}
 
+	err = db.DeleteAllTradeAuditsByTradingSystemId(tx, id)
+	if err != nil {
+		return err
+	}
+
+	err = db.DeleteAllTradeConflictsByTradingSystemId(tx, id)
+	if err != nil {
+		return err
+	}
+
 	err = db.DeleteTradingFilter(tx, id)
 	if err != nil {
 		return err
 	}
 
+	err = db.DeleteAllExecutionsByTradingSystemId(tx, id)
+	if err != nil {
+		return err
+	}
+
 	return db.DeleteTradingSystem(tx, id)
 }
 
@@ -93,6 +108,21 @@ func DeleteTrades(tx *gorm.DB, c *auth.Context, id uint) error {
 		return err
 	}
 
+	err = db.UnmatchAllExecutionsByTradingSystemId(tx, id)
+	if err != nil {
+		return err
+	}
+
+	err = db.DeleteAllTradeConflictsByTradingSystemId(tx, id)
+	if err != nil {
+		return err
+	}
+
+	err = addTradeAudit(tx, c, ts, nil, "", db.TradeAuditActionDeleteAll, "", "", "")
+	if err != nil {
+		return err
+	}
+
 	ts.FirstTrade      = nil
 	ts.LastTrade       = nil
 	ts.LastNetProfit   = 0
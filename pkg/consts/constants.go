@@ -31,3 +31,35 @@ const IdleDays   = 14
 const BrokenDays = 30
 
 //=============================================================================
+//--- Average number of days in a month (365.25 / 12)
+
+const DaysPerMonth = 30.4375
+
+//=============================================================================
+//--- Max distance (in minutes) between a strategy order and a broker execution to match them
+
+const ExecutionMatchMinutes = 15
+
+//=============================================================================
+//--- Thresholds used to detect a divergence between live and backtest trades.
+//--- The check starts only when there are enough live trades
+
+const DivergenceMinLiveTrades     = 20
+const DivergenceMaxPValue         = 0.05
+const DivergenceMinAvgTradeRatio  = 0.5
+const DivergenceMaxFrequencyDrift = 0.5
+
+//=============================================================================
+//--- Two trades with the same direction and size are near duplicates when their dates
+//--- differ by at most one bar and their profits by at most a few ticks
+
+const NearDuplicateTicks = 4
+
+//=============================================================================
+//--- Correlation analysis: default window (in days) of the rolling correlation and
+//--- threshold above which two systems are considered redundant
+
+const CorrelationRollingWindow = 60
+const CorrelationRedundancy    = 0.7
+
+//=============================================================================
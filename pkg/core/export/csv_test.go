@@ -0,0 +1,62 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package export
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+)
+
+//=============================================================================
+
+func TestWriteCsv(t *testing.T) {
+	var nilFloat *float64
+	value := 2.25
+	day   := time.Date(2025, 1, 2, 10, 30, 0, 0, time.UTC)
+
+	table := NewTable("Data", "text", "number", "pointer", "time")
+	table.AddRow(`a, "quoted"`, 1.5, &value, day)
+	table.AddRow("line\nbreak", math.NaN(), nilFloat, nil)
+	table.AddRow("", math.Inf(-1), 10, true)
+
+	var buf bytes.Buffer
+	if err := WriteCsv(&buf, table); err != nil {
+		t.Fatalf("Cannot write CSV: %v", err)
+	}
+
+	expected := "text,number,pointer,time\n"+
+				"\"a, \"\"quoted\"\"\",1.5,2.25,2025-01-02T10:30:00Z\n"+
+				"\"line\nbreak\",,,\n"+
+				",,10,true\n"
+
+	if buf.String() != expected {
+		t.Errorf("Bad CSV. Expected:\n%s\nbut got:\n%s", expected, buf.String())
+	}
+}
+
+//=============================================================================
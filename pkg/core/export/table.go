@@ -0,0 +1,164 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package export
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+//=============================================================================
+
+const (
+	FormatJson = "json"
+	FormatCsv  = "csv"
+	FormatXlsx = "xlsx"
+)
+
+//=============================================================================
+
+func IsValidFormat(format string) bool {
+	return format == FormatJson || format == FormatCsv || format == FormatXlsx
+}
+
+//=============================================================================
+//--- A table is exported as a CSV file or as a sheet of an XLSX workbook
+
+type Table struct {
+	Name   string
+	Header []string
+	Rows   [][]any
+}
+
+//=============================================================================
+
+func NewTable(name string, header ...string) *Table {
+	return &Table{
+		Name  : name,
+		Header: header,
+	}
+}
+
+//=============================================================================
+
+func (t *Table) AddRow(values ...any) {
+	t.Rows = append(t.Rows, values)
+}
+
+//=============================================================================
+
+func FindTable(tables []*Table, name string) *Table {
+	for _, t := range tables {
+		if t.Name == name {
+			return t
+		}
+	}
+
+	return nil
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+//--- Converts a cell to string. Pointers are dereferenced and nil values are empty
+
+func toString(value any) string {
+	switch v := value.(type) {
+		case nil:
+			return ""
+		case string:
+			return v
+		case float64:
+			return formatFloat(v)
+		case int:
+			return strconv.Itoa(v)
+		case int8:
+			return strconv.Itoa(int(v))
+		case uint:
+			return strconv.FormatUint(uint64(v), 10)
+		case bool:
+			return strconv.FormatBool(v)
+		case time.Time:
+			return v.Format(time.RFC3339)
+		case *time.Time:
+			if v == nil {
+				return ""
+			}
+			return v.Format(time.RFC3339)
+		case *float64:
+			if v == nil {
+				return ""
+			}
+			return formatFloat(*v)
+		case *uint:
+			if v == nil {
+				return ""
+			}
+			return strconv.FormatUint(uint64(*v), 10)
+		default:
+			return fmt.Sprint(v)
+	}
+}
+
+//=============================================================================
+
+//--- NaN and infinite values are not numbers, because they have no representation
+
+func isNumber(value any) bool {
+	switch v := value.(type) {
+		case float64:
+			return isFinite(v)
+		case int, int8, uint:
+			return true
+		case *float64:
+			return v != nil && isFinite(*v)
+		case *uint:
+			return v != nil
+		default:
+			return false
+	}
+}
+
+//=============================================================================
+
+func formatFloat(v float64) string {
+	if !isFinite(v) {
+		return ""
+	}
+
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+//=============================================================================
+
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+//=============================================================================
@@ -0,0 +1,266 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package export
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+//=============================================================================
+//--- Minimal XLSX (Office Open XML) writer: one sheet per table, inline strings and
+//--- numbers, no styles. Dates are written as RFC3339 strings
+
+const xlsxMaxSheetName = 31
+
+//=============================================================================
+
+func WriteXlsx(w io.Writer, tables []*Table) error {
+	zw := zip.NewWriter(w)
+
+	err := writeZipEntry(zw, "[Content_Types].xml", buildContentTypes(len(tables)))
+	if err == nil {
+		err = writeZipEntry(zw, "_rels/.rels", xlsxRootRels)
+		if err == nil {
+			err = writeZipEntry(zw, "xl/workbook.xml", buildWorkbook(tables))
+			if err == nil {
+				err = writeZipEntry(zw, "xl/_rels/workbook.xml.rels", buildWorkbookRels(len(tables)))
+			}
+		}
+	}
+
+	for i, t := range tables {
+		if err != nil {
+			break
+		}
+
+		err = writeZipEntry(zw, fmt.Sprintf("xl/worksheets/sheet%d.xml", i +1), buildSheet(t))
+	}
+
+	if err != nil {
+		_ = zw.Close()
+		return err
+	}
+
+	return zw.Close()
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+const xlsxHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+const xlsxRootRels = xlsxHeader +
+	`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+//=============================================================================
+
+func writeZipEntry(zw *zip.Writer, name string, content string) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(f, content)
+	return err
+}
+
+//=============================================================================
+
+func buildContentTypes(sheets int) string {
+	var sb strings.Builder
+
+	sb.WriteString(xlsxHeader)
+	sb.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	sb.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	sb.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	sb.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+
+	for i := 1; i <= sheets; i++ {
+		sb.WriteString(fmt.Sprintf(`<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i))
+	}
+
+	sb.WriteString(`</Types>`)
+
+	return sb.String()
+}
+
+//=============================================================================
+
+func buildWorkbook(tables []*Table) string {
+	var sb strings.Builder
+
+	sb.WriteString(xlsxHeader)
+	sb.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+
+	for i, name := range sheetNames(tables) {
+		sb.WriteString(fmt.Sprintf(`<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escape(name), i +1, i +1))
+	}
+
+	sb.WriteString(`</sheets></workbook>`)
+
+	return sb.String()
+}
+
+//=============================================================================
+
+func buildWorkbookRels(sheets int) string {
+	var sb strings.Builder
+
+	sb.WriteString(xlsxHeader)
+	sb.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+
+	for i := 1; i <= sheets; i++ {
+		sb.WriteString(fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i, i))
+	}
+
+	sb.WriteString(`</Relationships>`)
+
+	return sb.String()
+}
+
+//=============================================================================
+
+func buildSheet(t *Table) string {
+	var sb strings.Builder
+
+	sb.WriteString(xlsxHeader)
+	sb.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	header := make([]any, len(t.Header))
+	for i, h := range t.Header {
+		header[i] = h
+	}
+
+	writeRow(&sb, 1, header)
+
+	for i, row := range t.Rows {
+		writeRow(&sb, i +2, row)
+	}
+
+	sb.WriteString(`</sheetData></worksheet>`)
+
+	return sb.String()
+}
+
+//=============================================================================
+
+func writeRow(sb *strings.Builder, rowNum int, values []any) {
+	sb.WriteString(fmt.Sprintf(`<row r="%d">`, rowNum))
+
+	for col, value := range values {
+		ref := fmt.Sprintf("%s%d", columnName(col), rowNum)
+
+		if isNumber(value) {
+			sb.WriteString(fmt.Sprintf(`<c r="%s"><v>%s</v></c>`, ref, toString(value)))
+		} else {
+			text := toString(value)
+			if text != "" {
+				sb.WriteString(fmt.Sprintf(`<c r="%s" t="inlineStr"><is><t>%s</t></is></c>`, ref, escape(text)))
+			}
+		}
+	}
+
+	sb.WriteString(`</row>`)
+}
+
+//=============================================================================
+//--- 0 -> A, 25 -> Z, 26 -> AA, ...
+
+func columnName(col int) string {
+	name := ""
+
+	for col >= 0 {
+		name = string(rune('A' + col % 26)) + name
+		col  = col / 26 -1
+	}
+
+	return name
+}
+
+//=============================================================================
+//--- Sheet names must be unique (ignoring the case) and cannot be longer than 31
+//--- characters. Duplicates get a " (n)" suffix
+
+func sheetNames(tables []*Table) []string {
+	names := make([]string, len(tables))
+	used  := map[string]bool{}
+
+	for i, t := range tables {
+		name := sheetName(t.Name, i)
+
+		for n := 2; used[strings.ToLower(name)]; n++ {
+			suffix := fmt.Sprintf(" (%d)", n)
+			name = truncate(sheetName(t.Name, i), xlsxMaxSheetName - len(suffix)) + suffix
+		}
+
+		used[strings.ToLower(name)] = true
+		names[i] = name
+	}
+
+	return names
+}
+
+//=============================================================================
+
+func sheetName(name string, index int) string {
+	name = strings.NewReplacer("/", "-", "\\", "-", "?", "", "*", "", "[", "(", "]", ")", ":", "-").Replace(name)
+
+	if name == "" {
+		name = fmt.Sprintf("Sheet%d", index +1)
+	}
+
+	return truncate(name, xlsxMaxSheetName)
+}
+
+//=============================================================================
+//--- Truncates to the given number of characters, not bytes
+
+func truncate(s string, length int) string {
+	runes := []rune(s)
+	if len(runes) <= length {
+		return s
+	}
+
+	return string(runes[:length])
+}
+
+//=============================================================================
+
+func escape(s string) string {
+	var sb strings.Builder
+	_ = xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
+
+//=============================================================================
@@ -0,0 +1,202 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"math"
+	"strings"
+	"testing"
+)
+
+//=============================================================================
+
+func TestColumnName(t *testing.T) {
+	tests := []struct {
+		col  int
+		name string
+	}{
+		{   0, "A"   },
+		{  25, "Z"   },
+		{  26, "AA"  },
+		{  51, "AZ"  },
+		{  52, "BA"  },
+		{ 701, "ZZ"  },
+		{ 702, "AAA" },
+	}
+
+	for _, test := range tests {
+		if name := columnName(test.col); name != test.name {
+			t.Errorf("Bad column name for %d. Expected %s but got %s", test.col, test.name, name)
+		}
+	}
+}
+
+//=============================================================================
+
+func TestSheetNames(t *testing.T) {
+	long := strings.Repeat("è", 40)
+
+	tables := []*Table{
+		NewTable("Trades"),
+		NewTable("trades"),
+		NewTable("Trades"),
+		NewTable("a/b:c[1]?"),
+		NewTable(""),
+		NewTable(long),
+		NewTable(long),
+	}
+
+	expected := []string{
+		"Trades",
+		"trades (2)",
+		"Trades (3)",
+		"a-b-c(1)",
+		"Sheet5",
+		strings.Repeat("è", 31),
+		strings.Repeat("è", 27) +" (2)",
+	}
+
+	names := sheetNames(tables)
+
+	for i, name := range names {
+		if name != expected[i] {
+			t.Errorf("Bad sheet name %d. Expected '%s' but got '%s'", i, expected[i], name)
+		}
+	}
+}
+
+//=============================================================================
+
+func TestWriteXlsx(t *testing.T) {
+	t1 := NewTable("Data", "text", "number", "missing")
+	t1.AddRow("<a & b>", 1.5, math.NaN())
+	t1.AddRow(nil, math.Inf(1), math.Inf(-1))
+
+	wide := make([]string, 28)
+	for i := range wide {
+		wide[i] = "c"
+	}
+
+	t2 := NewTable("Data", wide...)
+
+	var buf bytes.Buffer
+	if err := WriteXlsx(&buf, []*Table{ t1, t2 }); err != nil {
+		t.Fatalf("Cannot write workbook: %v", err)
+	}
+
+	files := readZip(t, buf.Bytes())
+
+	for _, name := range []string{ "[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml", "xl/worksheets/sheet2.xml" } {
+		content, ok := files[name]
+		if !ok {
+			t.Errorf("Missing file in workbook: %s", name)
+			continue
+		}
+
+		if err := checkXml(content); err != nil {
+			t.Errorf("Bad XML in %s: %v", name, err)
+		}
+	}
+
+	workbook := files["xl/workbook.xml"]
+	if !strings.Contains(workbook, `name="Data"`) || !strings.Contains(workbook, `name="Data (2)"`) {
+		t.Errorf("Bad sheet names: %s", workbook)
+	}
+
+	sheet1 := files["xl/worksheets/sheet1.xml"]
+	if !strings.Contains(sheet1, `<c r="A2" t="inlineStr"><is><t>&lt;a &amp; b&gt;</t></is></c>`) {
+		t.Errorf("Text is not escaped: %s", sheet1)
+	}
+
+	if !strings.Contains(sheet1, `<c r="B2"><v>1.5</v></c>`) {
+		t.Errorf("Bad number cell: %s", sheet1)
+	}
+
+	if strings.Contains(sheet1, "NaN") || strings.Contains(sheet1, "Inf") || strings.Contains(sheet1, `r="C2"`) || strings.Contains(sheet1, `r="B3"`) {
+		t.Errorf("Not finite numbers must be empty cells: %s", sheet1)
+	}
+
+	sheet2 := files["xl/worksheets/sheet2.xml"]
+	if !strings.Contains(sheet2, `<c r="Z1" `) || !strings.Contains(sheet2, `<c r="AB1" `) {
+		t.Errorf("Bad column references past Z: %s", sheet2)
+	}
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func readZip(t *testing.T, data []byte) map[string]string {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Workbook is not a zip file: %v", err)
+	}
+
+	files := map[string]string{}
+
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("Cannot open %s: %v", f.Name, err)
+		}
+
+		content, err := io.ReadAll(rc)
+		_ = rc.Close()
+
+		if err != nil {
+			t.Fatalf("Cannot read %s: %v", f.Name, err)
+		}
+
+		files[f.Name] = string(content)
+	}
+
+	return files
+}
+
+//=============================================================================
+
+func checkXml(content string) error {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+//=============================================================================
@@ -0,0 +1,72 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package core
+
+import (
+	"github.com/tradalia/portfolio-trader/pkg/db"
+)
+
+//=============================================================================
+
+const LastStatsDays = 180
+
+//=============================================================================
+//--- Trades must be the ones of the last LastStatsDays days
+
+func UpdateLastStats(ts *db.TradingSystem, trades *[]db.Trade) {
+	grossProfit := 0.0
+	netProfit   := 0.0
+	numTrades   := 0
+
+	var grossEquity   []float64
+	//var grossDrawdown []float64
+	var netEquity     []float64
+	//var netDrawdown   []float64
+
+	for _, trade := range *trades {
+		grossProfit += trade.GrossProfit
+		netProfit   += trade.GrossProfit - 2 * ts.CostPerOperation
+		numTrades++
+
+		grossEquity   = append(grossEquity, grossProfit)
+		netEquity     = append(netEquity,   netProfit)
+		//grossDrawdown = append(grossDrawdown, 0)
+		//netDrawdown   = append(netDrawdown,   0)
+	}
+
+	//maxGrossDD := CalcDrawDown(&grossEquity, &grossDrawdown)
+	//maxNetDD   := CalcDrawDown(&netEquity,   &netDrawdown)
+
+	ts.LastNetProfit   = Trunc2d(netProfit)
+	ts.LastNumTrades   = numTrades
+	ts.LastNetAvgTrade = 0
+
+	if numTrades != 0 {
+		ts.LastNetAvgTrade = Trunc2d(netProfit / float64(numTrades))
+	}
+}
+
+//=============================================================================
@@ -27,11 +27,11 @@ package runtime
 import (
 	"encoding/json"
 	"log/slog"
-	"sort"
 	"time"
 
 	"github.com/tradalia/core/datatype"
 	"github.com/tradalia/core/msg"
+	"github.com/tradalia/portfolio-trader/pkg/business"
 	"github.com/tradalia/portfolio-trader/pkg/business/filter"
 	"github.com/tradalia/portfolio-trader/pkg/consts"
 	"github.com/tradalia/portfolio-trader/pkg/db"
@@ -102,11 +102,12 @@ func handleNewTrades(tm *TradeListMessage) bool {
 				var tf *db.TradingFilter
 				tf, err = db.GetTradingFilterByTsId(tx, tsId)
 				if err == nil {
-					trades,err = addNewTrades(tx, ts, trades, tm.Trades)
+					var changed bool
+					trades,changed,err = addNewTrades(tx, ts, trades, tm)
 					if err == nil {
-						err = addNewDailyProfits(tx, ts, dailyProfits, tm.DailyProfits)
+						err = updateDailyProfits(tx, ts, trades, changed, dailyProfits, tm.DailyProfits)
 						if err == nil {
-							err = updateTradingSystem(tx, ts, trades, tf)
+							err = updateTradingSystem(tx, ts, tf)
 						}
 					}
 				}
@@ -116,114 +117,146 @@ func handleNewTrades(tm *TradeListMessage) bool {
 		return err
 	})
 
+	if err == nil {
+		checkRiskRules(tsId)
+	}
+
 	return err == nil
 }
 
 //=============================================================================
+//--- Risk rules are checked in their own transaction: a failure must not cause the
+//--- trades to be processed again
 
-func addNewTrades(tx *gorm.DB, ts *db.TradingSystem, trades *[]db.Trade, newTrades []*TradeItem) (*[]db.Trade, error) {
-	list := *trades
-
-	tradeSet := map[string]bool{}
-	for _, dbt := range *trades {
-		tradeSet[dbt.String()] = true
-	}
-
-	firstTrade := ts.FirstTrade
-	lastTrade  := ts.LastTrade
+func checkRiskRules(tsId uint) {
+	var events []*db.RiskEvent
 
-	for _, tr := range newTrades {
-		dbTr := toDbTrade(ts.Id, tr)
-		_, exists := tradeSet[dbTr.String()]
-		if exists {
-			continue
+	err := db.RunInTransaction(func (tx *gorm.DB) error {
+		ts, err := db.GetTradingSystemById(tx, tsId)
+		if err != nil || ts == nil {
+			return err
 		}
 
-		//--- We need to add trades that are outside of [firstTrade .. lastTrade]
-		//--- because we will have duplicates when importing from external strategies
-		//--- Example: we have @NQ and we run the strategy on the full period to get lots of data.
-		//--- Then, when switching to live, the instrument will switch to something like @NQM25 for roughly
-		//--- 180 days. @NQ and @NQM25 are slightly different and there will be a jump in the continuous
-		//--- contract causing duplicates between @NQ and @NQM25 during the last 180 days
+		events, err = business.CheckTradingSystemRiskRules(tx, ts)
+		return err
+	})
 
-		if isTheTradeOutOfRange(ts, dbTr) {
-			tradeSet[dbTr.String()] = true
-			err  := db.AddTrade(tx, dbTr)
+	if err != nil {
+		slog.Error("checkRiskRules: Cannot check risk rules", "id", tsId, "error", err.Error())
+		return
+	}
 
-			if err != nil {
-				return nil, err
-			}
+	business.PublishRiskEvents(events)
+}
 
-			list = append(list, *dbTr)
+//=============================================================================
+//--- Returns true if trades have been added or replaced
 
-			//--- Update information on trading system
-			//--- It is better to use the exit date for first/last trade because a trade could last
-			//--- for 7+ days and the IDLE flag is impacted
+func addNewTrades(tx *gorm.DB, ts *db.TradingSystem, trades *[]db.Trade, tm *TradeListMessage) (*[]db.Trade, bool, error) {
+	var list []*db.Trade
 
-			if firstTrade == nil || firstTrade.After(*tr.ExitDate) {
-				firstTrade = tr.ExitDate
-			}
+	for _, tr := range tm.Trades {
+		list = append(list, toDbTrade(ts, tr, tm.Origin, tm.Symbol))
+	}
 
-			if lastTrade == nil || lastTrade.Before(*tr.ExitDate) {
-				lastTrade = tr.ExitDate
-			}
-		}
+	trades, mr, err := business.MergeTrades(tx, ts, trades, list, false)
+	if err != nil {
+		return nil, false, err
 	}
 
-	ts.FirstTrade = firstTrade
-	ts.LastTrade  = lastTrade
+	slog.Info("addNewTrades: Trades merged", "id", ts.Id, "added", mr.Added, "blocked", mr.Blocked, "replaced", mr.Replaced, "discarded", mr.Discarded, "conflicts", mr.Conflicts)
+
+	return trades, mr.Added > 0 || mr.Replaced > 0, nil
+}
 
-	//--- Sort final list as new trades could be in the past
+//=============================================================================
 
-	sort.Slice(list, func(i,j int) bool {
-		return list[i].EntryDate.Before(*list[j].EntryDate)
-	})
+func toDbTrade(ts *db.TradingSystem, t *TradeItem, origin string, symbol string) *db.Trade {
+	tr := &db.Trade{
+		TradingSystemId      : ts.Id,
+		TradeType            : t.TradeType,
+		EntryDate            : t.EntryDate,
+		EntryPrice           : t.EntryPrice,
+		EntryLabel           : t.EntryLabel,
+		ExitDate             : t.ExitDate,
+		ExitPrice            : t.ExitPrice,
+		ExitLabel            : t.ExitLabel,
+		GrossProfit          : t.GrossProfit,
+		Contracts            : t.Contracts,
+		MaxAdverseExcursion  : t.MaxAdverseExcursion,
+		MaxFavorableExcursion: t.MaxFavorableExcursion,
+		Origin               : origin,
+		Symbol               : symbol,
+	}
+
+	if tr.Origin == "" {
+		tr.Origin = db.TradeOriginBacktest
+		if tr.IsLive(ts) {
+			tr.Origin = db.TradeOriginLive
+		}
+	}
 
-	return &list, nil
+	return tr
 }
 
 //=============================================================================
+//--- If the runtime does not send daily profits, they are derived from trades. They are
+//--- rebuilt only if trades changed and the runtime never sent daily profits, otherwise
+//--- only the missing days are added. Runtime daily profits are never deleted
+
+func updateDailyProfits(tx *gorm.DB, ts *db.TradingSystem, trades *[]db.Trade, changed bool, profits *[]db.DailyReturn, newProfits []*DailyProfitItem) error {
+	if len(newProfits) > 0 {
+		return addNewDailyProfits(tx, ts, profits, newProfits)
+	}
 
-func isTheTradeOutOfRange(ts *db.TradingSystem, t *db.Trade) bool {
-	if ts.FirstTrade == nil || ts.LastTrade == nil {
-		return true
+	var err error
+
+	if changed && !hasRuntimeDailyProfits(profits) {
+		_, err = business.UpdateDailyReturns(tx, ts, trades, nil)
+	} else {
+		_, err = business.AddMissingDailyReturns(tx, ts, trades)
 	}
 
-	//--- Better to use the exit date (please, see above)
-	return t.ExitDate.Before(*ts.FirstTrade) || t.ExitDate.After(*ts.LastTrade)
+	return err
 }
 
 //=============================================================================
 
-func toDbTrade(tsId uint, t *TradeItem) *db.Trade {
-	return &db.Trade{
-		TradingSystemId: tsId,
-		TradeType      : t.TradeType,
-		EntryDate      : t.EntryDate,
-		EntryPrice     : t.EntryPrice,
-		EntryLabel     : t.EntryLabel,
-		ExitDate       : t.ExitDate,
-		ExitPrice      : t.ExitPrice,
-		ExitLabel      : t.ExitLabel,
-		GrossProfit    : t.GrossProfit,
-		Contracts      : t.Contracts,
+func hasRuntimeDailyProfits(profits *[]db.DailyReturn) bool {
+	for _, dp := range *profits {
+		if dp.IsFromRuntime() {
+			return true
+		}
 	}
+
+	return false
 }
 
 //=============================================================================
+//--- Daily profits from the runtime take the place of the ones derived from trades
 
 func addNewDailyProfits(tx *gorm.DB, ts *db.TradingSystem, profits *[]db.DailyReturn, newProfits []*DailyProfitItem) error {
-	profitSet := map[datatype.IntDate]bool{}
-	for _, dp := range *profits {
-		profitSet[dp.Day] = true
+	profitMap := map[datatype.IntDate]*db.DailyReturn{}
+	for i, dp := range *profits {
+		profitMap[dp.Day] = &(*profits)[i]
 	}
 
 	for _, dp := range newProfits {
 		dbDp := toDbDailyProfit(ts.Id, dp)
-		_, exists := profitSet[dbDp.Day]
+		curr, exists := profitMap[dbDp.Day]
+
+		if exists && !curr.IsFromRuntime() {
+			err := db.DeleteDailyReturn(tx, curr.Id)
+			if err != nil {
+				return err
+			}
+
+			exists = false
+		}
+
 		if !exists {
-			profitSet[dbDp.Day] = true
-			err  := db.AddDailyReturn(tx, dbDp)
+			profitMap[dbDp.Day] = dbDp
+			err := db.AddDailyReturn(tx, dbDp)
 
 			if err != nil {
 				return err
@@ -242,12 +275,19 @@ func toDbDailyProfit(tsId uint, p *DailyProfitItem) *db.DailyReturn {
 		Day            : p.Day,
 		GrossProfit    : p.GrossProfit,
 		Trades         : p.Trades,
+		Origin         : db.DailyReturnOriginRuntime,
 	}
 }
 
 //=============================================================================
+//--- Excluded trades must not drive the activation
+
+func updateTradingSystem(tx *gorm.DB, ts *db.TradingSystem, filter *db.TradingFilter) error {
+	trades, err := db.FindIncludedTradesByTradingSystemId(tx, ts.Id)
+	if err != nil {
+		return err
+	}
 
-func updateTradingSystem(tx *gorm.DB, ts *db.TradingSystem, trades *[]db.Trade, filter *db.TradingFilter) error {
 	updateActivationStatus(ts, trades, filter)
 
 	//--- If we got new trades, probably we have to set an idle/broken state to running
@@ -267,6 +307,7 @@ func updateTradingSystem(tx *gorm.DB, ts *db.TradingSystem, trades *[]db.Trade,
 
 func updateActivationStatus(ts *db.TradingSystem, trades *[]db.Trade, f *db.TradingFilter) {
 	if ! ts.Running {
+		ts.Divergent       = false
 		ts.SuggestedAction = db.TsActionNone
 		ts.Status          = db.TsStatusOff
 		return
@@ -291,7 +332,7 @@ func updateActivationStatus(ts *db.TradingSystem, trades *[]db.Trade, f *db.Trad
 func handleManualActivation(ts *db.TradingSystem, activValue bool) {
 	if !ts.Active {
 		if !activValue {
-			ts.SuggestedAction = db.TsActionNone
+			ts.SuggestedAction = ts.DefaultAction()
 		} else {
 			ts.SuggestedAction = db.TsActionTurnOn
 		}
@@ -299,7 +340,7 @@ func handleManualActivation(ts *db.TradingSystem, activValue bool) {
 		if !activValue {
 			ts.SuggestedAction = db.TsActionTurnOff
 		} else {
-			ts.SuggestedAction = db.TsActionNone
+			ts.SuggestedAction = ts.DefaultAction()
 		}
 	}
 }
@@ -307,7 +348,7 @@ func handleManualActivation(ts *db.TradingSystem, activValue bool) {
 //=============================================================================
 
 func handleAutomaticActivation(ts *db.TradingSystem, activValue bool) {
-	ts.SuggestedAction = db.TsActionNone
+	ts.SuggestedAction = ts.DefaultAction()
 
 	if !ts.Active {
 		if activValue {
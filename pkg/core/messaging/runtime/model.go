@@ -32,8 +32,14 @@ import (
 
 //=============================================================================
 
+//--- Origin is optional (backtest|live). If missing, it is inferred using the date the
+//--- trading system was set running. Symbol is the instrument the strategy ran on, used
+//--- with the roll calendar to merge trades
+
 type TradeListMessage struct {
 	TradingSystemId uint               `json:"tradingSystemId"`
+	Origin          string             `json:"origin,omitempty"`
+	Symbol          string             `json:"symbol,omitempty"`
 	Trades          []*TradeItem       `json:"trades"`
 	DailyProfits    []*DailyProfitItem `json:"dailyProfits"`
 }
@@ -41,15 +47,17 @@ type TradeListMessage struct {
 //=============================================================================
 
 type TradeItem struct {
-	TradeType       string       `json:"tradeType"`
-	EntryDate       *time.Time   `json:"entryDate"`
-	EntryPrice      float64      `json:"entryPrice"`
-	EntryLabel      string       `json:"entryLabel"`
-	ExitDate        *time.Time   `json:"exitDate"`
-	ExitPrice       float64      `json:"exitPrice"`
-	ExitLabel       string       `json:"exitLabel"`
-	GrossProfit     float64      `json:"grossProfit"`
-	Contracts       int          `json:"contracts"`
+	TradeType             string     `json:"tradeType"`
+	EntryDate             *time.Time `json:"entryDate"`
+	EntryPrice            float64    `json:"entryPrice"`
+	EntryLabel            string     `json:"entryLabel"`
+	ExitDate              *time.Time `json:"exitDate"`
+	ExitPrice             float64    `json:"exitPrice"`
+	ExitLabel             string     `json:"exitLabel"`
+	GrossProfit           float64    `json:"grossProfit"`
+	Contracts             int        `json:"contracts"`
+	MaxAdverseExcursion   *float64   `json:"maxAdverseExcursion,omitempty"`
+	MaxFavorableExcursion *float64   `json:"maxFavorableExcursion,omitempty"`
 }
 
 //=============================================================================
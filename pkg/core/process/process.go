@@ -26,6 +26,9 @@ package process
 
 import (
 	"github.com/tradalia/portfolio-trader/pkg/app"
+	"github.com/tradalia/portfolio-trader/pkg/core/process/executionmatcher"
+	"github.com/tradalia/portfolio-trader/pkg/core/process/riskmonitor"
+	"github.com/tradalia/portfolio-trader/pkg/core/process/snapshotupdater"
 	"github.com/tradalia/portfolio-trader/pkg/core/process/statsupdater"
 	"github.com/tradalia/portfolio-trader/pkg/core/process/statusupdater"
 )
@@ -33,8 +36,11 @@ import (
 //=============================================================================
 
 func Init(cfg *app.Config) {
-	statusupdater.Init(cfg)
-	statsupdater .Init(cfg)
+	statusupdater   .Init(cfg)
+	statsupdater    .Init(cfg)
+	executionmatcher.Init(cfg)
+	riskmonitor     .Init(cfg)
+	snapshotupdater .Init(cfg)
 }
 
 //=============================================================================
@@ -0,0 +1,114 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package riskmonitor
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/tradalia/portfolio-trader/pkg/app"
+	"github.com/tradalia/portfolio-trader/pkg/business"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+func Init(cfg *app.Config) *time.Ticker {
+	ticker := time.NewTicker(15 * time.Minute)
+
+	go func() {
+		//--- Wait 2 secs to allow the system to boot properly
+		time.Sleep(2 * time.Second)
+		run(cfg)
+
+		for range ticker.C {
+			run(cfg)
+		}
+	}()
+
+	return ticker
+}
+
+//=============================================================================
+
+func run(cfg *app.Config) {
+	slog.Info("RiskMonitor: Starting")
+	start := time.Now()
+	count := 0
+
+	list, err := getPortfolioIdsWithEnabledRiskRules()
+	if err != nil {
+		slog.Error("RiskMonitor: Cannot get list of portfolios. Check aborted", "error", err)
+	} else {
+		slog.Info("RiskMonitor: Processing portfolios", "count", len(list))
+
+		for _, portfolioId := range list {
+			count += checkPortfolio(portfolioId)
+		}
+	}
+
+	duration := time.Now().Sub(start).Seconds()
+	slog.Info("RiskMonitor: Ended", "seconds", duration, "events", count)
+}
+
+//=============================================================================
+
+func getPortfolioIdsWithEnabledRiskRules() ([]uint, error){
+	var list []uint
+	var err error
+
+	err = db.RunInTransaction(func (tx *gorm.DB) error {
+		list, err = db.GetPortfolioIdsWithEnabledRiskRules(tx)
+		return err
+	})
+
+	return list,err
+}
+
+//=============================================================================
+//--- Each portfolio has its own transaction, so that an error does not roll back the
+//--- systems switched off in other portfolios. Events are published after the commit
+
+func checkPortfolio(portfolioId uint) int {
+	var events []*db.RiskEvent
+
+	err := db.RunInTransaction(func (tx *gorm.DB) error {
+		var err error
+		events, err = business.CheckScheduledRiskRules(tx, portfolioId)
+		return err
+	})
+
+	if err != nil {
+		slog.Error("RiskMonitor: Cannot check risk rules of portfolio", "portfolioId", portfolioId, "error", err)
+		return 0
+	}
+
+	business.PublishRiskEvents(events)
+	return len(events)
+}
+
+//=============================================================================
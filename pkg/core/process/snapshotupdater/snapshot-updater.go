@@ -0,0 +1,109 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package snapshotupdater
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/tradalia/portfolio-trader/pkg/app"
+	"github.com/tradalia/portfolio-trader/pkg/business"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+//--- Each run overwrites today's snapshot, so the last run of the day is the one
+//--- that is kept
+
+func Init(cfg *app.Config) *time.Ticker {
+	ticker := time.NewTicker(6 * time.Hour)
+
+	go func() {
+		//--- Wait 10 secs to allow the system to boot properly
+		time.Sleep(10 * time.Second)
+		run(cfg)
+
+		for range ticker.C {
+			run(cfg)
+		}
+	}()
+
+	return ticker
+}
+
+//=============================================================================
+
+func run(cfg *app.Config) {
+	slog.Info("SnapshotUpdater: Starting")
+	start := time.Now()
+
+	users, err := getUsersWithTradingSystems()
+	if err != nil {
+		slog.Error("SnapshotUpdater: Cannot get list of users with trading systems. Update aborted", "error", err)
+	} else {
+		for _, user := range users {
+			updateSnapshotsForUser(user)
+		}
+	}
+
+	duration := time.Now().Sub(start).Seconds()
+	slog.Info("SnapshotUpdater: Ended", "seconds", duration)
+}
+
+//=============================================================================
+
+func getUsersWithTradingSystems() ([]string, error){
+	var list []string
+	var err error
+
+	err = db.RunInTransaction(func (tx *gorm.DB) error {
+		list, err = db.GetUsersWithTradingSystems(tx)
+		return err
+	})
+
+	return list,err
+}
+
+//=============================================================================
+
+func updateSnapshotsForUser(user string) {
+	count := 0
+
+	err := db.RunInTransaction(func (tx *gorm.DB) error {
+		var err error
+		count, err = business.TakeUserPortfolioSnapshots(tx, user)
+		return err
+	})
+
+	if err != nil {
+		slog.Error("updateSnapshotsForUser: Cannot record portfolio snapshots", "user", user, "error", err)
+	} else {
+		slog.Info("updateSnapshotsForUser: Portfolio snapshots recorded", "user", user, "count", count)
+	}
+}
+
+//=============================================================================
@@ -46,8 +46,6 @@ const (
 	ChartTypeTrades = "trades"
 )
 
-const LastDays = 180
-
 //=============================================================================
 
 func Init(cfg *app.Config) *time.Ticker {
@@ -131,12 +129,12 @@ func getTradingSystemsByUser(user string) (*[]db.TradingSystem, error){
 //=============================================================================
 
 func updateTradingSystem(ts *db.TradingSystem) {
-	lastDays := LastDays
+	lastDays := core.LastStatsDays
 	trades, err := getTradingSystemTrades(ts.Id, lastDays)
 	if err != nil {
 		slog.Error("updateTradingSystem: Cannot get the list of trades for trading system. Skipping", "user", ts.Username, "id", ts.Id, "error", err)
 	} else {
-		updateLastStats(ts, trades)
+		core.UpdateLastStats(ts, trades)
 		err = updateChart(ts, trades, lastDays)
 		if err == nil {
 			err = db.RunInTransaction(func (tx *gorm.DB) error {
@@ -167,41 +165,6 @@ func getTradingSystemTrades(id uint, lastDays int) (*[]db.Trade, error) {
 
 //=============================================================================
 
-func updateLastStats(ts *db.TradingSystem, trades *[]db.Trade) {
-	grossProfit := 0.0
-	netProfit   := 0.0
-	numTrades   := 0
-
-	var grossEquity   []float64
-	//var grossDrawdown []float64
-	var netEquity     []float64
-	//var netDrawdown   []float64
-
-	for _, trade := range *trades {
-		grossProfit += trade.GrossProfit
-		netProfit   += trade.GrossProfit - 2 * ts.CostPerOperation
-		numTrades++
-
-		grossEquity   = append(grossEquity, grossProfit)
-		netEquity     = append(netEquity,   netProfit)
-		//grossDrawdown = append(grossDrawdown, 0)
-		//netDrawdown   = append(netDrawdown,   0)
-	}
-
-	//maxGrossDD := core.CalcDrawDown(&grossEquity, &grossDrawdown)
-	//maxNetDD   := core.CalcDrawDown(&netEquity,   &netDrawdown)
-
-	ts.LastNetProfit   = core.Trunc2d(netProfit)
-	ts.LastNumTrades   = numTrades
-	ts.LastNetAvgTrade = 0
-
-	if numTrades != 0 {
-		ts.LastNetAvgTrade = core.Trunc2d(netProfit / float64(numTrades))
-	}
-}
-
-//=============================================================================
-
 func updateChart(ts *db.TradingSystem, trades *[]db.Trade, lastDays int) error {
 	er := platform.NewEquityRequest()
 	er.Username = ts.Username
@@ -26,6 +26,7 @@ package statusupdater
 
 import (
 	"github.com/tradalia/portfolio-trader/pkg/app"
+	"github.com/tradalia/portfolio-trader/pkg/business/divergence"
 	"github.com/tradalia/portfolio-trader/pkg/consts"
 	"github.com/tradalia/portfolio-trader/pkg/db"
 	"gorm.io/gorm"
@@ -71,6 +72,8 @@ func run(cfg *app.Config) {
 		}
 	}
 
+	checkDivergences()
+
 	duration := time.Now().Sub(start).Seconds()
 	slog.Info("StatusUpdater: Ended", "seconds", duration)
 }
@@ -111,3 +114,69 @@ func updateTradingSystem(ts *db.TradingSystem) error {
 }
 
 //=============================================================================
+
+func checkDivergences() {
+	var list *[]db.TradingSystem
+
+	err := db.RunInTransaction(func (tx *gorm.DB) error {
+		var err error
+		list, err = db.GetRunningTradingSystems(tx)
+		return err
+	})
+
+	if err != nil {
+		slog.Error("StatusUpdater:Cannot get list of running trading systems. Divergence check aborted", "error", err)
+		return
+	}
+
+	slog.Info("StatusUpdater: Checking divergence of running trading systems", "count", len(*list))
+
+	for _, ts := range *list {
+		err = checkDivergence(ts.Id)
+		if err != nil {
+			slog.Error("StatusUpdater:Cannot check divergence of trading system", "id", ts.Id, "error", err)
+		}
+	}
+}
+
+//=============================================================================
+//--- A system that trades but diverges from its backtest is flagged for a check. The
+//--- system is reloaded and only the divergence columns are saved, to not override
+//--- changes made by runtime messages in the meantime
+
+func checkDivergence(tsId uint) error {
+	return db.RunInTransaction(func (tx *gorm.DB) error {
+		ts, err := db.GetTradingSystemById(tx, tsId)
+		if err != nil || ts == nil {
+			return err
+		}
+
+		trades, err := db.FindIncludedTradesByTradingSystemId(tx, ts.Id)
+		if err != nil {
+			return err
+		}
+
+		res := divergence.Analyze(ts, trades)
+		if res.Divergent == ts.Divergent {
+			return nil
+		}
+
+		if res.Divergent {
+			slog.Info("StatusUpdater: Trading system diverges from backtest", "id", ts.Id, "reasons", res.Reasons)
+		} else {
+			slog.Info("StatusUpdater: Trading system no longer diverges from backtest", "id", ts.Id)
+		}
+
+		ts.Divergent = res.Divergent
+
+		//--- Other suggested actions are kept, including the check of a broken system
+
+		if ts.SuggestedAction == db.TsActionNone || (ts.SuggestedAction == db.TsActionCheck && ts.Status != db.TsStatusBroken) {
+			ts.SuggestedAction = ts.DefaultAction()
+		}
+
+		return db.UpdateTradingSystemDivergence(tx, ts.Id, ts.Divergent, ts.SuggestedAction)
+	})
+}
+
+//=============================================================================
@@ -0,0 +1,130 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package core
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+)
+
+//=============================================================================
+
+const MinutesPerDay = 24 * 60
+
+//=============================================================================
+//--- Trading session expressed in minutes from midnight, in the exchange's timezone
+
+type Session struct {
+	Open  int
+	Close int
+}
+
+//=============================================================================
+
+var sessionTimeRegex = regexp.MustCompile(`\b(\d{1,2}):(\d{2})\b`)
+
+//=============================================================================
+//--- The session config (TradingSession.Config of the inventory) starts with the open
+//--- and close times as hh:mm, in the exchange's timezone (i.e. "18:00 17:00 ..."). Any
+//--- other text is ignored, and an open after the close is an overnight session.
+//--- Returns nil if the config does not contain two valid times: callers then fall back
+//--- to calendar days and to the hours of the clock
+
+func ParseSession(config string) *Session {
+	tokens := sessionTimeRegex.FindAllStringSubmatch(config, 2)
+	if len(tokens) < 2 {
+		return nil
+	}
+
+	open  := toMinutes(tokens[0])
+	close := toMinutes(tokens[1])
+
+	if open < 0 || close < 0 {
+		return nil
+	}
+
+	return &Session{
+		Open : open,
+		Close: close,
+	}
+}
+
+//=============================================================================
+
+func (s *Session) CrossesMidnight() bool {
+	return s.Open > s.Close
+}
+
+//=============================================================================
+//--- When the session crosses midnight, everything after the open belongs to the
+//--- session of the next day
+
+func (s *Session) Date(t time.Time) time.Time {
+	if s.CrossesMidnight() && minutesOfDay(t) >= s.Open {
+		return t.AddDate(0, 0, 1)
+	}
+
+	return t
+}
+
+//=============================================================================
+
+func (s *Session) Weekday(t time.Time) time.Weekday {
+	return s.Date(t).Weekday()
+}
+
+//=============================================================================
+//--- Returns the hours elapsed since the session open (0..23)
+
+func (s *Session) Hour(t time.Time) int {
+	minutes := (minutesOfDay(t) - s.Open + MinutesPerDay) % MinutesPerDay
+	return minutes / 60
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func toMinutes(token []string) int {
+	hh, err1 := strconv.Atoi(token[1])
+	mm, err2 := strconv.Atoi(token[2])
+
+	if err1 != nil || err2 != nil || hh > 24 || mm > 59 || (hh == 24 && mm > 0) {
+		return -1
+	}
+
+	return (hh * 60 + mm) % MinutesPerDay
+}
+
+//=============================================================================
+
+func minutesOfDay(t time.Time) int {
+	return t.Hour() * 60 + t.Minute()
+}
+
+//=============================================================================
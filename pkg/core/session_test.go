@@ -0,0 +1,118 @@
+//=============================================================================
+/*
+Copyright © 2023 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+//=============================================================================
+//--- Session configs have the open and close first, optionally followed by other fields.
+//--- Configs without two valid times give nil
+
+func TestParseSession(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		expected *Session
+	}{
+		{ "globex overnight",   "18:00 17:00",                                        &Session{ Open: 1080, Close: 1020 } },
+		{ "cme overnight",      "17:00 16:00 Mon-Fri",                                &Session{ Open: 1020, Close:  960 } },
+		{ "json overnight",     `{"open":"18:00","close":"17:00","days":"Sun-Fri"}`,  &Session{ Open: 1080, Close: 1020 } },
+		{ "regular hours",      "08:30 15:15",                                        &Session{ Open:  510, Close:  915 } },
+		{ "range",              "09:00-17:30",                                        &Session{ Open:  540, Close: 1050 } },
+		{ "single digit hour",  "8:00 16:30",                                         &Session{ Open:  480, Close:  990 } },
+		{ "full day",           "00:00 24:00",                                        &Session{ Open:    0, Close:    0 } },
+		{ "empty",              "",                                                   nil },
+		{ "no times",           "24x7",                                               nil },
+		{ "open only",          "18:00",                                              nil },
+		{ "bad hour",           "25:00 17:00",                                        nil },
+		{ "bad minutes",        "18:60 17:00",                                        nil },
+		{ "bad end of day",     "24:30 17:00",                                        nil },
+	}
+
+	for _, test := range tests {
+		s := ParseSession(test.config)
+
+		if s == nil || test.expected == nil {
+			if s != test.expected {
+				t.Errorf("%v: Bad session. Expected %v but got %v", test.name, test.expected, s)
+			}
+			continue
+		}
+
+		if *s != *test.expected {
+			t.Errorf("%v: Bad session. Expected %+v but got %+v", test.name, *test.expected, *s)
+		}
+	}
+}
+
+//=============================================================================
+//--- In an overnight session, the evening belongs to the session of the next day
+
+func TestSessionDate(t *testing.T) {
+	overnight := ParseSession("18:00 17:00")
+	regular   := ParseSession("08:30 15:15")
+
+	tests := []struct {
+		name    string
+		session *Session
+		time    time.Time
+		weekday time.Weekday
+		hour    int
+	}{
+		{ "overnight sunday open",   overnight, sessionTime(2025, 1, 12, 18,  0), time.Monday,   0 },
+		{ "overnight sunday night",  overnight, sessionTime(2025, 1, 12, 23, 30), time.Monday,   5 },
+		{ "overnight monday",        overnight, sessionTime(2025, 1, 13, 10,  0), time.Monday,  16 },
+		{ "overnight monday close",  overnight, sessionTime(2025, 1, 13, 16, 59), time.Monday,  22 },
+		{ "overnight friday night",  overnight, sessionTime(2025, 1, 17, 20,  0), time.Saturday, 2 },
+		{ "regular monday",          regular,   sessionTime(2025, 1, 13, 10,  0), time.Monday,   1 },
+		{ "regular monday night",    regular,   sessionTime(2025, 1, 13, 23,  0), time.Monday,  14 },
+	}
+
+	for _, test := range tests {
+		if wd := test.session.Weekday(test.time); wd != test.weekday {
+			t.Errorf("%v: Bad weekday. Expected %v but got %v", test.name, test.weekday, wd)
+		}
+
+		if h := test.session.Hour(test.time); h != test.hour {
+			t.Errorf("%v: Bad hour. Expected %v but got %v", test.name, test.hour, h)
+		}
+	}
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func sessionTime(year int, month time.Month, day int, hour int, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+}
+
+//=============================================================================
@@ -0,0 +1,138 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package stats
+
+import (
+	"math"
+)
+
+//=============================================================================
+//--- Step of an agglomerative clustering. Leaves are numbered from 0 to n-1 and the
+//--- cluster created at step i gets the number n+i (same convention as scipy's linkage)
+
+type ClusterMerge struct {
+	Left     int     `json:"left"`
+	Right    int     `json:"right"`
+	Distance float64 `json:"distance"`
+	Size     int     `json:"size"`
+}
+
+//=============================================================================
+//--- Agglomerative hierarchical clustering with average linkage. The distance matrix
+//--- must be square and symmetric
+
+func HierarchicalClustering(dist [][]float64) []ClusterMerge {
+	n := len(dist)
+	if n < 2 {
+		return []ClusterMerge{}
+	}
+
+	//--- Active clusters: id -> members
+
+	members := map[int][]int{}
+	for i := 0; i < n; i++ {
+		members[i] = []int{ i }
+	}
+
+	var merges []ClusterMerge
+
+	for step := 0; len(members) > 1; step++ {
+		bestA, bestB := -1, -1
+		bestDist     := math.Inf(1)
+
+		for a, ma := range members {
+			for b, mb := range members {
+				if a >= b {
+					continue
+				}
+
+				d := averageLinkage(dist, ma, mb)
+				if d < bestDist || (d == bestDist && (a < bestA || (a == bestA && b < bestB))) {
+					bestA, bestB, bestDist = a, b, d
+				}
+			}
+		}
+
+		merged := append(append([]int{}, members[bestA]...), members[bestB]...)
+		delete(members, bestA)
+		delete(members, bestB)
+		members[n + step] = merged
+
+		merges = append(merges, ClusterMerge{
+			Left    : bestA,
+			Right   : bestB,
+			Distance: bestDist,
+			Size    : len(merged),
+		})
+	}
+
+	return merges
+}
+
+//=============================================================================
+//--- Returns the leaves in dendrogram order, so that similar items are adjacent
+
+func LeafOrder(merges []ClusterMerge, n int) []int {
+	if n == 0 {
+		return []int{}
+	}
+
+	if len(merges) == 0 {
+		return []int{ 0 }
+	}
+
+	var visit func(id int) []int
+	visit = func(id int) []int {
+		if id < n {
+			return []int{ id }
+		}
+
+		m := merges[id - n]
+		return append(visit(m.Left), visit(m.Right)...)
+	}
+
+	return visit(n + len(merges) - 1)
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func averageLinkage(dist [][]float64, a []int, b []int) float64 {
+	sum := 0.0
+
+	for _, i := range a {
+		for _, j := range b {
+			sum += dist[i][j]
+		}
+	}
+
+	return sum / float64(len(a) * len(b))
+}
+
+//=============================================================================
@@ -0,0 +1,172 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package stats
+
+import (
+	"math"
+	"slices"
+	"sort"
+)
+
+//=============================================================================
+//--- Two-sample Kolmogorov-Smirnov test. Returns the D statistic and the asymptotic
+//--- p-value of the null hypothesis (both samples come from the same distribution)
+
+func KolmogorovSmirnov(a, b []float64) (float64, float64) {
+	n1 := len(a)
+	n2 := len(b)
+
+	if n1 == 0 || n2 == 0 {
+		return math.NaN(), math.NaN()
+	}
+
+	x := slices.Clone(a)
+	y := slices.Clone(b)
+	slices.Sort(x)
+	slices.Sort(y)
+
+	d := 0.0
+	i := 0
+	j := 0
+
+	for i < n1 && j < n2 {
+		v := math.Min(x[i], y[j])
+
+		for i < n1 && x[i] <= v {
+			i++
+		}
+
+		for j < n2 && y[j] <= v {
+			j++
+		}
+
+		diff := math.Abs(float64(i)/float64(n1) - float64(j)/float64(n2))
+		if diff > d {
+			d = diff
+		}
+	}
+
+	en := math.Sqrt(float64(n1 * n2) / float64(n1 + n2))
+
+	return d, kolmogorovProbability((en + 0.12 + 0.11/en) * d)
+}
+
+//=============================================================================
+//--- Mann-Whitney U test (aka Wilcoxon rank-sum) using the normal approximation with
+//--- tie correction. Returns the U statistic of the first sample and the two-sided p-value
+
+func MannWhitney(a, b []float64) (float64, float64) {
+	n1 := len(a)
+	n2 := len(b)
+
+	if n1 == 0 || n2 == 0 {
+		return math.NaN(), math.NaN()
+	}
+
+	type rankItem struct {
+		value float64
+		first bool
+	}
+
+	items := make([]rankItem, 0, n1 + n2)
+	for _, v := range a {
+		items = append(items, rankItem{ value: v, first: true })
+	}
+	for _, v := range b {
+		items = append(items, rankItem{ value: v, first: false })
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].value < items[j].value
+	})
+
+	//--- Assign average ranks to ties
+
+	rankSum := 0.0
+	tieSum  := 0.0
+	n       := len(items)
+
+	for i := 0; i < n; {
+		j := i
+		for j < n && items[j].value == items[i].value {
+			j++
+		}
+
+		rank := float64(i + j + 1) / 2
+		for k := i; k < j; k++ {
+			if items[k].first {
+				rankSum += rank
+			}
+		}
+
+		t := float64(j - i)
+		tieSum += t*t*t - t
+		i = j
+	}
+
+	f1 := float64(n1)
+	f2 := float64(n2)
+	u  := rankSum - f1 * (f1 + 1) / 2
+	mu := f1 * f2 / 2
+
+	variance := f1 * f2 / 12 * ((f1 + f2 + 1) - tieSum / ((f1 + f2) * (f1 + f2 - 1)))
+	if variance <= 0 {
+		return u, 1
+	}
+
+	z := (u - mu) / math.Sqrt(variance)
+
+	return u, 2 * (1 - NormalCdf(math.Abs(z)))
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func kolmogorovProbability(lambda float64) float64 {
+	if lambda < 0.2 {
+		return 1
+	}
+
+	sum  := 0.0
+	sign := 1.0
+
+	for k := 1; k <= 100; k++ {
+		term := sign * math.Exp(-2 * float64(k * k) * lambda * lambda)
+		sum += term
+
+		if math.Abs(term) < 1e-10 {
+			break
+		}
+
+		sign = -sign
+	}
+
+	return math.Max(0, math.Min(1, 2 * sum))
+}
+
+//=============================================================================
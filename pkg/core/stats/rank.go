@@ -0,0 +1,72 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package stats
+
+import (
+	"sort"
+)
+
+//=============================================================================
+//--- Returns the ranks of the values (starting from 1), using the average rank for ties
+
+func Ranks(data []float64) []float64 {
+	n     := len(data)
+	index := make([]int, n)
+	for i := range index {
+		index[i] = i
+	}
+
+	sort.SliceStable(index, func(i, j int) bool {
+		return data[index[i]] < data[index[j]]
+	})
+
+	ranks := make([]float64, n)
+
+	for i := 0; i < n; {
+		j := i
+		for j < n && data[index[j]] == data[index[i]] {
+			j++
+		}
+
+		rank := float64(i + j + 1) / 2
+		for k := i; k < j; k++ {
+			ranks[index[k]] = rank
+		}
+
+		i = j
+	}
+
+	return ranks
+}
+
+//=============================================================================
+//--- Spearman rank correlation: the Pearson correlation of the ranks
+
+func SpearmanCorrelation(x, y []float64) float64 {
+	return Correlation(Ranks(x), Ranks(y))
+}
+
+//=============================================================================
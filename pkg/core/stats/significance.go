@@ -0,0 +1,326 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package stats
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+)
+
+//=============================================================================
+
+const EulerMascheroni = 0.5772156649015329
+
+//=============================================================================
+//===
+//=== Moments
+//===
+//=============================================================================
+
+func MomentSkewness(data []float64, mean, stdDev float64) float64 {
+	if len(data) == 0 || stdDev == 0 {
+		return 0
+	}
+
+	sum := 0.0
+
+	for _, v := range data {
+		z := (v - mean) / stdDev
+		sum += z * z * z
+	}
+
+	return sum/float64(len(data))
+}
+
+//=============================================================================
+//--- Returns the kurtosis (not the excess kurtosis): 3 for a normal distribution
+
+func Kurtosis(data []float64, mean, stdDev float64) float64 {
+	if len(data) == 0 || stdDev == 0 {
+		return 3
+	}
+
+	sum := 0.0
+
+	for _, v := range data {
+		z := (v - mean) / stdDev
+		sum += z * z * z * z
+	}
+
+	return sum/float64(len(data))
+}
+
+//=============================================================================
+//===
+//=== Distributions
+//===
+//=============================================================================
+
+func NormalCdf(x float64) float64 {
+	return 0.5 * math.Erfc(-x / math.Sqrt2)
+}
+
+//=============================================================================
+//--- Inverse of the standard normal CDF (Acklam's algorithm, relative error < 1.15e-9)
+
+func NormalInv(p float64) float64 {
+	if p <= 0 {
+		return math.Inf(-1)
+	}
+
+	if p >= 1 {
+		return math.Inf(1)
+	}
+
+	a := []float64{ -3.969683028665376e+01, 2.209460984245205e+02, -2.759285104469687e+02, 1.383577518672690e+02, -3.066479806614716e+01, 2.506628277459239e+00 }
+	b := []float64{ -5.447609879822406e+01, 1.615858368580409e+02, -1.556989798598866e+02, 6.680131188771972e+01, -1.328068155288572e+01 }
+	c := []float64{ -7.784894002430293e-03, -3.223964580411365e-01, -2.400758277161838e+00, -2.549732539343734e+00, 4.374664141464968e+00, 2.938163982698783e+00 }
+	d := []float64{ 7.784695709041462e-03, 3.224671290700398e-01, 2.445134137142996e+00, 3.754408661907416e+00 }
+
+	pLow  := 0.02425
+	pHigh := 1 - pLow
+
+	if p < pLow {
+		q := math.Sqrt(-2 * math.Log(p))
+		return (((((c[0]*q+c[1])*q+c[2])*q+c[3])*q+c[4])*q + c[5]) / ((((d[0]*q+d[1])*q+d[2])*q+d[3])*q + 1)
+	}
+
+	if p > pHigh {
+		q := math.Sqrt(-2 * math.Log(1-p))
+		return -(((((c[0]*q+c[1])*q+c[2])*q+c[3])*q+c[4])*q + c[5]) / ((((d[0]*q+d[1])*q+d[2])*q+d[3])*q + 1)
+	}
+
+	q := p - 0.5
+	r := q * q
+	return (((((a[0]*r+a[1])*r+a[2])*r+a[3])*r+a[4])*r + a[5]) * q / (((((b[0]*r+b[1])*r+b[2])*r+b[3])*r+b[4])*r + 1)
+}
+
+//=============================================================================
+
+func StudentTCdf(t float64, df float64) float64 {
+	x    := df / (df + t*t)
+	tail := 0.5 * RegIncompleteBeta(df/2, 0.5, x)
+
+	if t > 0 {
+		return 1 - tail
+	}
+
+	return tail
+}
+
+//=============================================================================
+//--- Regularized incomplete beta function I_x(a,b)
+
+func RegIncompleteBeta(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+
+	if x >= 1 {
+		return 1
+	}
+
+	lga, _  := math.Lgamma(a)
+	lgb, _  := math.Lgamma(b)
+	lgab, _ := math.Lgamma(a + b)
+	front   := math.Exp(lgab - lga - lgb + a*math.Log(x) + b*math.Log(1-x))
+
+	//--- Use the symmetry relation to speed up the continued fraction convergence
+
+	if x < (a+1)/(a+b+2) {
+		return front * betaContinuedFraction(a, b, x) / a
+	}
+
+	return 1 - front * betaContinuedFraction(b, a, 1-x) / b
+}
+
+//=============================================================================
+//===
+//=== Tests
+//===
+//=============================================================================
+//--- One-sided t-test of mean > 0. Returns the t statistic and the p-value
+
+func TTest(data []float64) (float64, float64) {
+	n := len(data)
+	if n < 2 {
+		return math.NaN(), math.NaN()
+	}
+
+	mean   := Mean(data)
+	stdDev := SampleStdDev(data, mean)
+
+	if stdDev == 0 {
+		return math.NaN(), math.NaN()
+	}
+
+	t := mean / (stdDev / math.Sqrt(float64(n)))
+
+	return t, 1 - StudentTCdf(t, float64(n - 1))
+}
+
+//=============================================================================
+
+func SampleStdDev(data []float64, mean float64) float64 {
+	if len(data) < 2 {
+		return math.NaN()
+	}
+
+	sum := 0.0
+
+	for _, v := range data {
+		diff := v - mean
+		sum += diff * diff
+	}
+
+	return math.Sqrt(sum/float64(len(data) -1))
+}
+
+//=============================================================================
+//--- Percentile bootstrap confidence interval of a statistic
+
+func Bootstrap(data []float64, samples int, confidence float64, seed int64, statistic func([]float64) float64) (float64, float64) {
+	if len(data) == 0 || samples == 0 {
+		return math.NaN(), math.NaN()
+	}
+
+	rnd    := rand.New(rand.NewSource(seed))
+	sample := make([]float64, len(data))
+	values := make([]float64, samples)
+
+	for i := 0; i < samples; i++ {
+		for j := range sample {
+			sample[j] = data[rnd.Intn(len(data))]
+		}
+
+		values[i] = statistic(sample)
+	}
+
+	slices.Sort(values)
+
+	alpha := (1 - confidence) / 2
+	lower := values[int(math.Floor(alpha * float64(samples -1)))]
+	upper := values[int(math.Ceil((1 - alpha) * float64(samples -1)))]
+
+	return lower, upper
+}
+
+//=============================================================================
+//===
+//=== Sharpe ratio
+//===
+//=============================================================================
+//--- Probability that the true Sharpe ratio is above the benchmark one (Bailey & Lopez de Prado)
+
+func ProbabilisticSharpeRatio(sr, benchmarkSr float64, n int, skewness, kurtosis float64) float64 {
+	if n < 2 {
+		return math.NaN()
+	}
+
+	den := 1 - skewness * sr + (kurtosis - 1) / 4 * sr * sr
+	if den <= 0 {
+		return math.NaN()
+	}
+
+	return NormalCdf((sr - benchmarkSr) * math.Sqrt(float64(n - 1)) / math.Sqrt(den))
+}
+
+//=============================================================================
+//--- Expected maximum Sharpe ratio among 'trials' independent trials with zero true Sharpe,
+//--- given the variance of the Sharpe ratio estimates
+
+func ExpectedMaxSharpeRatio(trials int, variance float64) float64 {
+	if trials <= 1 {
+		return 0
+	}
+
+	n := float64(trials)
+
+	return math.Sqrt(variance) * ((1 - EulerMascheroni) * NormalInv(1 - 1/n) + EulerMascheroni * NormalInv(1 - 1/(n * math.E)))
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func betaContinuedFraction(a, b, x float64) float64 {
+	const maxIterations = 300
+	const epsilon       = 3e-14
+	const tiny          = 1e-300
+
+	qab := a + b
+	qap := a + 1
+	qam := a - 1
+	c   := 1.0
+	d   := 1 - qab * x / qap
+
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+
+	d = 1 / d
+	h := d
+
+	for m := 1; m <= maxIterations; m++ {
+		fm := float64(m)
+		m2 := 2 * fm
+
+		aa := fm * (b - fm) * x / ((qam + m2) * (a + m2))
+		d = 1 + aa * d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa / c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d  = 1 / d
+		h *= d * c
+
+		aa = -(a + fm) * (qab + fm) * x / ((a + m2) * (qap + m2))
+		d = 1 + aa * d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa / c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+
+		if math.Abs(del - 1) < epsilon {
+			break
+		}
+	}
+
+	return h
+}
+
+//=============================================================================
@@ -131,6 +131,37 @@ func LinearRegression(x []time.Time, y []float64) float64 {
 
 //=============================================================================
 
+func Covariance(x []float64, xMean float64, y []float64, yMean float64) float64 {
+	if len(x) == 0 || len(x) != len(y) {
+		return math.NaN()
+	}
+
+	sum := 0.0
+
+	for i := range x {
+		sum += (x[i] - xMean) * (y[i] - yMean)
+	}
+
+	return sum/float64(len(x))
+}
+
+//=============================================================================
+
+func Correlation(x, y []float64) float64 {
+	xMean := Mean(x)
+	yMean := Mean(y)
+	xStd  := StdDev(x, xMean)
+	yStd  := StdDev(y, yMean)
+
+	if xStd == 0 || yStd == 0 {
+		return 0
+	}
+
+	return Covariance(x, xMean, y, yMean) / (xStd * yStd)
+}
+
+//=============================================================================
+
 func Min(data []float64) float64 {
 	if len(data) == 0 {
 		return math.NaN()
@@ -47,3 +47,149 @@ func TestSharpeRatio(t *testing.T) {
 }
 
 //=============================================================================
+
+var serie1 = []float64{ 1, 2, 3, 4, 5 }
+var serie2 = []float64{ 2, 4, 6, 8, 10 }
+var serie3 = []float64{ 5, 4, 3, 2, 1 }
+
+//=============================================================================
+
+func TestCorrelation(t *testing.T) {
+	corr := Correlation(serie1, serie2)
+
+	if math.Abs(corr - 1) > 1e-9 {
+		t.Errorf("Bad correlation: Expected 1 and got %v", corr)
+	}
+
+	corr = Correlation(serie1, serie3)
+
+	if math.Abs(corr + 1) > 1e-9 {
+		t.Errorf("Bad correlation: Expected -1 and got %v", corr)
+	}
+
+	cov := Covariance(serie1, Mean(serie1), serie2, Mean(serie2))
+
+	if cov != 4 {
+		t.Errorf("Bad covariance: Expected 4 and got %v", cov)
+	}
+}
+
+//=============================================================================
+
+func TestNormalDistribution(t *testing.T) {
+	if v := NormalCdf(0); math.Abs(v - 0.5) > 1e-9 {
+		t.Errorf("Bad normal CDF: Expected 0.5 and got %v", v)
+	}
+
+	if v := NormalCdf(1.96); math.Abs(v - 0.975) > 1e-4 {
+		t.Errorf("Bad normal CDF: Expected ~0.975 and got %v", v)
+	}
+
+	if v := NormalInv(0.975); math.Abs(v - 1.96) > 1e-3 {
+		t.Errorf("Bad inverse normal CDF: Expected ~1.96 and got %v", v)
+	}
+
+	if v := NormalInv(0.01); math.Abs(v + 2.3263) > 1e-3 {
+		t.Errorf("Bad inverse normal CDF: Expected ~-2.3263 and got %v", v)
+	}
+}
+
+//=============================================================================
+
+func TestStudentT(t *testing.T) {
+	//--- Critical value for 10 degrees of freedom at 97.5%
+
+	if v := StudentTCdf(2.228, 10); math.Abs(v - 0.975) > 1e-3 {
+		t.Errorf("Bad Student's t CDF: Expected ~0.975 and got %v", v)
+	}
+
+	if v := StudentTCdf(-2.228, 10); math.Abs(v - 0.025) > 1e-3 {
+		t.Errorf("Bad Student's t CDF: Expected ~0.025 and got %v", v)
+	}
+
+	tStat, pValue := TTest(prices)
+
+	if tStat < 2.51 || tStat > 2.53 || pValue < 0.01 || pValue > 0.02 {
+		t.Errorf("Bad t-test: Got t=%v and p=%v", tStat, pValue)
+	}
+}
+
+//=============================================================================
+
+func TestNonParametric(t *testing.T) {
+	shifted := make([]float64, len(prices))
+	for i, v := range prices {
+		shifted[i] = v + 100
+	}
+
+	if d, p := KolmogorovSmirnov(prices, prices); d != 0 || p != 1 {
+		t.Errorf("Bad KS test on same sample: Got d=%v and p=%v", d, p)
+	}
+
+	if d, p := KolmogorovSmirnov(prices, shifted); d != 1 || p > 0.01 {
+		t.Errorf("Bad KS test on shifted sample: Got d=%v and p=%v", d, p)
+	}
+
+	if u, p := MannWhitney([]float64{ 1, 2, 3, 4, 5 }, []float64{ 6, 7, 8, 9, 10 }); u != 0 || p > 0.02 {
+		t.Errorf("Bad Mann-Whitney test: Got u=%v and p=%v", u, p)
+	}
+
+	if u, p := MannWhitney(prices, prices); math.Abs(p - 1) > 1e-9 || u != float64(len(prices) * len(prices)) / 2 {
+		t.Errorf("Bad Mann-Whitney test on same sample: Got u=%v and p=%v", u, p)
+	}
+}
+
+//=============================================================================
+
+func TestSpearmanCorrelation(t *testing.T) {
+	ranks := Ranks([]float64{ 10, 20, 20, 5 })
+	if ranks[0] != 2 || ranks[1] != 3.5 || ranks[2] != 3.5 || ranks[3] != 1 {
+		t.Errorf("Bad ranks: Got %v", ranks)
+	}
+
+	//--- Monotonic but not linear
+
+	if corr := SpearmanCorrelation(serie1, []float64{ 1, 4, 9, 16, 100 }); math.Abs(corr - 1) > 1e-9 {
+		t.Errorf("Bad Spearman correlation: Expected 1 and got %v", corr)
+	}
+
+	if corr := SpearmanCorrelation(serie1, serie3); math.Abs(corr + 1) > 1e-9 {
+		t.Errorf("Bad Spearman correlation: Expected -1 and got %v", corr)
+	}
+}
+
+//=============================================================================
+
+func TestHierarchicalClustering(t *testing.T) {
+	dist := [][]float64{
+		{ 0, 1, 8, 9 },
+		{ 1, 0, 7, 8 },
+		{ 8, 7, 0, 2 },
+		{ 9, 8, 2, 0 },
+	}
+
+	merges := HierarchicalClustering(dist)
+
+	if len(merges) != 3 {
+		t.Fatalf("Bad number of merges: Expected 3 and got %v", len(merges))
+	}
+
+	if merges[0].Left != 0 || merges[0].Right != 1 || merges[0].Distance != 1 {
+		t.Errorf("Bad first merge: Got %+v", merges[0])
+	}
+
+	if merges[1].Left != 2 || merges[1].Right != 3 || merges[1].Distance != 2 {
+		t.Errorf("Bad second merge: Got %+v", merges[1])
+	}
+
+	if merges[2].Left != 4 || merges[2].Right != 5 || merges[2].Distance != 8 || merges[2].Size != 4 {
+		t.Errorf("Bad last merge: Got %+v", merges[2])
+	}
+
+	order := LeafOrder(merges, 4)
+	if len(order) != 4 || order[0] != 0 || order[1] != 1 || order[2] != 2 || order[3] != 3 {
+		t.Errorf("Bad leaf order: Got %v", order)
+	}
+}
+
+//=============================================================================
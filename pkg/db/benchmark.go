@@ -0,0 +1,121 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package db
+
+import (
+	"github.com/tradalia/core/datatype"
+	"github.com/tradalia/core/req"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+func GetBenchmarks(tx *gorm.DB, filter map[string]any, offset int, limit int) (*[]Benchmark, error) {
+	var list []Benchmark
+	res := tx.Where(filter).Offset(offset).Limit(limit).Find(&list)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	return &list, nil
+}
+
+//=============================================================================
+
+func GetBenchmarkById(tx *gorm.DB, id uint) (*Benchmark, error) {
+	var list []Benchmark
+	res := tx.Find(&list, id)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	if len(list) == 1 {
+		return &list[0], nil
+	}
+
+	return nil, nil
+}
+
+//=============================================================================
+
+func AddBenchmark(tx *gorm.DB, b *Benchmark) error {
+	err := tx.Create(b).Error
+	return req.NewServerErrorByError(err)
+}
+
+//=============================================================================
+
+func DeleteBenchmark(tx *gorm.DB, id uint) error {
+	err := tx.Delete(&Benchmark{}, id).Error
+	return req.NewServerErrorByError(err)
+}
+
+//=============================================================================
+
+func FindBenchmarkReturnsFromDay(tx *gorm.DB, benchmarkId uint, from datatype.IntDate, to datatype.IntDate) (*[]BenchmarkReturn, error) {
+	var list []BenchmarkReturn
+
+	query := "benchmark_id = ? and day >= ? and day <= ?"
+	res   := tx.Order("day").Find(&list, query, benchmarkId, from, to)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	return &list, nil
+}
+
+//=============================================================================
+//--- Existing values on the same days are replaced
+
+func SetBenchmarkReturns(tx *gorm.DB, benchmarkId uint, list []BenchmarkReturn) error {
+	if len(list) == 0 {
+		return nil
+	}
+
+	var days []datatype.IntDate
+	for _, br := range list {
+		days = append(days, br.Day)
+	}
+
+	err := tx.Delete(&BenchmarkReturn{}, "benchmark_id = ? and day in ?", benchmarkId, days).Error
+	if err != nil {
+		return req.NewServerErrorByError(err)
+	}
+
+	err = tx.CreateInBatches(&list, 500).Error
+	return req.NewServerErrorByError(err)
+}
+
+//=============================================================================
+
+func DeleteAllBenchmarkReturnsByBenchmarkId(tx *gorm.DB, id uint) error {
+	err := tx.Delete(&BenchmarkReturn{}, "benchmark_id", id).Error
+	return req.NewServerErrorByError(err)
+}
+
+//=============================================================================
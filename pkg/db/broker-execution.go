@@ -0,0 +1,143 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package db
+
+import (
+	"github.com/tradalia/core/req"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+func FindExecutionsByTradingSystemId(tx *gorm.DB, tsId uint) (*[]BrokerExecution, error) {
+	var list []BrokerExecution
+
+	res := tx.Order("execution_date").Find(&list, "trading_system_id = ?", tsId)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	return &list, nil
+}
+
+//=============================================================================
+
+//--- Returns the executions whose quantity has not been fully matched yet, orphaned ones excluded
+
+func FindUnmatchedExecutionsByTsId(tx *gorm.DB, tsId uint) (*[]BrokerExecution, error) {
+	var list []BrokerExecution
+
+	res := tx.Order("execution_date").Find(&list, "trading_system_id = ? and matched < quantity and orphaned = false", tsId)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	return &list, nil
+}
+
+//=============================================================================
+
+func FindLastMatchedExecutionByTsId(tx *gorm.DB, tsId uint) (*BrokerExecution, error) {
+	var list []BrokerExecution
+
+	res := tx.Order("execution_date desc").Limit(1).Find(&list, "trading_system_id = ? and trade_id is not null", tsId)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	if len(list) == 1 {
+		return &list[0], nil
+	}
+
+	return nil, nil
+}
+
+//=============================================================================
+
+func GetTradingSystemIdsWithUnmatchedExecutions(tx *gorm.DB) ([]uint, error) {
+	var list []uint
+	res := tx.Table("broker_execution").Where("matched < quantity and orphaned = false").Distinct("trading_system_id").Scan(&list)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	return list, nil
+}
+
+//=============================================================================
+
+func AddExecution(tx *gorm.DB, be *BrokerExecution) error {
+	err := tx.Create(be).Error
+	return req.NewServerErrorByError(err)
+}
+
+//=============================================================================
+
+func UpdateExecution(tx *gorm.DB, be *BrokerExecution) error {
+	err := tx.Save(be).Error
+	return req.NewServerErrorByError(err)
+}
+
+//=============================================================================
+
+func UnmatchAllExecutionsByTradingSystemId(tx *gorm.DB, id uint) error {
+	err := tx.Model(&BrokerExecution{}).Where("trading_system_id", id).Updates(unmatchedExecution()).Error
+	return req.NewServerErrorByError(err)
+}
+
+//=============================================================================
+//--- Shared fills are released entirely, even if part of them belongs to another trade
+
+func UnmatchExecutionsByTradeId(tx *gorm.DB, tradeId uint) error {
+	err := tx.Model(&BrokerExecution{}).Where("trade_id", tradeId).Updates(unmatchedExecution()).Error
+	return req.NewServerErrorByError(err)
+}
+
+//=============================================================================
+
+func DeleteAllExecutionsByTradingSystemId(tx *gorm.DB, id uint) error {
+	err := tx.Delete(&BrokerExecution{}, "trading_system_id", id).Error
+	return req.NewServerErrorByError(err)
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func unmatchedExecution() map[string]any {
+	return map[string]any{
+		"trade_id": nil,
+		"matched" : 0,
+		"orphaned": false,
+	}
+}
+
+//=============================================================================
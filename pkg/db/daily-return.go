@@ -85,6 +85,20 @@ func AddDailyReturn(tx *gorm.DB, dr *DailyReturn) error {
 
 //=============================================================================
 
+func UpdateDailyReturn(tx *gorm.DB, dr *DailyReturn) error {
+	err := tx.Save(dr).Error
+	return req.NewServerErrorByError(err)
+}
+
+//=============================================================================
+
+func DeleteDailyReturn(tx *gorm.DB, id uint) error {
+	err := tx.Delete(&DailyReturn{}, id).Error
+	return req.NewServerErrorByError(err)
+}
+
+//=============================================================================
+
 func DeleteAllDailyReturnsByTradingSystemId(tx *gorm.DB, id uint) error {
 	err := tx.Delete(&DailyReturn{}, "trading_system_id", id).Error
 	return req.NewServerErrorByError(err)
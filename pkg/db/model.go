@@ -100,10 +100,12 @@ type TradingSystem struct {
 	Finalized         bool             `json:"finalized"`
 	Trading           bool             `json:"trading"`
 	Running           bool             `json:"running"`
+	RunningSince      *time.Time       `json:"runningSince"`
 	AutoActivation    bool             `json:"autoActivation"`
 	Active            bool             `json:"active"`
 	Status            TsStatus         `json:"status"`
 	SuggestedAction   TsSuggAction     `json:"suggestedAction"`
+	Divergent         bool             `json:"divergent"`
 	FirstTrade        *time.Time       `json:"firstTrade"`
 	LastTrade         *time.Time       `json:"lastTrade"`
 	LastNetProfit     float64          `json:"lastNetProfit"`
@@ -116,6 +118,18 @@ type TradingSystem struct {
 	EngineCode        string           `json:"engineCode"`
 }
 
+//-----------------------------------------------------------------------------
+//--- Action to suggest when the activation does not require one: a system that
+//--- diverges from its backtest must be checked
+
+func (ts TradingSystem) DefaultAction() TsSuggAction {
+	if ts.Divergent {
+		return TsActionCheck
+	}
+
+	return TsActionNone
+}
+
 //=============================================================================
 
 type TradingFilter struct {
@@ -147,24 +161,52 @@ const (
 	TradeTypeAll   = "**"
 )
 
+const (
+	TradeOriginBacktest = "backtest"
+	TradeOriginLive     = "live"
+)
+
 //-----------------------------------------------------------------------------
 
 type Trade struct {
-	Id                 uint       `json:"id" gorm:"primaryKey"`
-	TradingSystemId    uint       `json:"tradingSystemId"`
-	TradeType          string     `json:"tradeType"`
-	EntryDate          *time.Time `json:"entryDate"`
-	EntryPrice         float64    `json:"entryPrice"`
-	EntryLabel         string     `json:"entryLabel"`
-	ExitDate           *time.Time `json:"exitDate"`
-	ExitPrice          float64    `json:"exitPrice"`
-	ExitLabel          string     `json:"exitLabel"`
-	GrossProfit        float64    `json:"grossProfit"`
-	Contracts          int        `json:"contracts"`
-	EntryDateAtBroker  *time.Time `json:"entryDateAtBroker"`
-	EntryPriceAtBroker float64    `json:"entryPriceAtBroker"`
-	ExitDateAtBroker   *time.Time `json:"exitDateAtBroker"`
-	ExitPriceAtBroker  float64    `json:"exitPriceAtBroker"`
+	Id                    uint       `json:"id" gorm:"primaryKey"`
+	TradingSystemId       uint       `json:"tradingSystemId"`
+	TradeType             string     `json:"tradeType"`
+	EntryDate             *time.Time `json:"entryDate"`
+	EntryPrice            float64    `json:"entryPrice"`
+	EntryLabel            string     `json:"entryLabel"`
+	ExitDate              *time.Time `json:"exitDate"`
+	ExitPrice             float64    `json:"exitPrice"`
+	ExitLabel             string     `json:"exitLabel"`
+	GrossProfit           float64    `json:"grossProfit"`
+	Contracts             int        `json:"contracts"`
+	EntryDateAtBroker     *time.Time `json:"entryDateAtBroker"`
+	EntryPriceAtBroker    float64    `json:"entryPriceAtBroker"`
+	ExitDateAtBroker      *time.Time `json:"exitDateAtBroker"`
+	ExitPriceAtBroker     float64    `json:"exitPriceAtBroker"`
+	MaxAdverseExcursion   *float64   `json:"maxAdverseExcursion"`
+	MaxFavorableExcursion *float64   `json:"maxFavorableExcursion"`
+	Origin                string     `json:"origin"`
+	Excluded              bool       `json:"excluded"`
+	Symbol                string     `json:"symbol"`
+}
+
+//-----------------------------------------------------------------------------
+//--- Excursions are optional and expressed in currency, as positive values
+
+func (t Trade) HasExcursions() bool {
+	return t.MaxAdverseExcursion != nil && t.MaxFavorableExcursion != nil
+}
+
+//-----------------------------------------------------------------------------
+//--- Old trades have no origin: they are live if closed after the system was set running
+
+func (t Trade) IsLive(ts *TradingSystem) bool {
+	if t.Origin != "" {
+		return t.Origin == TradeOriginLive
+	}
+
+	return ts.RunningSince != nil && t.ExitDate != nil && !t.ExitDate.Before(*ts.RunningSince)
 }
 
 //-----------------------------------------------------------------------------
@@ -179,12 +221,225 @@ func (t Trade) String() string {
 
 //=============================================================================
 
+const (
+	ExecutionSideBuy  = "BUY"
+	ExecutionSideSell = "SELL"
+)
+
+//-----------------------------------------------------------------------------
+//--- Fill received from the broker. TradeId is set when the execution has been
+//--- matched against the entry or the exit of a strategy trade (the last one, if the
+//--- fill is shared, like in a stop and reverse). Matched is the quantity already used.
+//--- Orphaned executions are older than the last matched one and are not matched anymore
+
+type BrokerExecution struct {
+	Id               uint       `json:"id" gorm:"primaryKey"`
+	TradingSystemId  uint       `json:"tradingSystemId"`
+	ExecutionDate    *time.Time `json:"executionDate"`
+	Side             string     `json:"side"`
+	Price            float64    `json:"price"`
+	Quantity         int        `json:"quantity"`
+	Matched          int        `json:"matched"`
+	Orphaned         bool       `json:"orphaned"`
+	TradeId          *uint      `json:"tradeId"`
+}
+
+//=============================================================================
+
+func (be BrokerExecution) Remaining() int {
+	return be.Quantity - be.Matched
+}
+
+//=============================================================================
+
+const (
+	TradeAuditActionUpdate    = "update"
+	TradeAuditActionDelete    = "delete"
+	TradeAuditActionDeleteAll = "deleteAll"
+	TradeAuditActionExclude   = "exclude"
+	TradeAuditActionInclude   = "include"
+)
+
+//-----------------------------------------------------------------------------
+//--- Records a manual change on the trades. OldValue and NewValue hold the trade
+//--- as JSON. TradeId is nil when the change involves all trades. TradeKey is the key
+//--- of the trade before a delete or an update, so that it is not added again
+
+type TradeAudit struct {
+	Id               uint       `json:"id" gorm:"primaryKey"`
+	TradingSystemId  uint       `json:"tradingSystemId"`
+	TradeId          *uint      `json:"tradeId"`
+	TradeKey         string     `json:"tradeKey"`
+	Username         string     `json:"username"`
+	Timestamp        *time.Time `json:"timestamp"`
+	Action           string     `json:"action"`
+	Reason           string     `json:"reason"`
+	OldValue         string     `json:"oldValue"`
+	NewValue         string     `json:"newValue"`
+}
+
+//=============================================================================
+//--- Inside [FromDate .. ToDate], trades of DataSymbol must come from Symbol (i.e. from
+//--- the front contract instead of the back-adjusted continuous contract)
+
+type RollPeriod struct {
+	Id          uint       `json:"id" gorm:"primaryKey"`
+	Username    string     `json:"username"`
+	DataSymbol  string     `json:"dataSymbol"`
+	Symbol      string     `json:"symbol"`
+	FromDate    *time.Time `json:"fromDate"`
+	ToDate      *time.Time `json:"toDate"`
+}
+
+//=============================================================================
+
+const (
+	TradeConflictTypeNearDuplicate    = "nearDuplicate"
+	TradeConflictTypeNotAuthoritative = "notAuthoritative"
+)
+
+const (
+	TradeConflictStatusPending   = "pending"
+	TradeConflictStatusKept      = "kept"
+	TradeConflictStatusReplaced  = "replaced"
+	TradeConflictStatusAdded     = "added"
+	TradeConflictStatusDiscarded = "discarded"
+)
+
+//-----------------------------------------------------------------------------
+//--- A trade received during a merge that was not added as is. TradeId is the
+//--- existing trade it conflicts with (if any). TradeKey identifies the new trade
+//--- so that it is not reported twice. Values are trades as JSON
+
+type TradeConflict struct {
+	Id               uint       `json:"id" gorm:"primaryKey"`
+	TradingSystemId  uint       `json:"tradingSystemId"`
+	TradeId          *uint      `json:"tradeId"`
+	Timestamp        *time.Time `json:"timestamp"`
+	ConflictType     string     `json:"conflictType"`
+	Status           string     `json:"status"`
+	Symbol           string     `json:"symbol"`
+	TradeKey         string     `json:"tradeKey"`
+	OldValue         string     `json:"oldValue"`
+	NewValue         string     `json:"newValue"`
+}
+
+//=============================================================================
+
+const (
+	RiskRuleTypeDailyLoss     = "dailyLoss"
+	RiskRuleTypeDrawdown      = "drawdown"
+	RiskRuleTypeActiveSystems = "activeSystems"
+	RiskRuleTypeMargin        = "margin"
+)
+
+//-----------------------------------------------------------------------------
+//--- A limit applied to the portfolio and all its children. Threshold is an amount
+//--- of money, except for activeSystems where it is a number of systems. Daily loss
+//--- and drawdown are measured on the days after ResetDay, which is set when the rule
+//--- is created, reset or breached
+
+type RiskRule struct {
+	Id           uint             `json:"id" gorm:"primaryKey"`
+	PortfolioId  uint             `json:"portfolioId"`
+	Username     string           `json:"username"`
+	RuleType     string           `json:"ruleType"`
+	Threshold    float64          `json:"threshold"`
+	Enabled      bool             `json:"enabled"`
+	ResetDay     datatype.IntDate `json:"resetDay"`
+}
+
+//-----------------------------------------------------------------------------
+//--- A rule breach. TsIds are the ids (as JSON) of the systems switched to inactive
+
+type RiskEvent struct {
+	Id           uint       `json:"id" gorm:"primaryKey"`
+	PortfolioId  uint       `json:"portfolioId"`
+	RiskRuleId   uint       `json:"riskRuleId"`
+	Username     string     `json:"username"`
+	Timestamp    *time.Time `json:"timestamp"`
+	RuleType     string     `json:"ruleType"`
+	Threshold    float64    `json:"threshold"`
+	Value        float64    `json:"value"`
+	TsIds        string     `json:"tsIds"`
+}
+
+//=============================================================================
+//--- A dynamic group of trading systems. Query holds the selection criteria as JSON
+
+type SmartGroup struct {
+	Id        uint    `json:"id" gorm:"primaryKey"`
+	Username  string  `json:"username"`
+	Name      string  `json:"name"`
+	Query     string  `json:"query"`
+}
+
+//=============================================================================
+//--- Daily status of a portfolio (and its children). Values are recorded as they were
+//--- on that day and are not changed when trades or systems are later removed. Snapshots
+//--- outlive their portfolio, so its name is recorded too
+
+type PortfolioSnapshot struct {
+	Id              uint             `json:"id" gorm:"primaryKey"`
+	PortfolioId     uint             `json:"portfolioId"`
+	PortfolioName   string           `json:"portfolioName"`
+	Username        string           `json:"username"`
+	Day             datatype.IntDate `json:"day"`
+	Timestamp       *time.Time       `json:"timestamp"`
+	NetProfit       float64          `json:"netProfit"`
+	Drawdown        float64          `json:"drawdown"`
+	MaxDrawdown     float64          `json:"maxDrawdown"`
+	TradingSystems  int              `json:"tradingSystems"`
+	RunningSystems  int              `json:"runningSystems"`
+	ActiveSystems   int              `json:"activeSystems"`
+	MarginInUse     float64          `json:"marginInUse"`
+	OpenExposure    float64          `json:"openExposure"`
+}
+
+//=============================================================================
+//--- Daily returns sent by the runtime have an empty origin, as they were the only
+//--- source before daily returns could be derived from trades
+
+const (
+	DailyReturnOriginRuntime = ""
+	DailyReturnOriginTrades  = "trades"
+	DailyReturnOriginMarked  = "marked"
+)
+
+//-----------------------------------------------------------------------------
+
 type DailyReturn struct {
 	Id               uint             `json:"id" gorm:"primaryKey"`
 	TradingSystemId  uint             `json:"tradingSystemId"`
 	Day              datatype.IntDate `json:"day"`
 	GrossProfit      float64          `json:"grossProfit"`
 	Trades           int              `json:"trades"`
+	Origin           string           `json:"origin"`
+}
+
+//-----------------------------------------------------------------------------
+
+func (dr DailyReturn) IsFromRuntime() bool {
+	return dr.Origin == DailyReturnOriginRuntime
+}
+
+//=============================================================================
+
+type Benchmark struct {
+	Id        uint    `json:"id" gorm:"primaryKey"`
+	Username  string  `json:"username"`
+	Code      string  `json:"code"`
+	Name      string  `json:"name"`
+}
+
+//=============================================================================
+//--- Value is the daily return of the benchmark, as a percentage (i.e. 1.5 means +1.5%)
+
+type BenchmarkReturn struct {
+	Id           uint             `json:"id" gorm:"primaryKey"`
+	BenchmarkId  uint             `json:"benchmarkId"`
+	Day          datatype.IntDate `json:"day"`
+	Value        float64          `json:"value"`
 }
 
 //=============================================================================
@@ -193,11 +448,21 @@ type DailyReturn struct {
 //===
 //=============================================================================
 
-func (TradingSystem) TableName() string { return "trading_system" }
-func (TradingFilter) TableName() string { return "trading_filter" }
-func (Trade)         TableName() string { return "trade"          }
-func (Portfolio)     TableName() string { return "portfolio"      }
-func (DailyReturn)   TableName() string { return "daily_return"   }
+func (TradingSystem)     TableName() string { return "trading_system"     }
+func (TradingFilter)     TableName() string { return "trading_filter"     }
+func (Trade)             TableName() string { return "trade"              }
+func (Portfolio)         TableName() string { return "portfolio"          }
+func (BrokerExecution)   TableName() string { return "broker_execution"   }
+func (TradeAudit)        TableName() string { return "trade_audit"        }
+func (RollPeriod)        TableName() string { return "roll_period"        }
+func (TradeConflict)     TableName() string { return "trade_conflict"     }
+func (RiskRule)          TableName() string { return "risk_rule"          }
+func (RiskEvent)         TableName() string { return "risk_event"         }
+func (PortfolioSnapshot) TableName() string { return "portfolio_snapshot" }
+func (SmartGroup)        TableName() string { return "smart_group"        }
+func (DailyReturn)       TableName() string { return "daily_return"       }
+func (Benchmark)         TableName() string { return "benchmark"          }
+func (BenchmarkReturn)   TableName() string { return "benchmark_return"   }
 
 //=============================================================================
 //===
@@ -0,0 +1,80 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package db
+
+import (
+	"github.com/tradalia/core/datatype"
+	"github.com/tradalia/core/req"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+func FindPortfolioSnapshots(tx *gorm.DB, portfolioId uint, fromDay datatype.IntDate, offset int, limit int) (*[]PortfolioSnapshot, error) {
+	var list []PortfolioSnapshot
+
+	query := "portfolio_id = ? and day >= ?"
+	res   := tx.Order("day desc").Offset(offset).Limit(limit).Find(&list, query, portfolioId, fromDay)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	return &list, nil
+}
+
+//=============================================================================
+
+func GetPortfolioSnapshotByDay(tx *gorm.DB, portfolioId uint, day datatype.IntDate) (*PortfolioSnapshot, error) {
+	var list []PortfolioSnapshot
+	res := tx.Find(&list, "portfolio_id = ? and day = ?", portfolioId, day)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	if len(list) == 1 {
+		return &list[0], nil
+	}
+
+	return nil, nil
+}
+
+//=============================================================================
+
+func AddPortfolioSnapshot(tx *gorm.DB, ps *PortfolioSnapshot) error {
+	err := tx.Create(ps).Error
+	return req.NewServerErrorByError(err)
+}
+
+//=============================================================================
+
+func UpdatePortfolioSnapshot(tx *gorm.DB, ps *PortfolioSnapshot) error {
+	err := tx.Save(ps).Error
+	return req.NewServerErrorByError(err)
+}
+
+//=============================================================================
@@ -43,3 +43,67 @@ func GetPortfolios(tx *gorm.DB, filter map[string]any, offset int, limit int) (*
 }
 
 //=============================================================================
+
+func GetPortfolioById(tx *gorm.DB, id uint) (*Portfolio, error) {
+	var list []Portfolio
+	res := tx.Find(&list, id)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	if len(list) == 1 {
+		return &list[0], nil
+	}
+
+	return nil, nil
+}
+
+//=============================================================================
+
+func GetPortfoliosByUser(tx *gorm.DB, username string) (*[]Portfolio, error) {
+	var list []Portfolio
+	res := tx.Find(&list, "username = ?", username)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	return &list, nil
+}
+
+//=============================================================================
+
+func FindPortfoliosByParentId(tx *gorm.DB, parentId uint) (*[]Portfolio, error) {
+	var list []Portfolio
+	res := tx.Order("id").Find(&list, "parent_id = ?", parentId)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	return &list, nil
+}
+
+//=============================================================================
+
+func AddPortfolio(tx *gorm.DB, p *Portfolio) error {
+	err := tx.Create(p).Error
+	return req.NewServerErrorByError(err)
+}
+
+//=============================================================================
+
+func UpdatePortfolio(tx *gorm.DB, p *Portfolio) error {
+	err := tx.Save(p).Error
+	return req.NewServerErrorByError(err)
+}
+
+//=============================================================================
+
+func DeletePortfolio(tx *gorm.DB, id uint) error {
+	err := tx.Delete(&Portfolio{}, id).Error
+	return req.NewServerErrorByError(err)
+}
+
+//=============================================================================
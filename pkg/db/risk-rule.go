@@ -0,0 +1,104 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package db
+
+import (
+	"github.com/tradalia/core/req"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+func FindRiskRulesByPortfolioId(tx *gorm.DB, portfolioId uint) (*[]RiskRule, error) {
+	var list []RiskRule
+	res := tx.Order("id").Find(&list, "portfolio_id = ?", portfolioId)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	return &list, nil
+}
+
+//=============================================================================
+
+func GetPortfolioIdsWithEnabledRiskRules(tx *gorm.DB) ([]uint, error) {
+	var list []uint
+	res := tx.Table("risk_rule").Where("enabled = ?", true).Order("portfolio_id").Distinct("portfolio_id").Scan(&list)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	return list, nil
+}
+
+//=============================================================================
+
+func GetRiskRuleById(tx *gorm.DB, id uint) (*RiskRule, error) {
+	var list []RiskRule
+	res := tx.Find(&list, id)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	if len(list) == 1 {
+		return &list[0], nil
+	}
+
+	return nil, nil
+}
+
+//=============================================================================
+
+func AddRiskRule(tx *gorm.DB, rr *RiskRule) error {
+	err := tx.Create(rr).Error
+	return req.NewServerErrorByError(err)
+}
+
+//=============================================================================
+
+func UpdateRiskRule(tx *gorm.DB, rr *RiskRule) error {
+	err := tx.Save(rr).Error
+	return req.NewServerErrorByError(err)
+}
+
+//=============================================================================
+
+func DeleteRiskRule(tx *gorm.DB, id uint) error {
+	err := tx.Delete(&RiskRule{}, id).Error
+	return req.NewServerErrorByError(err)
+}
+
+//=============================================================================
+
+func DeleteRiskRulesByPortfolioId(tx *gorm.DB, portfolioId uint) error {
+	err := tx.Delete(&RiskRule{}, "portfolio_id = ?", portfolioId).Error
+	return req.NewServerErrorByError(err)
+}
+
+//=============================================================================
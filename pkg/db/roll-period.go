@@ -0,0 +1,90 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package db
+
+import (
+	"github.com/tradalia/core/req"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+func GetRollPeriods(tx *gorm.DB, filter map[string]any, offset int, limit int) (*[]RollPeriod, error) {
+	var list []RollPeriod
+	res := tx.Where(filter).Order("data_symbol,from_date").Offset(offset).Limit(limit).Find(&list)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	return &list, nil
+}
+
+//=============================================================================
+
+func FindRollPeriodsByDataSymbol(tx *gorm.DB, username string, dataSymbol string) (*[]RollPeriod, error) {
+	var list []RollPeriod
+	res := tx.Order("from_date").Find(&list, "username = ? and data_symbol = ?", username, dataSymbol)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	return &list, nil
+}
+
+//=============================================================================
+
+func GetRollPeriodById(tx *gorm.DB, id uint) (*RollPeriod, error) {
+	var list []RollPeriod
+	res := tx.Find(&list, id)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	if len(list) == 1 {
+		return &list[0], nil
+	}
+
+	return nil, nil
+}
+
+//=============================================================================
+
+func AddRollPeriod(tx *gorm.DB, rp *RollPeriod) error {
+	err := tx.Create(rp).Error
+	return req.NewServerErrorByError(err)
+}
+
+//=============================================================================
+
+func DeleteRollPeriod(tx *gorm.DB, id uint) error {
+	err := tx.Delete(&RollPeriod{}, id).Error
+	return req.NewServerErrorByError(err)
+}
+
+//=============================================================================
@@ -0,0 +1,92 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package db
+
+import (
+	"github.com/tradalia/core/req"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+func FindTradeConflictsByTradingSystemId(tx *gorm.DB, tsId uint, status string) (*[]TradeConflict, error) {
+	var list []TradeConflict
+
+	filter := map[string]any{}
+	filter["trading_system_id"] = tsId
+
+	if status != "" {
+		filter["status"] = status
+	}
+
+	res := tx.Where(filter).Order("timestamp desc, id desc").Find(&list)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	return &list, nil
+}
+
+//=============================================================================
+
+func GetTradeConflictById(tx *gorm.DB, id uint) (*TradeConflict, error) {
+	var list []TradeConflict
+	res := tx.Find(&list, id)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	if len(list) == 1 {
+		return &list[0], nil
+	}
+
+	return nil, nil
+}
+
+//=============================================================================
+
+func AddTradeConflict(tx *gorm.DB, tc *TradeConflict) error {
+	err := tx.Create(tc).Error
+	return req.NewServerErrorByError(err)
+}
+
+//=============================================================================
+
+func UpdateTradeConflict(tx *gorm.DB, tc *TradeConflict) error {
+	err := tx.Save(tc).Error
+	return req.NewServerErrorByError(err)
+}
+
+//=============================================================================
+
+func DeleteAllTradeConflictsByTradingSystemId(tx *gorm.DB, id uint) error {
+	err := tx.Delete(&TradeConflict{}, "trading_system_id", id).Error
+	return req.NewServerErrorByError(err)
+}
+
+//=============================================================================
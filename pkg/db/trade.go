@@ -48,6 +48,21 @@ func FindTradesByTradingSystemId(tx *gorm.DB, tsId uint) (*[]Trade, error) {
 	return &list, nil
 }
 
+//=============================================================================
+//--- Excluded trades are kept in the database but must not be used for statistics
+
+func FindIncludedTradesByTradingSystemId(tx *gorm.DB, tsId uint) (*[]Trade, error) {
+	var list []Trade
+
+	res := tx.Order("entry_date,exit_date").Find(&list, "trading_system_id = ? and excluded = false", tsId)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	return &list, nil
+}
+
 //=============================================================================
 // We MUST order by entry_date because we may have cases like:
 // entry_date,       exit_date
@@ -71,7 +86,7 @@ func FindTradesByTsIdFromTime(tx *gorm.DB, tsId uint, fromTime *time.Time, toTim
 	var list []Trade
 
 	//--- WHERE condition must be exit_date otherwise we loose trades started in the past and ended after fromTime
-	query := "trading_system_id = ? and exit_date >= ? and exit_date<= ?"
+	query := "trading_system_id = ? and exit_date >= ? and exit_date<= ? and excluded = false"
 	res   := tx.Order("exit_date,entry_date").Find(&list, query, tsId, from, to)
 
 	if res.Error != nil {
@@ -86,7 +101,72 @@ func FindTradesByTsIdFromTime(tx *gorm.DB, tsId uint, fromTime *time.Time, toTim
 func FindTradesFromTime(tx *gorm.DB, tsIds []uint, fromTime time.Time) (*[]Trade, error) {
 	var list []Trade
 
-	res := tx.Find(&list, "trading_system_id in ? and entry_date >= ?", tsIds, fromTime)
+	res := tx.Find(&list, "trading_system_id in ? and exit_date >= ? and excluded = false", tsIds, fromTime)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	return &list, nil
+}
+
+//=============================================================================
+//--- Returns all trades (excluded ones too) whose exit date falls in [fromTime .. toTime]
+
+func FindTradesByTsIdInRange(tx *gorm.DB, tsId uint, fromTime time.Time, toTime time.Time) (*[]Trade, error) {
+	var list []Trade
+
+	query := "trading_system_id = ? and exit_date >= ? and exit_date <= ?"
+	res   := tx.Order("entry_date,exit_date").Find(&list, query, tsId, fromTime, toTime)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	return &list, nil
+}
+
+//=============================================================================
+
+func GetTradeById(tx *gorm.DB, id uint) (*Trade, error) {
+	var list []Trade
+	res := tx.Find(&list, id)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	if len(list) == 1 {
+		return &list[0], nil
+	}
+
+	return nil, nil
+}
+
+//=============================================================================
+
+func GetLastTradeByTsId(tx *gorm.DB, tsId uint) (*Trade, error) {
+	var list []Trade
+	res := tx.Order("entry_date desc,exit_date desc").Limit(1).Find(&list, "trading_system_id = ? and excluded = false", tsId)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	if len(list) == 1 {
+		return &list[0], nil
+	}
+
+	return nil, nil
+}
+
+//=============================================================================
+
+func FindTradesWithoutBrokerInfo(tx *gorm.DB, tsId uint) (*[]Trade, error) {
+	var list []Trade
+
+	query := "trading_system_id = ? and (entry_date_at_broker is null or exit_date_at_broker is null)"
+	res   := tx.Order("entry_date,exit_date").Find(&list, query, tsId)
 
 	if res.Error != nil {
 		return nil, req.NewServerErrorByError(res.Error)
@@ -104,6 +184,20 @@ func AddTrade(tx *gorm.DB, tr *Trade) error {
 
 //=============================================================================
 
+func UpdateTrade(tx *gorm.DB, tr *Trade) error {
+	err := tx.Save(tr).Error
+	return req.NewServerErrorByError(err)
+}
+
+//=============================================================================
+
+func DeleteTrade(tx *gorm.DB, id uint) error {
+	err := tx.Delete(&Trade{}, id).Error
+	return req.NewServerErrorByError(err)
+}
+
+//=============================================================================
+
 func DeleteAllTradesByTradingSystemId(tx *gorm.DB, id uint) error {
 	err := tx.Delete(&Trade{}, "trading_system_id", id).Error
 	return req.NewServerErrorByError(err)
@@ -127,12 +127,50 @@ func GetTradingSystemsInIdle(tx *gorm.DB, days int) (*[]TradingSystem, error) {
 
 //=============================================================================
 
+func GetRunningTradingSystems(tx *gorm.DB) (*[]TradingSystem, error) {
+	var list []TradingSystem
+
+	res := tx.Where("running = ?", true).Find(&list)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	return &list, nil
+}
+
+//=============================================================================
+
+func FindTradingSystemsByPortfolioIds(tx *gorm.DB, ids []uint) (*[]TradingSystem, error) {
+	var list []TradingSystem
+	res := tx.Order("id").Find(&list, "portfolio_id in ?", ids)
+
+	if res.Error != nil {
+		return nil, req.NewServerErrorByError(res.Error)
+	}
+
+	return &list, nil
+}
+
+//=============================================================================
+
 func UpdateTradingSystem(tx *gorm.DB, ts *TradingSystem) error {
 	return tx.Save(ts).Error
 }
 
 //=============================================================================
 
+func UpdateTradingSystemDivergence(tx *gorm.DB, id uint, divergent bool, action TsSuggAction) error {
+	return tx.Model(&TradingSystem{}).
+		Where("id", id).
+		Updates(map[string]interface{}{
+			"divergent"       : divergent,
+			"suggested_action": action,
+		}).Error
+}
+
+//=============================================================================
+
 func UpdateDataProductInfo(tx *gorm.DB, dataProductId uint, values map[string]interface{}) error {
 	return tx.Model(&TradingSystem{}).
 		Where("data_product_id", dataProductId).
@@ -0,0 +1,125 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package service
+
+import (
+	"github.com/tradalia/core/auth"
+	"github.com/tradalia/portfolio-trader/pkg/business"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+func getBenchmarks(c *auth.Context) {
+	filter := map[string]any{}
+	offset, limit, err := c.GetPagingParams()
+	if err == nil {
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			list, err := business.GetBenchmarks(tx, c, filter, offset, limit)
+			if err != nil {
+				return err
+			}
+			return c.ReturnList(list, offset, limit, len(*list))
+		})
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func addBenchmark(c *auth.Context) {
+	spec := business.BenchmarkSpec{}
+	err  := c.BindParamsFromBody(&spec)
+	if err == nil {
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			b, err := business.AddBenchmark(tx, c, &spec)
+			if err != nil {
+				return err
+			}
+			return c.ReturnObject(b)
+		})
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func deleteBenchmark(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+	if err == nil {
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			err = business.DeleteBenchmark(tx, c, id)
+			if err != nil {
+				return err
+			}
+			return c.ReturnObject(NewStatusOkResponse())
+		})
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func getBenchmarkReturns(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+	if err == nil {
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			list, err := business.GetBenchmarkReturns(tx, c, id)
+			if err != nil {
+				return err
+			}
+			return c.ReturnObject(list)
+		})
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func setBenchmarkReturns(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+	if err == nil {
+		req := business.BenchmarkReturnsRequest{}
+		err = c.BindParamsFromBody(&req)
+		if err == nil {
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				err = business.SetBenchmarkReturns(tx, c, id, &req)
+				if err != nil {
+					return err
+				}
+				return c.ReturnObject(NewStatusOkResponse())
+			})
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
@@ -0,0 +1,109 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/tradalia/core/auth"
+	"github.com/tradalia/core/req"
+	"github.com/tradalia/portfolio-trader/pkg/core/export"
+)
+
+//=============================================================================
+
+const (
+	MimeTypeCsv  = "text/csv"
+	MimeTypeXlsx = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+//=============================================================================
+//--- The format can be given with the 'format' parameter or through the Accept header
+
+func getExportFormat(c *auth.Context) (string, error) {
+	accept := c.Gin.GetHeader("Accept")
+	defFormat := export.FormatJson
+
+	if strings.Contains(accept, MimeTypeCsv) {
+		defFormat = export.FormatCsv
+	} else if strings.Contains(accept, MimeTypeXlsx) {
+		defFormat = export.FormatXlsx
+	}
+
+	format := c.GetParamAsString("format", defFormat)
+
+	if !export.IsValidFormat(format) {
+		return "", req.NewBadRequestError("Invalid format parameter: %s", format)
+	}
+
+	return format, nil
+}
+
+//=============================================================================
+//--- CSV returns only one table (selected with the 'table' parameter, default is the
+//--- first one) while XLSX returns all tables, one per sheet
+
+func returnTables(c *auth.Context, format string, fileName string, tables []*export.Table) error {
+	var buf bytes.Buffer
+
+	if len(tables) == 0 {
+		return req.NewNotFoundError("There is no data to export")
+	}
+
+	if format == export.FormatCsv {
+		name  := c.GetParamAsString("table", tables[0].Name)
+		table := export.FindTable(tables, name)
+
+		if table == nil {
+			return req.NewBadRequestError("Unknown table: %s", name)
+		}
+
+		err := export.WriteCsv(&buf, table)
+		if err != nil {
+			return err
+		}
+
+		setAttachment(c, fmt.Sprintf("%s-%s.csv", fileName, table.Name))
+		return c.ReturnData(MimeTypeCsv, buf.Bytes())
+	}
+
+	err := export.WriteXlsx(&buf, tables)
+	if err != nil {
+		return err
+	}
+
+	setAttachment(c, fileName +".xlsx")
+	return c.ReturnData(MimeTypeXlsx, buf.Bytes())
+}
+
+//=============================================================================
+
+func setAttachment(c *auth.Context, fileName string) {
+	c.Gin.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", fileName))
+}
+
+//=============================================================================
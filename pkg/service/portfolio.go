@@ -25,8 +25,13 @@ THE SOFTWARE.
 package service
 
 import (
+	"fmt"
+
 	"github.com/tradalia/core/auth"
 	"github.com/tradalia/portfolio-trader/pkg/business"
+	"github.com/tradalia/portfolio-trader/pkg/business/allocation"
+	"github.com/tradalia/portfolio-trader/pkg/business/performance"
+	"github.com/tradalia/portfolio-trader/pkg/core/export"
 	"github.com/tradalia/portfolio-trader/pkg/db"
 	"gorm.io/gorm"
 )
@@ -75,18 +80,143 @@ func getPortfolioTree(c *auth.Context) {
 
 //=============================================================================
 
+func addPortfolio(c *auth.Context) {
+	spec := business.PortfolioSpec{}
+	err  := c.BindParamsFromBody(&spec)
+
+	if err == nil {
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			p, err := business.AddPortfolio(tx, c, &spec)
+
+			if err != nil {
+				return err
+			}
+
+			return c.ReturnObject(p)
+		})
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func updatePortfolio(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		spec := business.PortfolioSpec{}
+		err = c.BindParamsFromBody(&spec)
+
+		if err == nil {
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				p, err := business.UpdatePortfolio(tx, c, id, &spec)
+
+				if err != nil {
+					return err
+				}
+
+				return c.ReturnObject(p)
+			})
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func deletePortfolio(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			err := business.DeletePortfolio(tx, c, id)
+
+			if err != nil {
+				return err
+			}
+
+			return c.ReturnObject(NewStatusOkResponse())
+		})
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func assignTradingSystem(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var tsId uint
+		tsId, err = c.GetId2FromUrl()
+
+		if err == nil {
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				ts, err := business.AssignTradingSystem(tx, c, id, tsId)
+
+				if err != nil {
+					return err
+				}
+
+				return c.ReturnObject(ts)
+			})
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func unassignTradingSystem(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var tsId uint
+		tsId, err = c.GetId2FromUrl()
+
+		if err == nil {
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				ts, err := business.UnassignTradingSystem(tx, c, id, tsId)
+
+				if err != nil {
+					return err
+				}
+
+				return c.ReturnObject(ts)
+			})
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
 func getPortfolioMonitoring(c *auth.Context) {
 	params := business.PortfolioMonitoringParams{}
 	err    := c.BindParamsFromBody(&params)
 
+	var format string
+	if err == nil {
+		format, err = getExportFormat(c)
+	}
+
 	if err == nil {
 		err = db.RunInTransaction(func(tx *gorm.DB) error {
-			result, err := business.GetPortfolioMonitoring(tx, &params)
+			result, err := business.GetPortfolioMonitoring(tx, c, &params)
 
 			if err != nil {
 				return err
 			}
 
+			if format != export.FormatJson {
+				return returnTables(c, format, "portfolio-monitoring", business.PortfolioMonitoringTables(result))
+			}
+
 			return c.ReturnObject(result)
 		})
 	}
@@ -95,3 +225,236 @@ func getPortfolioMonitoring(c *auth.Context) {
 }
 
 //=============================================================================
+
+func runPortfolioPerformanceAnalysis(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		req := performance.AnalysisRequest{}
+		err = c.BindParamsFromBody(&req)
+
+		var format string
+		if err == nil {
+			format, err = getExportFormat(c)
+		}
+
+		if err == nil {
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				res, err := business.RunPortfolioPerformanceAnalysis(tx, c, id, &req)
+
+				if err != nil {
+					return err
+				}
+
+				if format != export.FormatJson {
+					return returnTables(c, format, fmt.Sprintf("portfolio-analysis-%d", id), business.PortfolioAnalysisTables(res))
+				}
+
+				return c.ReturnObject(res)
+			})
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func getPortfolioFactsheet(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		req := performance.AnalysisRequest{}
+		err = c.BindParamsFromBody(&req)
+
+		if err == nil {
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				data, err := business.GetPortfolioFactsheet(tx, c, id, &req)
+
+				if err != nil {
+					return err
+				}
+
+				setAttachment(c, fmt.Sprintf("portfolio-factsheet-%d.html", id))
+				return c.ReturnData("text/html; charset=utf-8", data)
+			})
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func getCorrelation(c *auth.Context) {
+	req := business.CorrelationRequest{}
+	err := c.BindParamsFromBody(&req)
+
+	if err == nil {
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			res, err := business.GetCorrelation(tx, c, &req)
+
+			if err != nil {
+				return err
+			}
+
+			return c.ReturnObject(res)
+		})
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func getPortfolioSnapshots(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var offset, limit int
+		offset, limit, err = c.GetPagingParams()
+
+		if err == nil {
+			var daysBack int
+			daysBack, err = c.GetParamAsInt("daysBack", 0)
+
+			if err == nil {
+				err = db.RunInTransaction(func(tx *gorm.DB) error {
+					list, err := business.GetPortfolioSnapshots(tx, c, id, daysBack, offset, limit)
+
+					if err != nil {
+						return err
+					}
+
+					return c.ReturnList(list, offset, limit, len(*list))
+				})
+			}
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func takePortfolioSnapshot(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			ps, err := business.TakePortfolioSnapshot(tx, c, id)
+
+			if err != nil {
+				return err
+			}
+
+			return c.ReturnObject(ps)
+		})
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func simulatePortfolio(c *auth.Context) {
+	req := business.SimulationRequest{}
+	err := c.BindParamsFromBody(&req)
+
+	if err == nil {
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			res, err := business.SimulatePortfolio(tx, c, &req)
+
+			if err != nil {
+				return err
+			}
+
+			return c.ReturnObject(res)
+		})
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func startAllocationOptimization(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		req := allocation.OptimizationRequest{}
+		err = c.BindParamsFromBody(&req)
+
+		if err == nil {
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				err := business.StartAllocationOptimization(tx, c, id, &req)
+
+				if err != nil {
+					return err
+				}
+
+				return c.ReturnObject(NewStatusOkResponse())
+			})
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func stopAllocationOptimization(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			err := business.StopAllocationOptimization(tx, c, id)
+
+			if err != nil {
+				return err
+			}
+
+			return c.ReturnObject(NewStatusOkResponse())
+		})
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func getAllocationOptimizationInfo(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			res, err := business.GetAllocationOptimizationInfo(tx, c, id)
+
+			if err != nil {
+				return err
+			}
+
+			return c.ReturnObject(res)
+		})
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func getExposure(c *auth.Context) {
+	err := db.RunInTransaction(func(tx *gorm.DB) error {
+		res, err := business.GetExposure(tx, c)
+
+		if err != nil {
+			return err
+		}
+
+		return c.ReturnObject(res)
+	})
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
@@ -0,0 +1,214 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package service
+
+import (
+	"github.com/tradalia/core/auth"
+	"github.com/tradalia/portfolio-trader/pkg/business"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+func getRiskRules(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			list, err := business.GetRiskRules(tx, c, id)
+
+			if err != nil {
+				return err
+			}
+
+			return c.ReturnList(list, 0, len(*list), len(*list))
+		})
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func addRiskRule(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		spec := business.RiskRuleSpec{}
+		err = c.BindParamsFromBody(&spec)
+
+		if err == nil {
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				rr, err := business.AddRiskRule(tx, c, id, &spec)
+
+				if err != nil {
+					return err
+				}
+
+				return c.ReturnObject(rr)
+			})
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func updateRiskRule(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var ruleId uint
+		ruleId, err = c.GetId2FromUrl()
+
+		if err == nil {
+			spec := business.RiskRuleSpec{}
+			err = c.BindParamsFromBody(&spec)
+
+			if err == nil {
+				err = db.RunInTransaction(func(tx *gorm.DB) error {
+					rr, err := business.UpdateRiskRule(tx, c, id, ruleId, &spec)
+
+					if err != nil {
+						return err
+					}
+
+					return c.ReturnObject(rr)
+				})
+			}
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func resetRiskRule(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var ruleId uint
+		ruleId, err = c.GetId2FromUrl()
+
+		if err == nil {
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				rr, err := business.ResetRiskRule(tx, c, id, ruleId)
+
+				if err != nil {
+					return err
+				}
+
+				return c.ReturnObject(rr)
+			})
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func deleteRiskRule(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var ruleId uint
+		ruleId, err = c.GetId2FromUrl()
+
+		if err == nil {
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				err := business.DeleteRiskRule(tx, c, id, ruleId)
+
+				if err != nil {
+					return err
+				}
+
+				return c.ReturnObject(NewStatusOkResponse())
+			})
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func getRiskEvents(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var offset, limit int
+		offset, limit, err = c.GetPagingParams()
+
+		if err == nil {
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				list, err := business.GetRiskEvents(tx, c, id, offset, limit)
+
+				if err != nil {
+					return err
+				}
+
+				return c.ReturnList(list, offset, limit, len(*list))
+			})
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func checkRiskRules(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var events []*db.RiskEvent
+
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			var err error
+			events, err = business.CheckPortfolioRiskRules(tx, c, id)
+			return err
+		})
+
+		if err == nil {
+			business.PublishRiskEvents(events)
+
+			if events == nil {
+				events = []*db.RiskEvent{}
+			}
+
+			_ = c.ReturnObject(events)
+			return
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
@@ -0,0 +1,93 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package service
+
+import (
+	"github.com/tradalia/core/auth"
+	"github.com/tradalia/portfolio-trader/pkg/business"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+func getRollPeriods(c *auth.Context) {
+	filter := map[string]any{}
+	offset, limit, err := c.GetPagingParams()
+	if err == nil {
+		dataSymbol := c.GetParamAsString("dataSymbol", "")
+		if dataSymbol != "" {
+			filter["data_symbol"] = dataSymbol
+		}
+
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			list, err := business.GetRollPeriods(tx, c, filter, offset, limit)
+			if err != nil {
+				return err
+			}
+			return c.ReturnList(list, offset, limit, len(*list))
+		})
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func addRollPeriod(c *auth.Context) {
+	spec := business.RollPeriodSpec{}
+	err  := c.BindParamsFromBody(&spec)
+	if err == nil {
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			rp, err := business.AddRollPeriod(tx, c, &spec)
+			if err != nil {
+				return err
+			}
+			return c.ReturnObject(rp)
+		})
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func deleteRollPeriod(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+	if err == nil {
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			err = business.DeleteRollPeriod(tx, c, id)
+			if err != nil {
+				return err
+			}
+			return c.ReturnObject(NewStatusOkResponse())
+		})
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
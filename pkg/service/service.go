@@ -42,6 +42,18 @@ func Init(router *gin.Engine, cfg *app.Config, logger *slog.Logger) {
 	router.GET   ("/api/portfolio/v1/trading-systems",                         ctrl.Secure(getTradingSystems,         roles.Admin_User_Service))
 	router.GET   ("/api/portfolio/v1/trading-systems/:id/trades",              ctrl.Secure(getTrades,                 roles.Admin_User_Service))
 	router.DELETE("/api/portfolio/v1/trading-systems/:id/trades",              ctrl.Secure(deleteTrades,                 roles.Admin_User_Service))
+	router.POST  ("/api/portfolio/v1/trading-systems/:id/trades/import",       ctrl.Secure(importTrades,              roles.Admin_User_Service))
+	router.POST  ("/api/portfolio/v1/trading-systems/:id/trades/delete-range", ctrl.Secure(deleteTradesInRange,       roles.Admin_User_Service))
+	router.GET   ("/api/portfolio/v1/trading-systems/:id/trades/audit",        ctrl.Secure(getTradeAudits,            roles.Admin_User_Service))
+	router.PUT   ("/api/portfolio/v1/trading-systems/:id/trades/:id2",         ctrl.Secure(updateTrade,               roles.Admin_User_Service))
+	router.DELETE("/api/portfolio/v1/trading-systems/:id/trades/:id2",         ctrl.Secure(deleteTrade,               roles.Admin_User_Service))
+	router.POST  ("/api/portfolio/v1/trading-systems/:id/trades/:id2/excluded",ctrl.Secure(setTradeExcluded,          roles.Admin_User_Service))
+	router.GET   ("/api/portfolio/v1/trading-systems/:id/trade-conflicts",     ctrl.Secure(getTradeConflicts,         roles.Admin_User_Service))
+	router.POST  ("/api/portfolio/v1/trading-systems/:id/trade-conflicts/:id2",ctrl.Secure(resolveTradeConflict,      roles.Admin_User_Service))
+	router.POST  ("/api/portfolio/v1/trading-systems/:id/daily-returns",       ctrl.Secure(recomputeDailyReturns,     roles.Admin_User_Service))
+
+	router.GET   ("/api/portfolio/v1/trading-systems/:id/executions",          ctrl.Secure(getExecutions,             roles.Admin_User_Service))
+	router.POST  ("/api/portfolio/v1/trading-systems/:id/executions",          ctrl.Secure(addExecutions,             roles.Admin_User_Service))
 	router.GET   ("/api/portfolio/v1/trading-systems/:id/filters",             ctrl.Secure(getTradingFilters,         roles.Admin_User_Service))
 	router.POST  ("/api/portfolio/v1/trading-systems/:id/filters",             ctrl.Secure(setTradingFilters,         roles.Admin_User_Service))
 	router.POST  ("/api/portfolio/v1/trading-systems/:id/filter-analysis",     ctrl.Secure(runFilterAnalysis,         roles.Admin_User_Service))
@@ -50,6 +62,8 @@ func Init(router *gin.Engine, cfg *app.Config, logger *slog.Logger) {
 	router.POST  ("/api/portfolio/v1/trading-systems/:id/activation",          ctrl.Secure(setTradingSystemActivation,roles.Admin_User_Service))
 	router.POST  ("/api/portfolio/v1/trading-systems/:id/active",              ctrl.Secure(setTradingSystemActive,    roles.Admin_User_Service))
 	router.POST  ("/api/portfolio/v1/trading-systems/:id/performance-analysis",ctrl.Secure(runPerformanceAnalysis,    roles.Admin_User_Service))
+	router.POST  ("/api/portfolio/v1/trading-systems/:id/factsheet",           ctrl.Secure(getTradingSystemFactsheet, roles.Admin_User_Service))
+	router.GET   ("/api/portfolio/v1/trading-systems/:id/divergence",          ctrl.Secure(getDivergence,             roles.Admin_User_Service))
 
 	router.GET   ("/api/portfolio/v1/trading-systems/:id/filter-optimization", ctrl.Secure(getFilterOptimizationInfo, roles.Admin_User_Service))
 	router.POST  ("/api/portfolio/v1/trading-systems/:id/filter-optimization", ctrl.Secure(startFilterOptimization,   roles.Admin_User_Service))
@@ -57,7 +71,48 @@ func Init(router *gin.Engine, cfg *app.Config, logger *slog.Logger) {
 
 	router.GET   ("/api/inventory/v1/portfolios",                              ctrl.Secure(getPortfolios,             roles.Admin_User_Service))
 	router.GET   ("/api/inventory/v1/portfolio/tree",                          ctrl.Secure(getPortfolioTree,          roles.Admin_User_Service))
+	router.POST  ("/api/portfolio/v1/portfolios",                             ctrl.Secure(addPortfolio,              roles.Admin_User_Service))
+	router.PUT   ("/api/portfolio/v1/portfolios/:id",                         ctrl.Secure(updatePortfolio,           roles.Admin_User_Service))
+	router.DELETE("/api/portfolio/v1/portfolios/:id",                         ctrl.Secure(deletePortfolio,           roles.Admin_User_Service))
+	router.PUT   ("/api/portfolio/v1/portfolios/:id/trading-systems/:id2",    ctrl.Secure(assignTradingSystem,       roles.Admin_User_Service))
+	router.DELETE("/api/portfolio/v1/portfolios/:id/trading-systems/:id2",    ctrl.Secure(unassignTradingSystem,     roles.Admin_User_Service))
 	router.POST  ("/api/portfolio/v1/portfolio/monitoring",                    ctrl.Secure(getPortfolioMonitoring,    roles.Admin_User_Service))
+	router.POST  ("/api/portfolio/v1/portfolios/:id/performance-analysis",     ctrl.Secure(runPortfolioPerformanceAnalysis, roles.Admin_User_Service))
+	router.POST  ("/api/portfolio/v1/portfolios/:id/factsheet",                ctrl.Secure(getPortfolioFactsheet,     roles.Admin_User_Service))
+	router.GET   ("/api/portfolio/v1/portfolios/:id/allocation-optimization",  ctrl.Secure(getAllocationOptimizationInfo, roles.Admin_User_Service))
+	router.POST  ("/api/portfolio/v1/portfolios/:id/allocation-optimization",  ctrl.Secure(startAllocationOptimization,   roles.Admin_User_Service))
+	router.DELETE("/api/portfolio/v1/portfolios/:id/allocation-optimization",  ctrl.Secure(stopAllocationOptimization,    roles.Admin_User_Service))
+	router.GET   ("/api/portfolio/v1/portfolios/:id/risk-rules",               ctrl.Secure(getRiskRules,              roles.Admin_User_Service))
+	router.POST  ("/api/portfolio/v1/portfolios/:id/risk-rules",               ctrl.Secure(addRiskRule,               roles.Admin_User_Service))
+	router.PUT   ("/api/portfolio/v1/portfolios/:id/risk-rules/:id2",          ctrl.Secure(updateRiskRule,            roles.Admin_User_Service))
+	router.DELETE("/api/portfolio/v1/portfolios/:id/risk-rules/:id2",          ctrl.Secure(deleteRiskRule,            roles.Admin_User_Service))
+	router.POST  ("/api/portfolio/v1/portfolios/:id/risk-rules/:id2/reset",    ctrl.Secure(resetRiskRule,             roles.Admin_User_Service))
+	router.POST  ("/api/portfolio/v1/portfolios/:id/risk-check",               ctrl.Secure(checkRiskRules,            roles.Admin_User_Service))
+	router.GET   ("/api/portfolio/v1/portfolios/:id/risk-events",              ctrl.Secure(getRiskEvents,             roles.Admin_User_Service))
+	router.GET   ("/api/portfolio/v1/portfolios/:id/snapshots",                ctrl.Secure(getPortfolioSnapshots,     roles.Admin_User_Service))
+	router.POST  ("/api/portfolio/v1/portfolios/:id/snapshots",                ctrl.Secure(takePortfolioSnapshot,     roles.Admin_User_Service))
+	router.POST  ("/api/portfolio/v1/correlation",                             ctrl.Secure(getCorrelation,            roles.Admin_User_Service))
+	router.POST  ("/api/portfolio/v1/simulation",                              ctrl.Secure(simulatePortfolio,         roles.Admin_User_Service))
+	router.POST  ("/api/portfolio/v1/performance-aggregation",                 ctrl.Secure(getPerformanceAggregation, roles.Admin_User_Service))
+	router.GET   ("/api/portfolio/v1/exposure",                                ctrl.Secure(getExposure,               roles.Admin_User_Service))
+	router.POST  ("/api/portfolio/v1/daily-returns",                           ctrl.Secure(recomputeAllDailyReturns,  roles.Admin_User_Service))
+
+	router.GET   ("/api/portfolio/v1/smart-groups",                            ctrl.Secure(getSmartGroups,            roles.Admin_User_Service))
+	router.POST  ("/api/portfolio/v1/smart-groups",                            ctrl.Secure(addSmartGroup,             roles.Admin_User_Service))
+	router.PUT   ("/api/portfolio/v1/smart-groups/:id",                        ctrl.Secure(updateSmartGroup,          roles.Admin_User_Service))
+	router.DELETE("/api/portfolio/v1/smart-groups/:id",                        ctrl.Secure(deleteSmartGroup,          roles.Admin_User_Service))
+	router.GET   ("/api/portfolio/v1/smart-groups/:id/trading-systems",        ctrl.Secure(getSmartGroupTradingSystems, roles.Admin_User_Service))
+	router.POST  ("/api/portfolio/v1/smart-groups/:id/performance-analysis",   ctrl.Secure(runSmartGroupPerformanceAnalysis, roles.Admin_User_Service))
+
+	router.GET   ("/api/portfolio/v1/benchmarks",                              ctrl.Secure(getBenchmarks,             roles.Admin_User_Service))
+	router.POST  ("/api/portfolio/v1/benchmarks",                              ctrl.Secure(addBenchmark,              roles.Admin_User_Service))
+	router.DELETE("/api/portfolio/v1/benchmarks/:id",                          ctrl.Secure(deleteBenchmark,           roles.Admin_User_Service))
+	router.GET   ("/api/portfolio/v1/benchmarks/:id/returns",                  ctrl.Secure(getBenchmarkReturns,       roles.Admin_User_Service))
+	router.POST  ("/api/portfolio/v1/benchmarks/:id/returns",                  ctrl.Secure(setBenchmarkReturns,       roles.Admin_User_Service))
+
+	router.GET   ("/api/portfolio/v1/roll-periods",                            ctrl.Secure(getRollPeriods,            roles.Admin_User_Service))
+	router.POST  ("/api/portfolio/v1/roll-periods",                            ctrl.Secure(addRollPeriod,             roles.Admin_User_Service))
+	router.DELETE("/api/portfolio/v1/roll-periods/:id",                        ctrl.Secure(deleteRollPeriod,          roles.Admin_User_Service))
 }
 
 //=============================================================================
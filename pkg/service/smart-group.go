@@ -0,0 +1,201 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package service
+
+import (
+	"fmt"
+
+	"github.com/tradalia/core/auth"
+	"github.com/tradalia/portfolio-trader/pkg/business"
+	"github.com/tradalia/portfolio-trader/pkg/business/performance"
+	"github.com/tradalia/portfolio-trader/pkg/core/export"
+	"github.com/tradalia/portfolio-trader/pkg/db"
+	"gorm.io/gorm"
+)
+
+//=============================================================================
+
+func getSmartGroups(c *auth.Context) {
+	filter := map[string]any{}
+	offset, limit, err := c.GetPagingParams()
+
+	if err == nil {
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			list, err := business.GetSmartGroups(tx, c, filter, offset, limit)
+
+			if err != nil {
+				return err
+			}
+
+			return c.ReturnList(list, offset, limit, len(*list))
+		})
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func addSmartGroup(c *auth.Context) {
+	spec := business.SmartGroupSpec{}
+	err  := c.BindParamsFromBody(&spec)
+
+	if err == nil {
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			sg, err := business.AddSmartGroup(tx, c, &spec)
+
+			if err != nil {
+				return err
+			}
+
+			return c.ReturnObject(sg)
+		})
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func updateSmartGroup(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		spec := business.SmartGroupSpec{}
+		err = c.BindParamsFromBody(&spec)
+
+		if err == nil {
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				sg, err := business.UpdateSmartGroup(tx, c, id, &spec)
+
+				if err != nil {
+					return err
+				}
+
+				return c.ReturnObject(sg)
+			})
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func deleteSmartGroup(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			err := business.DeleteSmartGroup(tx, c, id)
+
+			if err != nil {
+				return err
+			}
+
+			return c.ReturnObject(NewStatusOkResponse())
+		})
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func getSmartGroupTradingSystems(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			list, err := business.GetSmartGroupTradingSystems(tx, c, id)
+
+			if err != nil {
+				return err
+			}
+
+			return c.ReturnList(&list, 0, len(list), len(list))
+		})
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func runSmartGroupPerformanceAnalysis(c *auth.Context) {
+	id, err := c.GetIdFromUrl()
+
+	if err == nil {
+		req := performance.AnalysisRequest{}
+		err = c.BindParamsFromBody(&req)
+
+		var format string
+		if err == nil {
+			format, err = getExportFormat(c)
+		}
+
+		if err == nil {
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				res, err := business.RunSmartGroupPerformanceAnalysis(tx, c, id, &req)
+
+				if err != nil {
+					return err
+				}
+
+				if format != export.FormatJson {
+					return returnTables(c, format, fmt.Sprintf("smart-group-analysis-%d", id), business.PortfolioAnalysisTables(res))
+				}
+
+				return c.ReturnObject(res)
+			})
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func getPerformanceAggregation(c *auth.Context) {
+	req := business.AggregationRequest{}
+	err := c.BindParamsFromBody(&req)
+
+	if err == nil {
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			res, err := business.GetPerformanceAggregation(tx, c, &req)
+
+			if err != nil {
+				return err
+			}
+
+			return c.ReturnObject(res)
+		})
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
@@ -25,10 +25,13 @@ THE SOFTWARE.
 package service
 
 import (
+	"fmt"
+
 	"github.com/tradalia/core/auth"
 	"github.com/tradalia/portfolio-trader/pkg/business"
 	"github.com/tradalia/portfolio-trader/pkg/business/filter"
 	"github.com/tradalia/portfolio-trader/pkg/business/performance"
+	"github.com/tradalia/portfolio-trader/pkg/core/export"
 	"github.com/tradalia/portfolio-trader/pkg/db"
 	"gorm.io/gorm"
 )
@@ -63,9 +66,170 @@ func getTradingSystems(c *auth.Context) {
 func getTrades(c *auth.Context) {
 	tsId, err := c.GetIdFromUrl()
 
+	if err == nil {
+		var format string
+		format, err = getExportFormat(c)
+
+		if err == nil {
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				list, err := business.GetTrades(tx, c, tsId)
+
+				if err != nil {
+					return err
+				}
+
+				if format != export.FormatJson {
+					return returnTables(c, format, fmt.Sprintf("trades-%d", tsId), business.TradesTables(list))
+				}
+
+				return c.ReturnObject(&list)
+			})
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func deleteTrades(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			err = business.DeleteTrades(tx, c, tsId)
+
+			if err != nil {
+				return err
+			}
+
+			return c.ReturnObject("")
+		})
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func deleteTradesInRange(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		req := business.TradeRangeDeleteRequest{}
+		err = c.BindParamsFromBody(&req)
+
+		if err == nil {
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				rep, err := business.DeleteTradesInRange(tx, c, tsId, &req)
+
+				if err != nil {
+					return err
+				}
+
+				return c.ReturnObject(rep)
+			})
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func updateTrade(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var tradeId uint
+		tradeId, err = c.GetId2FromUrl()
+
+		if err == nil {
+			req := business.TradeUpdateRequest{}
+			err = c.BindParamsFromBody(&req)
+
+			if err == nil {
+				err = db.RunInTransaction(func(tx *gorm.DB) error {
+					tr, err := business.UpdateTrade(tx, c, tsId, tradeId, &req)
+
+					if err != nil {
+						return err
+					}
+
+					return c.ReturnObject(tr)
+				})
+			}
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func deleteTrade(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var tradeId uint
+		tradeId, err = c.GetId2FromUrl()
+
+		if err == nil {
+			reason := c.GetParamAsString("reason", "")
+
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				err = business.DeleteTrade(tx, c, tsId, tradeId, reason)
+
+				if err != nil {
+					return err
+				}
+
+				return c.ReturnObject("")
+			})
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func setTradeExcluded(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var tradeId uint
+		tradeId, err = c.GetId2FromUrl()
+
+		if err == nil {
+			req := business.TradeExclusionRequest{}
+			err = c.BindParamsFromBody(&req)
+
+			if err == nil {
+				err = db.RunInTransaction(func(tx *gorm.DB) error {
+					tr, err := business.SetTradeExcluded(tx, c, tsId, tradeId, &req)
+
+					if err != nil {
+						return err
+					}
+
+					return c.ReturnObject(tr)
+				})
+			}
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func getTradeAudits(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
 	if err == nil {
 		err = db.RunInTransaction(func(tx *gorm.DB) error {
-			list, err := business.GetTrades(tx, c, tsId)
+			list, err := business.GetTradeAudits(tx, c, tsId)
 
 			if err != nil {
 				return err
@@ -80,18 +244,20 @@ func getTrades(c *auth.Context) {
 
 //=============================================================================
 
-func deleteTrades(c *auth.Context) {
+func getTradeConflicts(c *auth.Context) {
 	tsId, err := c.GetIdFromUrl()
 
 	if err == nil {
+		status := c.GetParamAsString("status", "")
+
 		err = db.RunInTransaction(func(tx *gorm.DB) error {
-			err = business.DeleteTrades(tx, c, tsId)
+			list, err := business.GetTradeConflicts(tx, c, tsId, status)
 
 			if err != nil {
 				return err
 			}
 
-			return c.ReturnObject("")
+			return c.ReturnObject(&list)
 		})
 	}
 
@@ -100,6 +266,36 @@ func deleteTrades(c *auth.Context) {
 
 //=============================================================================
 
+func resolveTradeConflict(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var conflictId uint
+		conflictId, err = c.GetId2FromUrl()
+
+		if err == nil {
+			req := business.TradeConflictResolveRequest{}
+			err = c.BindParamsFromBody(&req)
+
+			if err == nil {
+				err = db.RunInTransaction(func(tx *gorm.DB) error {
+					tc, err := business.ResolveTradeConflict(tx, c, tsId, conflictId, &req)
+
+					if err != nil {
+						return err
+					}
+
+					return c.ReturnObject(tc)
+				})
+			}
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
 func getTradingFilters(c *auth.Context) {
 	tsId, err := c.GetIdFromUrl()
 
@@ -152,6 +348,11 @@ func runFilterAnalysis(c *auth.Context) {
 		req := filter.AnalysisRequest{}
 		err = c.BindParamsFromBody(&req)
 
+		var format string
+		if err == nil {
+			format, err = getExportFormat(c)
+		}
+
 		if err == nil {
 			err = db.RunInTransaction(func(tx *gorm.DB) error {
 				rep, err := business.RunFilterAnalysis(tx, c, tsId, &req)
@@ -160,6 +361,10 @@ func runFilterAnalysis(c *auth.Context) {
 					return err
 				}
 
+				if format != export.FormatJson {
+					return returnTables(c, format, fmt.Sprintf("filter-analysis-%d", tsId), business.FilterAnalysisTables(rep))
+				}
+
 				return c.ReturnObject(rep)
 			})
 		}
@@ -218,13 +423,24 @@ func stopFilterOptimization(c *auth.Context) {
 func getFilterOptimizationInfo(c *auth.Context) {
 	tsId, err := c.GetIdFromUrl()
 
+	var format string
+	if err == nil {
+		format, err = getExportFormat(c)
+	}
+
 	if err == nil {
 		res, err := business.GetFilterOptimizationInfo(c, tsId)
 
+		if err == nil {
+			if format != export.FormatJson {
+				err = returnTables(c, format, fmt.Sprintf("filter-optimization-%d", tsId), business.FilterOptimizationTables(res))
+			} else {
+				_ = c.ReturnObject(res)
+			}
+		}
+
 		if err != nil {
 			c.ReturnError(err)
-		} else {
-			_ = c.ReturnObject(res)
 		}
 
 		return
@@ -242,6 +458,11 @@ func runPerformanceAnalysis(c *auth.Context) {
 		req := performance.AnalysisRequest{}
 		err = c.BindParamsFromBody(&req)
 
+		var format string
+		if err == nil {
+			format, err = getExportFormat(c)
+		}
+
 		if err == nil {
 			err = db.RunInTransaction(func(tx *gorm.DB) error {
 				res, err := business.RunPerformanceAnalysis(tx, c, tsId, &req)
@@ -250,6 +471,10 @@ func runPerformanceAnalysis(c *auth.Context) {
 					return err
 				}
 
+				if format != export.FormatJson {
+					return returnTables(c, format, fmt.Sprintf("performance-analysis-%d", tsId), business.PerformanceAnalysisTables(res))
+				}
+
 				return c.ReturnObject(res)
 			})
 		}
@@ -259,3 +484,150 @@ func runPerformanceAnalysis(c *auth.Context) {
 }
 
 //=============================================================================
+
+func getExecutions(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+	if err == nil {
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			list, err := business.GetExecutions(tx, c, tsId)
+			if err != nil {
+				return err
+			}
+			return c.ReturnObject(list)
+		})
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func addExecutions(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+	if err == nil {
+		req := business.ExecutionsRequest{}
+		err = c.BindParamsFromBody(&req)
+		if err == nil {
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				rep, err := business.AddExecutions(tx, c, tsId, &req)
+				if err != nil {
+					return err
+				}
+				return c.ReturnObject(rep)
+			})
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func getDivergence(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+	if err == nil {
+		err = db.RunInTransaction(func(tx *gorm.DB) error {
+			rep, err := business.GetDivergence(tx, c, tsId)
+			if err != nil {
+				return err
+			}
+			return c.ReturnObject(rep)
+		})
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func getTradingSystemFactsheet(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		req := performance.AnalysisRequest{}
+		err = c.BindParamsFromBody(&req)
+
+		if err == nil {
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				data, err := business.GetTradingSystemFactsheet(tx, c, tsId, &req)
+
+				if err != nil {
+					return err
+				}
+
+				setAttachment(c, fmt.Sprintf("factsheet-%d.html", tsId))
+				return c.ReturnData("text/html; charset=utf-8", data)
+			})
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func importTrades(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		req := business.TradeImportRequest{}
+		err = c.BindParamsFromBody(&req)
+
+		if err == nil {
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				rep, err := business.ImportTrades(tx, c, tsId, &req)
+
+				if err != nil {
+					return err
+				}
+
+				return c.ReturnObject(rep)
+			})
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func recomputeDailyReturns(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		req := business.DailyReturnsRequest{}
+		err = c.BindParamsFromBody(&req)
+
+		if err == nil {
+			err = db.RunInTransaction(func(tx *gorm.DB) error {
+				rep, err := business.RecomputeDailyReturns(tx, c, tsId, &req)
+
+				if err != nil {
+					return err
+				}
+
+				return c.ReturnObject(rep)
+			})
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func recomputeAllDailyReturns(c *auth.Context) {
+	err := db.RunInTransaction(func(tx *gorm.DB) error {
+		rep, err := business.RecomputeAllDailyReturns(tx, c)
+
+		if err != nil {
+			return err
+		}
+
+		return c.ReturnObject(rep)
+	})
+
+	c.ReturnError(err)
+}
+
+//=============================================================================